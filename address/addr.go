@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/sigurn/crc16"
 )
@@ -24,6 +25,14 @@ type Address struct {
 	workchain int32
 	bitsLen   uint
 	data      []byte
+	anycast   *Anycast
+}
+
+// Anycast - anycast_info of std and var addresses, Depth bits of Prefix
+// are rewriting the first bits of address data
+type Anycast struct {
+	Depth  uint
+	Prefix []byte
 }
 
 type flags struct {
@@ -126,6 +135,25 @@ func (a *Address) MarshalJSON() ([]byte, error) {
 	return []byte(fmt.Sprintf("%q", a.String())), nil
 }
 
+func (a *Address) UnmarshalJSON(data []byte) error {
+	str, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("address should be string: %w", err)
+	}
+
+	if str == "NONE" || str == "" {
+		*a = *NewAddressNone()
+		return nil
+	}
+
+	addr, err := ParseAddr(str)
+	if err != nil {
+		return err
+	}
+	*a = *addr
+	return nil
+}
+
 func MustParseAddr(addr string) *Address {
 	a, err := ParseAddr(addr)
 	if err != nil {
@@ -211,3 +239,13 @@ func (a *Address) Workchain() int32 {
 func (a *Address) Data() []byte {
 	return a.data
 }
+
+// Anycast - returns anycast info of address, nil if it is not set
+func (a *Address) Anycast() *Anycast {
+	return a.anycast
+}
+
+// SetAnycast - sets anycast info of std or var address, nil removes it
+func (a *Address) SetAnycast(anycast *Anycast) {
+	a.anycast = anycast
+}
@@ -461,3 +461,29 @@ func TestParseflags(t *testing.T) {
 		})
 	}
 }
+
+func TestAddress_UnmarshalJSON(t *testing.T) {
+	a := MustParseAddr("EQC6KV4zs8TJtSZapOrRFmqSkxzpq-oSCoxekQRKElf4nC1I")
+
+	data, err := a.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b Address
+	if err = b.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.String() != a.String() {
+		t.Fatal("address not same after json round trip")
+	}
+
+	if err = b.UnmarshalJSON([]byte(`"NONE"`)); err != nil || !b.IsAddrNone() {
+		t.Fatal("should be none address", err)
+	}
+
+	if err = b.UnmarshalJSON([]byte(`123`)); err == nil {
+		t.Fatal("should be error for non string")
+	}
+}
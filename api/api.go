@@ -0,0 +1,51 @@
+// Package api contains small stable interfaces, which can be accepted by consumers
+// instead of concrete reflection based implementations, so alternative or generated codecs can be used.
+package api
+
+import (
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// Decoder - decodes data from loader to v, v should be a pointer
+type Decoder interface {
+	Decode(loader *cell.Slice, v any) error
+}
+
+// Encoder - encodes v to cell
+type Encoder interface {
+	Encode(v any) (*cell.Cell, error)
+}
+
+// Codec - both Decoder and Encoder
+type Codec interface {
+	Decoder
+	Encoder
+}
+
+// DictReader - read access to dictionary, implemented by *cell.Dictionary
+type DictReader interface {
+	Get(key *cell.Cell) *cell.Cell
+	All() []*cell.HashmapKV
+}
+
+var _ DictReader = (*cell.Dictionary)(nil)
+
+// ProofVerifier - verifies merkle proof received from untrusted source, like liteserver,
+// proof should prove data of the block, returns error when it does not
+type ProofVerifier interface {
+	VerifyProof(block *tlb.BlockInfo, proof *cell.Cell) error
+}
+
+type tlbCodec struct{}
+
+// TLB - codec based on tlb struct tags
+var TLB Codec = tlbCodec{}
+
+func (tlbCodec) Decode(loader *cell.Slice, v any) error {
+	return tlb.LoadFromCell(v, loader)
+}
+
+func (tlbCodec) Encode(v any) (*cell.Cell, error) {
+	return tlb.ToCell(v)
+}
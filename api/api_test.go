@@ -0,0 +1,35 @@
+package api
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testMsg struct {
+	_       tlb.Magic `tlb:"#00000001"`
+	QueryID uint64    `tlb:"## 64"`
+}
+
+func TestTLB(t *testing.T) {
+	c, err := TLB.Encode(testMsg{QueryID: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := cell.BeginCell().MustStoreUInt(1, 32).MustStoreUInt(5, 64).EndCell()
+	if !bytes.Equal(c.Hash(), exp.Hash()) {
+		t.Fatal("incorrect cell")
+	}
+
+	var x testMsg
+	if err = TLB.Decode(c.BeginParse(), &x); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.QueryID != 5 {
+		t.Fatal("query id not eq")
+	}
+}
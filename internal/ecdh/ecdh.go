@@ -0,0 +1,33 @@
+package ecdh
+
+import (
+	"crypto/ed25519"
+
+	"github.com/oasisprotocol/curve25519-voi/curve"
+	ed25519crv "github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+	"github.com/oasisprotocol/curve25519-voi/primitives/x25519"
+)
+
+// SharedKey - generates shared key based on our private and their public ed25519 keys,
+// keys are converted to x25519 and ECDH algorithm is used
+func SharedKey(ourKey ed25519.PrivateKey, theirKey ed25519.PublicKey) ([]byte, error) {
+	comp, err := curve.NewCompressedEdwardsYFromBytes(theirKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ep, err := curve.NewEdwardsPoint().SetCompressedY(comp)
+	if err != nil {
+		return nil, err
+	}
+
+	mp := curve.NewMontgomeryPoint().SetEdwards(ep)
+	bb := x25519.EdPrivateKeyToX25519(ed25519crv.PrivateKey(ourKey))
+
+	key, err := x25519.X25519(bb, mp[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
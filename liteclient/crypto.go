@@ -8,9 +8,7 @@ import (
 	"crypto/sha256"
 	"errors"
 
-	"github.com/oasisprotocol/curve25519-voi/curve"
-	ed25519crv "github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
-	"github.com/oasisprotocol/curve25519-voi/primitives/x25519"
+	"github.com/xssnick/tonutils-go/internal/ecdh"
 )
 
 func keyID(key []byte) ([]byte, error) {
@@ -30,25 +28,7 @@ func keyID(key []byte) ([]byte, error) {
 
 // generate encryption key based on our and server key, ECDH algorithm
 func sharedKey(ourKey ed25519.PrivateKey, serverKey ed25519.PublicKey) ([]byte, error) {
-	comp, err := curve.NewCompressedEdwardsYFromBytes(serverKey)
-	if err != nil {
-		return nil, err
-	}
-
-	ep, err := curve.NewEdwardsPoint().SetCompressedY(comp)
-	if err != nil {
-		return nil, err
-	}
-
-	mp := curve.NewMontgomeryPoint().SetEdwards(ep)
-	bb := x25519.EdPrivateKeyToX25519(ed25519crv.PrivateKey(ourKey))
-
-	key, err := x25519.X25519(bb, mp[:])
-	if err != nil {
-		return nil, err
-	}
-
-	return key, nil
+	return ecdh.SharedKey(ourKey, serverKey)
 }
 
 func newCipherCtr(key, iv []byte) (cipher.Stream, error) {
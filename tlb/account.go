@@ -2,7 +2,6 @@ package tlb
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"github.com/sigurn/crc16"
 	"math/big"
@@ -43,6 +42,7 @@ type AccountStorage struct {
 	Status            AccountStatus
 	LastTransactionLT uint64
 	Balance           Coins
+	ExtraCurrencies   ExtraCurrencyCollection
 
 	// has value when active
 	StateInit *StateInit
@@ -193,13 +193,14 @@ func (s *AccountStorage) LoadFromCell(loader *cell.Slice) error {
 		return fmt.Errorf("failed to load coins balance: %w", err)
 	}
 
-	extraExists, err := loader.LoadBoolBit()
+	extra, err := loader.LoadDict(32)
 	if err != nil {
-		return fmt.Errorf("failed to load extra exists bit: %w", err)
+		return fmt.Errorf("failed to load extra currencies: %w", err)
 	}
 
-	if extraExists {
-		return errors.New("extra currency info is not supported for AccountStorage")
+	s.ExtraCurrencies, err = ExtraCurrenciesFromDict(extra)
+	if err != nil {
+		return fmt.Errorf("failed to parse extra currencies: %w", err)
 	}
 
 	isStatusActive, err := loader.LoadBoolBit()
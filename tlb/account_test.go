@@ -2,6 +2,7 @@ package tlb
 
 import (
 	"encoding/hex"
+	"math/big"
 	"testing"
 
 	"github.com/xssnick/tonutils-go/tvm/cell"
@@ -48,6 +49,29 @@ func TestAccountState_LoadFromCell(t *testing.T) {
 	}
 }
 
+func TestAccountStorage_ExtraCurrencies(t *testing.T) {
+	extra, err := ExtraCurrencyCollection{239: big.NewInt(1000)}.ToDict()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := cell.BeginCell().MustStoreUInt(7, 64).MustStoreBigCoins(big.NewInt(500)).
+		MustStoreDict(extra).MustStoreUInt(0b00, 2).EndCell()
+
+	var s AccountStorage
+	if err = s.LoadFromCell(c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Status != AccountStatusUninit || s.Balance.NanoTON().Uint64() != 500 {
+		t.Fatal("not eq", s.Status, s.Balance)
+	}
+
+	if len(s.ExtraCurrencies) != 1 || s.ExtraCurrencies[239].Uint64() != 1000 {
+		t.Fatal("not eq extra currencies", s.ExtraCurrencies)
+	}
+}
+
 func Test_MethodNameHash(t *testing.T) {
 	hash := MethodNameHash("seqno")
 	if hash != 85143 {
@@ -0,0 +1,83 @@
+package tlb
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// Decision - non-obvious choice made during serialization, like chosen either branch or omitted maybe value
+type Decision struct {
+	// Field - path of the field from the root struct, like 'Body.Payload'
+	Field string
+	// Choice - what was chosen and why
+	Choice string
+}
+
+// fieldSpan - bits and refs of the cell taken by stored field, including its maybe and either bits
+type fieldSpan struct {
+	index            int
+	bitsFrom, bitsTo uint
+	refsFrom, refsTo int
+}
+
+// auditor - records decisions of ToCellAudit, nil auditor records nothing
+type auditor struct {
+	prefix    string
+	decisions *[]Decision
+	// spans - when not nil, receives spans of stored fields of the root struct only, it is not passed to nested auditors
+	spans *[]fieldSpan
+	// trace - when not nil, called for each stored field, including fields of nested structs, see ToCellTrace
+	trace func(FieldTrace)
+	depth int
+}
+
+// record - adds decision about field, empty field means the struct of the auditor itself
+func (a *auditor) record(field, choice string) {
+	if a == nil || a.decisions == nil {
+		return
+	}
+	*a.decisions = append(*a.decisions, Decision{Field: strings.TrimSuffix(a.prefix+field, "."), Choice: choice})
+}
+
+// span - records span of field i of rv stored to builder, starting at bitsFrom and refsFrom
+func (a *auditor) span(rv reflect.Value, i int, bitsFrom uint, refsFrom int, builder *cell.Builder) {
+	if a == nil {
+		return
+	}
+
+	if a.trace != nil {
+		a.trace(newFieldTrace(rv, i, a.depth, true, bitsFrom, builder.BitsUsed(), refsFrom, builder.RefsUsed()))
+	}
+
+	if a.spans != nil {
+		*a.spans = append(*a.spans, fieldSpan{
+			index:    i,
+			bitsFrom: bitsFrom,
+			bitsTo:   builder.BitsUsed(),
+			refsFrom: refsFrom,
+			refsTo:   builder.RefsUsed(),
+		})
+	}
+}
+
+// nested - returns auditor for fields of the inner struct stored in field
+func (a *auditor) nested(field string) *auditor {
+	if a == nil {
+		return nil
+	}
+	return &auditor{prefix: a.prefix + field + ".", decisions: a.decisions, trace: a.trace, depth: a.depth + 1}
+}
+
+// ToCellAudit - the same as ToCell, but also returns decisions made during serialization:
+// chosen either branches, omitted maybe values, skipped conditional fields, asserted values and union types.
+// Can be used to review generated messages before signing. Values of dictionaries are not audited.
+func ToCellAudit(v any) (*cell.Cell, []Decision, error) {
+	var decisions []Decision
+	c, err := toCell(v, storeOptions{}, &auditor{decisions: &decisions})
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, decisions, nil
+}
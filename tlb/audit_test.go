@@ -0,0 +1,62 @@
+package tlb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testAuditInner struct {
+	Extra *uint32 `tlb:"maybe ## 32"`
+}
+
+type testAudit struct {
+	Version uint8          `tlb:"## 8 assert:2"`
+	HasFee  bool           `tlb:"-"`
+	Fee     uint32         `tlb:"maybe:HasFee ## 32"`
+	Legacy  uint16         `tlb:"if:Version=3 ## 16"`
+	Inner   testAuditInner `tlb:"^"`
+	Body    *cell.Cell     `tlb:"either . ^ fit"`
+	Payload *cell.Cell     `tlb:"maybe ^"`
+}
+
+func TestToCellAudit(t *testing.T) {
+	x := testAudit{
+		Version: 1,
+		Body:    cell.BeginCell().MustStoreSlice(make([]byte, 127), 1016).EndCell(),
+	}
+
+	c, decisions, err := ToCellAudit(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), plain.Hash()) {
+		t.Fatal("audited cell should be the same as plain")
+	}
+
+	want := []Decision{
+		{Field: "Version", Choice: "value 1 replaced with asserted 2"},
+		{Field: "Fee", Choice: "omitted, HasFee is false"},
+		{Field: "Legacy", Choice: "skipped, condition 'Version=3' is false"},
+		{Field: "Inner.Extra", Choice: "omitted, value is nil"},
+		{Field: "Body", Choice: "either stored as '^', does not fit into cell"},
+		{Field: "Payload", Choice: "omitted, value is nil"},
+	}
+
+	if len(decisions) != len(want) {
+		t.Fatal("incorrect decisions", decisions)
+	}
+
+	for i := range want {
+		if decisions[i] != want[i] {
+			t.Fatal("incorrect decision", i, decisions[i])
+		}
+	}
+}
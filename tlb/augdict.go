@@ -0,0 +1,370 @@
+package tlb
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"reflect"
+	"sort"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// AugDictEntry - entry of augmented dictionary, Value contains the rest of the leaf after Extra
+type AugDictEntry[E any] struct {
+	Key   *cell.Cell
+	Extra E
+	Value *cell.Cell
+}
+
+// AugDict - augmented dictionary (HashmapAugE N X E), every node of it has extra value of type E,
+// extra of the whole dictionary is in Extra field. E is loaded using LoadFromCell or manual loader.
+// Can be used with tag 'dictaug N'. Dictionary which was not changed is stored as it was loaded.
+// Entries can be changed using Set and Delete when combine function is known, it is set by NewAugDict
+// or SetCombine, then on store extras of forks and of the whole dictionary are recalculated using it.
+type AugDict[E any] struct {
+	Extra E
+
+	keySz   uint
+	root    *cell.Cell
+	extra   *cell.Cell
+	entries map[string]*AugDictEntry[E]
+	combine func(a, b E) (E, error)
+	changed bool
+}
+
+type augDict interface {
+	loadAug(ctx context.Context, keySz uint, loader *cell.Slice) error
+	storeAug(keySz uint, builder *cell.Builder, options storeOptions) error
+}
+
+// NewAugDict - creates empty augmented dictionary with keys of keySz bits, combine should return extra of fork
+// from extras of its left and right branches, for example sum of balances, it is defined by the schema.
+// Extra of empty dictionary is stored as it is set in Extra field.
+func NewAugDict[E any](keySz uint, combine func(a, b E) (E, error)) *AugDict[E] {
+	return &AugDict[E]{
+		keySz:   keySz,
+		entries: map[string]*AugDictEntry[E]{},
+		combine: combine,
+		changed: true,
+	}
+}
+
+// SetCombine - sets function to calculate extras of forks, it is required to change loaded dictionary
+func (d *AugDict[E]) SetCombine(combine func(a, b E) (E, error)) {
+	d.combine = combine
+}
+
+// KeySize - size of dictionary keys in bits
+func (d *AugDict[E]) KeySize() uint {
+	return d.keySz
+}
+
+// All - returns all entries of dictionary sorted by key
+func (d *AugDict[E]) All() []*AugDictEntry[E] {
+	keys := make([]string, 0, len(d.entries))
+	for k := range d.entries {
+		keys = append(keys, k)
+	}
+	// keys are hex of the same number of bits, so string order is the order of keys
+	sort.Strings(keys)
+
+	all := make([]*AugDictEntry[E], 0, len(keys))
+	for _, k := range keys {
+		all = append(all, d.entries[k])
+	}
+	return all
+}
+
+// Get - returns entry by key, nil if not exists
+func (d *AugDict[E]) Get(key *cell.Cell) *AugDictEntry[E] {
+	data, err := key.BeginParse().LoadSlice(d.keySz)
+	if err != nil {
+		return nil
+	}
+	return d.entries[hex.EncodeToString(data)]
+}
+
+// Set - adds or replaces entry of key with extra and value, combine function should be set
+func (d *AugDict[E]) Set(key *cell.Cell, extra E, value *cell.Cell) error {
+	if d.combine == nil {
+		return fmt.Errorf("combine function of aug dict is not set")
+	}
+
+	if value == nil {
+		return fmt.Errorf("value of aug dict entry should not be nil")
+	}
+
+	data, err := key.BeginParse().LoadSlice(d.keySz)
+	if err != nil {
+		return fmt.Errorf("key should have %d bits: %w", d.keySz, err)
+	}
+
+	if d.entries == nil {
+		d.entries = map[string]*AugDictEntry[E]{}
+	}
+	d.entries[hex.EncodeToString(data)] = &AugDictEntry[E]{
+		Key:   cell.BeginCell().MustStoreSlice(data, d.keySz).EndCell(),
+		Extra: extra,
+		Value: value,
+	}
+	d.changed = true
+	return nil
+}
+
+// Delete - removes entry of key, if it exists, combine function should be set
+func (d *AugDict[E]) Delete(key *cell.Cell) error {
+	if d.combine == nil {
+		return fmt.Errorf("combine function of aug dict is not set")
+	}
+
+	data, err := key.BeginParse().LoadSlice(d.keySz)
+	if err != nil {
+		return fmt.Errorf("key should have %d bits: %w", d.keySz, err)
+	}
+
+	delete(d.entries, hex.EncodeToString(data))
+	d.changed = true
+	return nil
+}
+
+func (d *AugDict[E]) loadAug(ctx context.Context, keySz uint, loader *cell.Slice) error {
+	d.keySz = keySz
+	d.entries = map[string]*AugDictEntry[E]{}
+	d.changed = false
+
+	root, err := loader.LoadMaybeRef()
+	if err != nil {
+		return fmt.Errorf("failed to load root of aug dict: %w", err)
+	}
+
+	d.root = nil
+	if root != nil {
+		if d.root, err = root.ToCell(); err != nil {
+			return fmt.Errorf("failed to convert root of aug dict to cell: %w", err)
+		}
+
+		// forks have extra after refs, so it is skipped by regular dict parsing,
+		// and leaves values are starting with extra
+		dict, err := root.ToDict(keySz)
+		if err != nil {
+			return fmt.Errorf("failed to parse aug dict: %w", err)
+		}
+
+		for _, kv := range dict.All() {
+			ld := kv.Value.BeginParse()
+
+			extra, err := d.loadExtra(ctx, ld)
+			if err != nil {
+				return fmt.Errorf("failed to load extra of aug dict leaf: %w", err)
+			}
+
+			value, err := ld.ToCell()
+			if err != nil {
+				return fmt.Errorf("failed to convert aug dict value to cell: %w", err)
+			}
+
+			d.entries[hex.EncodeToString(kv.Key.BeginParse().MustLoadSlice(keySz))] = &AugDictEntry[E]{
+				Key:   kv.Key,
+				Extra: extra,
+				Value: value,
+			}
+		}
+	}
+
+	begin := loader.Copy()
+	if d.Extra, err = d.loadExtra(ctx, loader); err != nil {
+		return fmt.Errorf("failed to load extra of aug dict: %w", err)
+	}
+
+	// keep serialized extra to store it the same way
+	extraBits := loader.BitsOffset() - begin.BitsOffset()
+	extraRefs := loader.RefsOffset() - begin.RefsOffset()
+	b := cell.BeginCell().MustStoreSlice(begin.MustLoadSlice(extraBits), extraBits)
+	for i := 0; i < extraRefs; i++ {
+		b.MustStoreRef(begin.MustLoadRef().MustToCell())
+	}
+	d.extra = b.EndCell()
+
+	return nil
+}
+
+func (d *AugDict[E]) loadExtra(ctx context.Context, loader *cell.Slice) (E, error) {
+	var extra E
+
+	v, err := structLoad(ctx, reflect.TypeOf(&extra).Elem(), loader)
+	if err != nil {
+		return extra, err
+	}
+	return v.Interface().(E), nil
+}
+
+func (d *AugDict[E]) storeAug(keySz uint, builder *cell.Builder, options storeOptions) error {
+	if d.keySz != keySz {
+		return fmt.Errorf("aug dict has keys of %d bits, but %d is expected", d.keySz, keySz)
+	}
+
+	root, extra := d.root, d.extra
+	if d.changed {
+		var err error
+		if root, extra, err = d.build(options); err != nil {
+			return err
+		}
+	}
+
+	if extra == nil {
+		return fmt.Errorf("aug dict was not loaded")
+	}
+
+	if err := builder.StoreMaybeRef(root); err != nil {
+		return fmt.Errorf("failed to store root of aug dict: %w", err)
+	}
+
+	if err := builder.StoreBuilder(extra.ToBuilder()); err != nil {
+		return fmt.Errorf("failed to store extra of aug dict: %w", err)
+	}
+	return nil
+}
+
+// augNode - entries of subtree with common key prefix
+type augNode[E any] struct {
+	keys    [][]byte
+	entries []*AugDictEntry[E]
+}
+
+// build - serializes entries to root cell, extras of forks and of the dictionary are calculated using combine
+func (d *AugDict[E]) build(options storeOptions) (*cell.Cell, *cell.Cell, error) {
+	all := d.All()
+	if len(all) == 0 {
+		extra, err := d.storeExtra(d.Extra, options)
+		return nil, extra, err
+	}
+
+	node := augNode[E]{}
+	for _, e := range all {
+		node.keys = append(node.keys, e.Key.BeginParse().MustLoadSlice(d.keySz))
+		node.entries = append(node.entries, e)
+	}
+
+	root, rootExtra, err := d.buildNode(node, 0, options)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build aug dict: %w", err)
+	}
+
+	extra, err := d.storeExtra(rootExtra, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	return root, extra, nil
+}
+
+// buildNode - serializes subtree which keys have the same first 'from' bits, returns cell and extra of it
+func (d *AugDict[E]) buildNode(node augNode[E], from uint, options storeOptions) (*cell.Cell, E, error) {
+	// label is the longest common prefix of the rest bits of keys
+	to := from
+	for ; to < d.keySz; to++ {
+		bit := keyBit(node.keys[0], to)
+		same := true
+		for _, k := range node.keys[1:] {
+			if keyBit(k, to) != bit {
+				same = false
+				break
+			}
+		}
+
+		if !same {
+			break
+		}
+	}
+
+	b := cell.BeginCell()
+	if err := d.storeLabel(b, node.keys[0], from, to); err != nil {
+		var zero E
+		return nil, zero, fmt.Errorf("failed to store label: %w", err)
+	}
+
+	if to == d.keySz {
+		e := node.entries[0]
+		extra, err := d.storeExtra(e.Extra, options)
+		if err != nil {
+			return nil, e.Extra, err
+		}
+
+		if err = b.StoreBuilder(extra.ToBuilder()); err != nil {
+			return nil, e.Extra, fmt.Errorf("failed to store extra of leaf: %w", err)
+		}
+
+		if err = b.StoreBuilder(e.Value.ToBuilder()); err != nil {
+			return nil, e.Extra, fmt.Errorf("failed to store value of leaf: %w", err)
+		}
+		return b.EndCell(), e.Extra, nil
+	}
+
+	// keys are sorted, so branch 0 is the first part of them
+	split := 0
+	for split < len(node.keys) && !keyBit(node.keys[split], to) {
+		split++
+	}
+
+	left, leftExtra, err := d.buildNode(augNode[E]{keys: node.keys[:split], entries: node.entries[:split]}, to+1, options)
+	if err != nil {
+		return nil, leftExtra, err
+	}
+
+	right, rightExtra, err := d.buildNode(augNode[E]{keys: node.keys[split:], entries: node.entries[split:]}, to+1, options)
+	if err != nil {
+		return nil, rightExtra, err
+	}
+
+	forkExtra, err := d.combine(leftExtra, rightExtra)
+	if err != nil {
+		return nil, forkExtra, fmt.Errorf("failed to combine extras: %w", err)
+	}
+
+	extra, err := d.storeExtra(forkExtra, options)
+	if err != nil {
+		return nil, forkExtra, err
+	}
+
+	b.MustStoreRef(left).MustStoreRef(right)
+	if err = b.StoreBuilder(extra.ToBuilder()); err != nil {
+		return nil, forkExtra, fmt.Errorf("failed to store extra of fork: %w", err)
+	}
+	return b.EndCell(), forkExtra, nil
+}
+
+// storeLabel - stores bits [from, to) of key as hml_long label, or as empty hml_short, like cell.Dictionary does
+func (d *AugDict[E]) storeLabel(b *cell.Builder, key []byte, from, to uint) error {
+	if to == from {
+		return b.StoreUInt(0, 2)
+	}
+
+	if err := b.StoreUInt(0b10, 2); err != nil {
+		return err
+	}
+
+	if err := b.StoreUInt(uint64(to-from), uint(bits.Len(d.keySz-from))); err != nil {
+		return err
+	}
+
+	for i := from; i < to; i++ {
+		if err := b.StoreBoolBit(keyBit(key, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *AugDict[E]) storeExtra(extra E, options storeOptions) (*cell.Cell, error) {
+	c, err := structStore(reflect.ValueOf(&extra).Elem(), reflect.TypeOf(&extra).Elem().String(), options, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store extra of aug dict: %w", err)
+	}
+	return c, nil
+}
+
+// keyBit - returns bit i of key, counting from the highest bit of the first byte
+func keyBit(key []byte, i uint) bool {
+	return key[i/8]&(1<<(7-i%8)) != 0
+}
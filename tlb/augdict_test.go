@@ -0,0 +1,155 @@
+package tlb
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testAugExtra struct {
+	Sum uint16 `tlb:"## 16"`
+}
+
+type testAugDict struct {
+	Accounts *AugDict[testAugExtra] `tlb:"dictaug 8"`
+	Tail     uint8                  `tlb:"## 8"`
+}
+
+func TestLoadFromCellDictAug(t *testing.T) {
+	leaf := func(extra, val uint64) *cell.Cell {
+		// hml_short with 7 zero bits of key, extra, value
+		return cell.BeginCell().MustStoreUInt(0, 1).MustStoreUInt(0b11111110, 8).MustStoreUInt(0, 7).
+			MustStoreUInt(extra, 16).MustStoreUInt(val, 8).EndCell()
+	}
+
+	// empty label, fork with extra
+	root := cell.BeginCell().MustStoreUInt(0, 2).
+		MustStoreRef(leaf(10, 1)).MustStoreRef(leaf(20, 2)).MustStoreUInt(30, 16).EndCell()
+
+	c := cell.BeginCell().MustStoreMaybeRef(root).MustStoreUInt(30, 16).MustStoreUInt(7, 8).EndCell()
+
+	var x testAugDict
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Accounts.Extra.Sum != 30 || x.Tail != 7 || len(x.Accounts.All()) != 2 {
+		t.Fatal("aug dict not eq")
+	}
+
+	e := x.Accounts.Get(cell.BeginCell().MustStoreUInt(0x80, 8).EndCell())
+	if e == nil || e.Extra.Sum != 20 || e.Value.BeginParse().MustLoadUInt(8) != 2 {
+		t.Fatal("aug dict entry not eq")
+	}
+
+	a, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	empty := cell.BeginCell().MustStoreMaybeRef(nil).MustStoreUInt(0, 16).MustStoreUInt(1, 8).EndCell()
+	if err = LoadFromCell(&x, empty.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.Accounts.All()) != 0 || x.Tail != 1 {
+		t.Fatal("empty aug dict not eq")
+	}
+}
+
+func sumAugExtra(a, b testAugExtra) (testAugExtra, error) {
+	return testAugExtra{Sum: a.Sum + b.Sum}, nil
+}
+
+func TestToCellDictAugChanged(t *testing.T) {
+	key := func(k uint64) *cell.Cell {
+		return cell.BeginCell().MustStoreUInt(k, 8).EndCell()
+	}
+	val := func(v uint64) *cell.Cell {
+		return cell.BeginCell().MustStoreUInt(v, 8).EndCell()
+	}
+
+	d := NewAugDict[testAugExtra](8, sumAugExtra)
+	for i, k := range []uint64{0x80, 0x01, 0x03, 0xF0} {
+		if err := d.Set(key(k), testAugExtra{Sum: uint16(i + 1)}, val(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := ToCell(testAugDict{Accounts: d, Tail: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var x testAugDict
+	if err = LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Accounts.Extra.Sum != 10 || x.Tail != 7 {
+		t.Fatal("extra of aug dict is not recalculated", x.Accounts.Extra.Sum)
+	}
+
+	all := x.Accounts.All()
+	for i, k := range []uint64{0x01, 0x03, 0x80, 0xF0} {
+		if all[i].Key.BeginParse().MustLoadUInt(8) != k || all[i].Value.BeginParse().MustLoadUInt(8) != k {
+			t.Fatal("entries should be sorted by key", i)
+		}
+	}
+
+	// extra of fork under root is checked by loading subtree of 0xxxxxxx keys
+	fork := c.BeginParse().MustLoadMaybeRef().MustLoadRef()
+	if _, err = fork.LoadSlice(2 + 3 + 5); err != nil { // hml_long label of 5 zero bits
+		t.Fatal(err)
+	}
+	fork.MustLoadRef()
+	fork.MustLoadRef()
+	if fork.MustLoadUInt(16) != 5 {
+		t.Fatal("extra of fork is not recalculated")
+	}
+
+	if err = x.Accounts.Delete(key(0x80)); err == nil {
+		t.Fatal("loaded dict should not be changed without combine")
+	}
+
+	x.Accounts.SetCombine(sumAugExtra)
+	if err = x.Accounts.Delete(key(0x80)); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, err = ToCell(x); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Accounts.Extra.Sum != 9 || len(x.Accounts.All()) != 3 || x.Accounts.Get(key(0x80)) != nil {
+		t.Fatal("aug dict not eq after delete")
+	}
+
+	if _, err = ToCell(struct {
+		D *AugDict[testAugExtra] `tlb:"dictaug 16"`
+	}{NewAugDict[testAugExtra](8, sumAugExtra)}); err == nil {
+		t.Fatal("should fail on different key size")
+	}
+}
+
+func TestLoadFromCellSafeDictAug(t *testing.T) {
+	var x struct {
+		Accounts *AugDict[testPanicLoader] `tlb:"dictaug 8"`
+	}
+
+	// extra is shorter than 32 bits, options of caller should be applied to its loader
+	c := cell.BeginCell().MustStoreMaybeRef(nil).MustStoreUInt(1, 8).EndCell()
+	if err := LoadFromCellSafe(&x, c.BeginParse()); !errors.Is(err, ErrLoaderPanic) {
+		t.Fatal("should fail with loader panic error", err)
+	}
+}
@@ -0,0 +1,39 @@
+package tlb
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+)
+
+// Bits256 - 256 bits value, like hash or public key, can be loaded using 'hash' tag,
+// in JSON and text it is represented as hex string
+type Bits256 [32]byte
+
+func (b Bits256) String() string {
+	return hex.EncodeToString(b[:])
+}
+
+// Equal - compares values in constant time
+func (b Bits256) Equal(other Bits256) bool {
+	return subtle.ConstantTimeCompare(b[:], other[:]) == 1
+}
+
+func (b Bits256) IsZero() bool {
+	return b == Bits256{}
+}
+
+func (b Bits256) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *Bits256) UnmarshalText(data []byte) error {
+	if len(data) != 64 {
+		return fmt.Errorf("incorrect length of 256 bits hex: %d", len(data))
+	}
+
+	if _, err := hex.Decode(b[:], data); err != nil {
+		return fmt.Errorf("failed to decode hex: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package tlb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// ToBOC - serializes v to cell using ToCell, and returns it as BOC with crc
+func ToBOC(v any) ([]byte, error) {
+	c, err := ToCell(v)
+	if err != nil {
+		return nil, err
+	}
+	return c.ToBOC(), nil
+}
+
+// ToBOCBase64 - the same as ToBOC, but BOC is encoded to standard base64
+func ToBOCBase64(v any) (string, error) {
+	data, err := ToBOC(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// ToBOCHex - the same as ToBOC, but BOC is encoded to hex
+func ToBOCHex(v any) (string, error) {
+	data, err := ToBOC(v)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// FromBOC - parses single root BOC and loads v from its root cell using LoadFromCell, v should be a pointer
+func FromBOC(data []byte, v any) error {
+	c, err := cell.FromBOC(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse boc: %w", err)
+	}
+	return LoadFromCell(v, c.BeginParse())
+}
+
+// FromBOCBase64 - the same as FromBOC, but BOC is encoded to standard base64
+func FromBOCBase64(data string, v any) error {
+	boc, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode base64: %w", err)
+	}
+	return FromBOC(boc, v)
+}
+
+// FromBOCHex - the same as FromBOC, but BOC is encoded to hex
+func FromBOCHex(data string, v any) error {
+	boc, err := hex.DecodeString(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode hex: %w", err)
+	}
+	return FromBOC(boc, v)
+}
+
+// FromBOCMultiRoot - parses BOC with several roots and loads each root to element of dst with the same index using LoadFromCell,
+// number of roots should be equal to len(dst). Decoding of each root is independent, returned slice contains error
+// of each root (nil when it is decoded), err is returned only when BOC cannot be parsed or number of roots is different.
+func FromBOCMultiRoot(data []byte, dst ...any) (rootErrs []error, err error) {
+	roots, err := cell.FromBOCMultiRoot(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse boc: %w", err)
+	}
+
+	if len(roots) != len(dst) {
+		return nil, fmt.Errorf("boc has %d roots, but %d destinations are passed", len(roots), len(dst))
+	}
+
+	rootErrs = make([]error, len(roots))
+	for i, root := range roots {
+		if err = LoadFromCell(dst[i], root.BeginParse()); err != nil {
+			rootErrs[i] = fmt.Errorf("failed to load root %d: %w", i, err)
+		}
+	}
+	return rootErrs, nil
+}
+
+// LoadAnyBOC - parses BOC with one or several roots and decodes each root using LoadAny, so type of each root
+// is detected by magic of registered types. Returned slices have decoded value (nil on failure) and error of each root,
+// err is returned only when BOC cannot be parsed.
+func LoadAnyBOC(data []byte) (values []any, rootErrs []error, err error) {
+	return LoadAnyBOCContext(context.Background(), data)
+}
+
+// LoadAnyBOCContext - the same as LoadAnyBOC, but options set using WithOptions in ctx are applied to decoding
+func LoadAnyBOCContext(ctx context.Context, data []byte) (values []any, rootErrs []error, err error) {
+	roots, err := cell.FromBOCMultiRoot(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse boc: %w", err)
+	}
+
+	values = make([]any, len(roots))
+	rootErrs = make([]error, len(roots))
+	for i, root := range roots {
+		if values[i], err = LoadAnyContext(ctx, root.BeginParse()); err != nil {
+			rootErrs[i] = fmt.Errorf("failed to load root %d: %w", i, err)
+		}
+	}
+	return values, rootErrs, nil
+}
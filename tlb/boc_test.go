@@ -0,0 +1,102 @@
+package tlb
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+type testWireBOC struct {
+	Val uint16 `tlb:"## 16"`
+}
+
+func TestToBOC(t *testing.T) {
+	const want = "b5ee9c72410101010004000004abcd1e8f5994"
+
+	str, err := ToBOCHex(testWireBOC{Val: 0xABCD})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if str != want {
+		t.Fatal("incorrect boc", str)
+	}
+
+	var v testWireBOC
+	if err = FromBOCHex(want, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Val != 0xABCD {
+		t.Fatal("incorrect value", v.Val)
+	}
+
+	b64, err := ToBOCBase64(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v2 testWireBOC
+	if err = FromBOCBase64(b64, &v2); err != nil {
+		t.Fatal(err)
+	}
+
+	if v2 != v {
+		t.Fatal("value not same after base64 round trip")
+	}
+
+	if err = FromBOC([]byte{1, 2, 3}, &v); err == nil {
+		t.Fatal("should be error for incorrect boc")
+	}
+}
+
+func TestFromBOCMultiRoot(t *testing.T) {
+	Register("TestRegistryShort", testRegistryShort{})
+	Register("TestUnionA", testUnionA{})
+
+	// 2 roots: testRegistryShort{Val: 5} and testUnionA{Val: 7}
+	data, err := hex.DecodeString("b5ee9c720101020200" + "0c0001" + "00067e0005" + "000a0100000007")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var short testRegistryShort
+	var a testUnionA
+	errs, err := FromBOCMultiRoot(data, &short, &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errs[0] != nil || errs[1] != nil || short.Val != 5 || a.Val != 7 {
+		t.Fatal("incorrect roots", errs, short.Val, a.Val)
+	}
+
+	errs, err = FromBOCMultiRoot(data, &a, &short)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errs[0] == nil || errs[1] == nil {
+		t.Fatal("should be errors for mismatched types")
+	}
+
+	if _, err = FromBOCMultiRoot(data, &short); err == nil {
+		t.Fatal("should be error for wrong number of destinations")
+	}
+
+	values, errs, err := LoadAnyBOC(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errs[0] != nil || errs[1] != nil {
+		t.Fatal(errs)
+	}
+
+	if v, ok := values[0].(*testRegistryShort); !ok || v.Val != 5 {
+		t.Fatal("incorrect first root", values[0])
+	}
+
+	if v, ok := values[1].(*testUnionA); !ok || v.Val != 7 {
+		t.Fatal("incorrect second root", values[1])
+	}
+}
@@ -0,0 +1,56 @@
+package tlb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// Global capabilities of the network, which change layout of structures
+const (
+	// CapBounceMsgBody - bounced messages have BouncedBody, instead of empty body
+	CapBounceMsgBody uint64 = 0x4
+	// CapFullBodyInBounced - BouncedBody has the whole original body in ref
+	CapFullBodyInBounced uint64 = 0x10000
+)
+
+type capabilitiesKey struct{}
+
+// WithCapabilities - returns ctx with global capabilities of the network (from config param 8),
+// when it is passed to LoadFromCellContext or ToCellContext, fields with 'cap:N' modifier are loaded and stored
+// only when capability bits N are enabled
+func WithCapabilities(ctx context.Context, capabilities uint64) context.Context {
+	return context.WithValue(ctx, capabilitiesKey{}, capabilities)
+}
+
+// ToCellContext - the same as ToCell, but fields with 'cap:N' modifier are stored only when capability bits N are enabled
+// in capabilities passed with WithCapabilities, so value is stored the same way as it is loaded by LoadFromCellContext
+func ToCellContext(ctx context.Context, v any) (*cell.Cell, error) {
+	return toCell(v, storeOptionsOf(ctx), nil)
+}
+
+// capabilitiesOf - returns capabilities passed with WithCapabilities, nil when they are unknown
+func capabilitiesOf(ctx context.Context) *uint64 {
+	caps, ok := ctx.Value(capabilitiesKey{}).(uint64)
+	if !ok {
+		return nil
+	}
+	return &caps
+}
+
+// capabilitiesEnabled - checks that all bits of mask are enabled in caps,
+// when capabilities are unknown, all of them are considered enabled, as in the latest protocol version
+func capabilitiesEnabled(caps *uint64, fieldName, mask string) bool {
+	m, err := strconv.ParseUint(mask, 0, 64)
+	if err != nil {
+		// we panic, because its developer's issue, need to fix tag
+		panic(fmt.Sprintf("corrupted capability mask '%s' in tag of '%s'", mask, fieldName))
+	}
+
+	if caps == nil {
+		return true
+	}
+	return *caps&m == m
+}
@@ -0,0 +1,180 @@
+package tlb
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testCapabilities struct {
+	Value uint32     `tlb:"## 32"`
+	Extra *cell.Cell `tlb:"cap:0x4 ^"`
+	Tail  uint8      `tlb:"## 8"`
+}
+
+func TestLoadFromCellCapabilities(t *testing.T) {
+	extra := cell.BeginCell().MustStoreUInt(7, 16).EndCell()
+	withExtra := cell.BeginCell().MustStoreUInt(1, 32).MustStoreRef(extra).MustStoreUInt(9, 8).EndCell()
+	withoutExtra := cell.BeginCell().MustStoreUInt(1, 32).MustStoreUInt(9, 8).EndCell()
+
+	var x testCapabilities
+	if err := LoadFromCellContext(WithCapabilities(context.Background(), 0x6), &x, withExtra.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Extra == nil || !bytes.Equal(x.Extra.Hash(), extra.Hash()) || x.Tail != 9 {
+		t.Fatal("not eq with capability")
+	}
+
+	c, err := ToCellContext(WithCapabilities(context.Background(), 0x6), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), withExtra.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	if err = LoadFromCellContext(WithCapabilities(context.Background(), 0x2), &x, withoutExtra.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Extra != nil || x.Tail != 9 {
+		t.Fatal("not eq without capability")
+	}
+
+	c, err = ToCellContext(WithCapabilities(context.Background(), 0x2), x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), withoutExtra.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	// unknown capabilities are considered enabled
+	if err = LoadFromCell(&x, withExtra.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Extra == nil {
+		t.Fatal("not eq with unknown capabilities")
+	}
+}
+
+type testCapabilitiesZero struct {
+	Value uint32 `tlb:"## 32"`
+	New   uint32 `tlb:"cap:0x4 ## 32"`
+}
+
+func TestToCellCapabilitiesZero(t *testing.T) {
+	// zero value of enabled field is stored, so it is loaded back
+	for _, ctx := range []context.Context{context.Background(), WithCapabilities(context.Background(), 0x4)} {
+		c, err := ToCellContext(ctx, testCapabilitiesZero{Value: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c.BitsSize() != 64 {
+			t.Fatal("zero field should be stored", c.BitsSize())
+		}
+
+		var x testCapabilitiesZero
+		if err = LoadFromCellContext(ctx, &x, c.BeginParse()); err != nil {
+			t.Fatal(err)
+		}
+
+		if x.Value != 1 || x.New != 0 {
+			t.Fatal("incorrect values", x)
+		}
+	}
+
+	c, err := ToCellContext(WithCapabilities(context.Background(), 0), testCapabilitiesZero{Value: 1, New: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.BitsSize() != 32 {
+		t.Fatal("field should be skipped when capability is not enabled", c.BitsSize())
+	}
+}
+
+func TestToCellCapabilitiesDictValues(t *testing.T) {
+	type testCapabilitiesDict struct {
+		Items map[uint16]testCapabilitiesZero `tlb:"dict 16 -> map"`
+	}
+
+	ctx := WithCapabilities(context.Background(), 0)
+	c, err := ToCellContext(ctx, testCapabilitiesDict{Items: map[uint16]testCapabilitiesZero{7: {Value: 1, New: 5}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := c.BeginParse().MustLoadDict(16).GetByIntKey(big.NewInt(7))
+	if value == nil || value.BitsSize() != 32 {
+		t.Fatal("field of dict value should be skipped when capability is not enabled", value)
+	}
+
+	var x testCapabilitiesDict
+	if err = LoadFromCellContext(ctx, &x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Items[7].Value != 1 || x.Items[7].New != 0 {
+		t.Fatal("incorrect values", x.Items)
+	}
+}
+
+func TestBouncedBodyCapabilities(t *testing.T) {
+	original := cell.BeginCell().MustStoreUInt(0x0f8a7ea5, 32).MustStoreUInt(777, 64).EndCell()
+	truncated := cell.BeginCell().MustStoreUInt(0x0f8a7ea5, 32).MustStoreUInt(777, 64).EndCell()
+
+	full := cell.BeginCell().MustStoreUInt(0xffffffff, 32).MustStoreBuilder(truncated.ToBuilder()).MustStoreRef(original).EndCell()
+	short := cell.BeginCell().MustStoreUInt(0xffffffff, 32).MustStoreBuilder(truncated.ToBuilder()).EndCell()
+
+	for _, tt := range []struct {
+		caps     uint64
+		data     *cell.Cell
+		withBody bool
+	}{
+		{CapBounceMsgBody | CapFullBodyInBounced, full, true},
+		{CapBounceMsgBody, short, false},
+	} {
+		ctx := WithCapabilities(context.Background(), tt.caps)
+
+		var body BouncedBody
+		if err := LoadFromCellContext(ctx, &body, tt.data.BeginParse()); err != nil {
+			t.Fatal(err)
+		}
+
+		if (body.OriginalBody != nil) != tt.withBody || !bytes.Equal(body.Truncated.Hash(), truncated.Hash()) {
+			t.Fatal("incorrect bounced body", tt.caps)
+		}
+
+		if tt.withBody && !bytes.Equal(body.OriginalBody.Hash(), original.Hash()) {
+			t.Fatal("incorrect original body")
+		}
+
+		c, err := ToCellContext(ctx, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(c.Hash(), tt.data.Hash()) {
+			t.Fatal("cell hashes not same after From to", tt.caps)
+		}
+
+		msg := InternalMessage{Bounced: true, Body: c}
+		loaded, err := msg.LoadBouncedBody(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d := Diff(&body, loaded); d != "" {
+			t.Fatal("not same bounced body of message:", d)
+		}
+	}
+}
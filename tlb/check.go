@@ -0,0 +1,508 @@
+package tlb
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// CheckError - all problems found by Check, it matches ErrInvalidDefinition
+type CheckError struct {
+	Problems []string
+}
+
+func (e *CheckError) Error() string {
+	return "invalid definition: " + strings.Join(e.Problems, "; ")
+}
+
+func (e *CheckError) Is(target error) bool {
+	return target == ErrInvalidDefinition
+}
+
+type checker struct {
+	seen     map[reflect.Type]bool
+	problems []string
+}
+
+// Check - validates tags and field types of struct v and of its inner structs without loading any data,
+// so incorrect definitions can be detected at startup instead of panic during parsing.
+// All found problems are returned together in CheckError. Accepts value or pointer to struct.
+// Types which implement Unmarshaler or custom LoadFromCell are not checked, as well as custom tags.
+func Check(v any) error {
+	typ := reflect.TypeOf(v)
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return errors.New("v should be a struct or pointer to struct")
+	}
+
+	c := &checker{seen: map[reflect.Type]bool{}}
+	c.checkStruct(typ)
+	if len(c.problems) > 0 {
+		return &CheckError{Problems: c.problems}
+	}
+	return nil
+}
+
+func (c *checker) checkStruct(typ reflect.Type) {
+	if c.seen[typ] {
+		return
+	}
+	c.seen[typ] = true
+
+	if _, ok := asUnmarshaler(reflect.New(typ).Interface()); ok {
+		return
+	}
+	// parsed tags are prepared ahead, to not do it on first load, and the same plan is validated
+	plan := planOf(typ)
+
+	// zero value is used to validate references to other fields
+	rv := reflect.New(typ).Elem()
+	for i := range plan.fields {
+		fp := &plan.fields[i]
+		if fp.hasMark && plan.marks < 0 {
+			c.problemf(typ, fp.field, "mark:%s is used, but struct has no Marks field", fp.markName)
+		}
+		c.checkField(rv, fp)
+	}
+}
+
+// checkField - validates field using its plan, problems reported by helpers of loader using panic are recorded too
+func (c *checker) checkField(rv reflect.Value, fp *fieldPlan) {
+	field := fp.field
+	defer func() {
+		if r := recover(); r != nil {
+			c.problemf(rv.Type(), field, "%v", r)
+		}
+	}()
+
+	if fp.skip {
+		return
+	}
+
+	if fp.invalid != nil {
+		c.problemf(rv.Type(), field, "%v", fp.invalid)
+		return
+	}
+
+	if fieldTag(field) == "" {
+		c.problemf(rv.Type(), field, "tag is empty, use '-' to skip field")
+		return
+	}
+
+	if fp.hasCond {
+		// condition is not evaluated, because values of referenced fields are known only on load
+		conditionField(rv.Type(), field.Name, parseCondition(fp.cond))
+	}
+	if fp.hasCap {
+		capabilitiesEnabled(nil, field.Name, fp.capMask)
+	}
+	if fp.hasAssert {
+		parseValue(field.Type, field.Name, fp.want)
+	}
+	if fp.hasEnum {
+		_ = checkEnum(rv.FieldByIndex(field.Index), field.Name, fp.enum)
+	}
+	if fp.hasPresence {
+		boolField(rv, field.Name, fp.presence)
+	}
+	if fp.hasDefault {
+		if !fp.hasPresence && (len(fp.settings) == 0 || fp.settings[0] != "maybe") {
+			c.problemf(rv.Type(), field, "default can be used only with maybe")
+			return
+		}
+		parseValue(field.Type, field.Name, fp.def)
+	}
+	if fp.hasBranch {
+		boolField(rv, field.Name, fp.branch)
+		if len(fp.settings) == 0 {
+			c.problemf(rv.Type(), field, "either:Field should have 2 args")
+			return
+		}
+		a, b := splitEither(fp.settings, field.Name)
+		c.checkData(rv, field, a)
+		c.checkData(rv, field, b)
+		return
+	}
+	c.checkData(rv, field, fp.settings)
+}
+
+// checkData - validates data tag of the field, modifiers are handled as in loadField,
+// and data tags are validated using the same dataTags table which is used by loader
+func (c *checker) checkData(rv reflect.Value, field reflect.StructField, settings []string) {
+	if len(settings) == 0 || settings[0] == "" {
+		c.problemf(rv.Type(), field, "data tag is missing")
+		return
+	}
+
+	settings = expandAlias(settings)
+	typ := field.Type
+	args := len(settings) - 1
+
+	switch {
+	case settings[0] == "maybe":
+		if args == 0 {
+			c.problemf(rv.Type(), field, "maybe should be followed by tag of the value")
+			return
+		}
+		if len(settings) == 2 && settings[1] == "^" && typ == reflect.TypeOf(&cell.Cell{}) {
+			return
+		}
+		c.checkData(rv, field, settings[1:])
+		return
+	case settings[0] == "either":
+		args, fit := eitherFit(settings[1:])
+		a, b := splitEither(args, field.Name)
+		if fit && (len(a) != 1 || len(b) != 1 || !isInlineOrRef(a[0], b[0])) {
+			c.problemf(rv.Type(), field, "fit option of either can be used only with '. ^' or '^ .'")
+		}
+		c.checkData(rv, field, a)
+		c.checkData(rv, field, b)
+		return
+	case strings.HasPrefix(settings[0], "try("):
+		candidates, variant := tryCandidates(settings, field.Name)
+		if variant != "" {
+			variantField(rv, field.Name, variant)
+		}
+		for _, candidate := range candidates {
+			_, rest, _ := candidateMagic(candidate)
+			c.checkData(rv, field, rest)
+		}
+		return
+	case typ == reflect.TypeOf(Magic{}):
+		_, variant := magicAlternatives(settings[0])
+		parseMagics(settings[0])
+		if variant != "" {
+			variantField(rv, field.Name, variant)
+		}
+		return
+	}
+
+	if _, ok := customTag(settings[0]); ok {
+		return
+	}
+
+	if _, ok := refSliceTag(field, settings); ok {
+		c.checkValue(rv, field, typ.Elem())
+		return
+	}
+
+	t, ok := dataTags[settings[0]]
+	if !ok {
+		c.problemf(rv.Type(), field, "unknown tag '%s'", strings.Join(settings, " "))
+		return
+	}
+	t.check(c, rv, field, settings)
+}
+
+// checkIntTag - validates '## N' field, type should fit N bits
+func (c *checker) checkIntTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	if !c.need(rv, field, settings, 1) {
+		return
+	}
+	num, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil || num == 0 || num > 256 {
+		c.problemf(rv.Type(), field, "corrupted num bits '%s' in ## tag", settings[1])
+		return
+	}
+	c.checkInt(rv, field, uint(num))
+}
+
+// checkBitsTag - validates 'bits N' field
+func (c *checker) checkBitsTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	if !c.need(rv, field, settings, 1) {
+		return
+	}
+	if _, err := strconv.ParseUint(settings[1], 10, 64); err != nil {
+		c.problemf(rv.Type(), field, "corrupted num bits '%s' in bits tag", settings[1])
+	}
+	c.checkType(rv, field, reflect.TypeOf([]byte{}), reflect.TypeOf(&big.Int{}), reflect.TypeOf(""))
+}
+
+// checkAddrTag - validates 'addr' field
+func (c *checker) checkAddrTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	c.checkType(rv, field, reflect.TypeOf(&address.Address{}), reflect.TypeOf(""))
+}
+
+// checkBoolTag - validates 'bool' and 'bool N' field
+func (c *checker) checkBoolTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	if len(settings) > 1 {
+		boolBits(field.Name, settings[1])
+	}
+	c.checkKind(rv, field, reflect.Bool)
+}
+
+// checkFlagsTag - validates 'flags N' field
+func (c *checker) checkFlagsTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	parseFlagsTag(settings, field.Type)
+}
+
+// checkTimestampTag - validates 'timestamp N' field
+func (c *checker) checkTimestampTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	if !c.need(rv, field, settings, 1) {
+		return
+	}
+	if num, err := strconv.ParseUint(settings[1], 10, 64); err != nil || num > 64 {
+		c.problemf(rv.Type(), field, "corrupted num bits '%s' in timestamp tag", settings[1])
+	}
+	c.checkType(rv, field, reflect.TypeOf(time.Time{}))
+}
+
+// checkStrTag - validates 'str N' field
+func (c *checker) checkStrTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	strBytes(field, settings)
+	c.checkKind(rv, field, reflect.String)
+}
+
+// checkSkipTag - validates 'skip N [tag]' field
+func (c *checker) checkSkipTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	padBits(field.Name, settings, 0)
+	if len(settings) > 2 {
+		c.checkData(rv, field, settings[2:])
+	}
+}
+
+// checkPadTag - validates 'pad N' and 'align N' field
+func (c *checker) checkPadTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	padBits(field.Name, settings, 0)
+}
+
+// checkUnaryTag - validates 'unary' field
+func (c *checker) checkUnaryTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	c.checkKind(rv, field, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64)
+}
+
+// checkHashTag - validates 'hash' field
+func (c *checker) checkHashTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	c.checkType(rv, field, reflect.TypeOf(Bits256{}))
+}
+
+// checkUnionTag - validates 'union A B' field, all types should have magic and implement interface of the field
+func (c *checker) checkUnionTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	typ := field.Type
+	c.checkKind(rv, field, reflect.Interface)
+	if !c.need(rv, field, settings, 1) {
+		return
+	}
+	for _, name := range settings[1:] {
+		ut := registeredType(name)
+		if _, ok := magicOf(ut); !ok {
+			c.problemf(rv.Type(), field, "type '%s' used in union has no magic", name)
+		}
+		if typ.Kind() == reflect.Interface && !ut.Implements(typ) && !reflect.PtrTo(ut).Implements(typ) {
+			c.problemf(rv.Type(), field, "type '%s' not implements %s", name, typ.String())
+		}
+	}
+}
+
+// checkRefTag - validates '^' field, and '^ tag' field with definition inside ref
+func (c *checker) checkRefTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	if len(settings) > 1 {
+		c.checkData(rv, field, settings[1:])
+		return
+	}
+	c.checkValue(rv, field, field.Type)
+}
+
+// checkInnerTag - validates '.' field
+func (c *checker) checkInnerTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	c.checkValue(rv, field, field.Type)
+}
+
+// checkRemainingTag - validates 'remaining' field
+func (c *checker) checkRemainingTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	c.checkType(rv, field, reflect.TypeOf(&cell.Cell{}), reflect.TypeOf(&cell.Slice{}))
+}
+
+// checkUnknownTag - validates 'unknown' field
+func (c *checker) checkUnknownTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	c.checkType(rv, field, reflect.TypeOf(&cell.Cell{}))
+}
+
+// checkCoinsTag - validates 'coins' field
+func (c *checker) checkCoinsTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	c.checkType(rv, field, reflect.TypeOf(Coins{}), reflect.TypeOf(&big.Int{}), reflect.TypeOf(uint64(0)))
+}
+
+// checkCellTag - validates 'cell' field
+func (c *checker) checkCellTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	c.checkType(rv, field, reflect.TypeOf(&cell.Cell{}))
+}
+
+// checkRefsTag - validates 'refs' field
+func (c *checker) checkRefsTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	c.checkType(rv, field, reflect.TypeOf([]*cell.Cell{}))
+}
+
+// checkRepeatTag - validates 'repeat N [tag]' field
+func (c *checker) checkRepeatTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	_, elemSettings := parseRepeatTag(settings, field)
+	elem := reflect.StructField{Name: field.Name, Type: field.Type.Elem()}
+	c.checkData(rv, elem, elemSettings)
+}
+
+// checkChunkedTag - validates 'chunked' field
+func (c *checker) checkChunkedTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	c.checkType(rv, field, reflect.TypeOf([]byte{}))
+}
+
+// checkPfxDictTag - validates 'pfxdict N' field
+func (c *checker) checkPfxDictTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	if c.need(rv, field, settings, 1) {
+		c.checkSize(rv, field, settings[1])
+	}
+	c.checkType(rv, field, reflect.TypeOf(&cell.PrefixDictionary{}))
+}
+
+// checkDictAugTag - validates 'dictaug N' field
+func (c *checker) checkDictAugTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	if c.need(rv, field, settings, 1) {
+		c.checkSize(rv, field, settings[1])
+	}
+	typ := field.Type
+	ptr := reflect.PtrTo(typ)
+	if typ.Kind() == reflect.Pointer {
+		ptr = typ
+	}
+	if !ptr.Implements(reflect.TypeOf((*augDict)(nil)).Elem()) {
+		c.problemf(rv.Type(), field, "dictaug field type should be AugDict")
+	}
+}
+
+// checkDictTag - validates 'dict N [-> transformation]' field
+func (c *checker) checkDictTag(rv reflect.Value, field reflect.StructField, settings []string) {
+	if c.need(rv, field, settings, 1) {
+		c.checkSize(rv, field, settings[1])
+	}
+	c.checkDict(rv, field, settings)
+}
+
+func (c *checker) checkDict(rv reflect.Value, field reflect.StructField, settings []string) {
+	typ := field.Type
+	if len(settings) < 4 || settings[2] != "->" {
+		if len(settings) > 2 {
+			c.problemf(rv.Type(), field, "corrupted dict tag, transformation should be like 'dict N -> map'")
+			return
+		}
+		c.checkType(rv, field, reflect.TypeOf(&cell.Dictionary{}))
+		return
+	}
+	parseDictOptions(settings[4:])
+
+	switch settings[3] {
+	case "array":
+		if typ.Kind() != reflect.Slice {
+			c.problemf(rv.Type(), field, "dict array field should be a slice")
+			return
+		}
+
+		elem := typ.Elem()
+		if elem.Implements(dictEntryType) {
+			elem = elem.Field(1).Type
+		}
+		c.checkValue(rv, field, elem)
+	case "map":
+		if typ.Kind() != reflect.Map {
+			c.problemf(rv.Type(), field, "dict map field should be a map")
+			return
+		}
+		c.checkValue(rv, field, typ.Elem())
+	default:
+		c.problemf(rv.Type(), field, "transformation of dict to '%s' is not supported", settings[3])
+	}
+}
+
+// checkValue - validates type loaded as inner struct or cell
+func (c *checker) checkValue(rv reflect.Value, field reflect.StructField, typ reflect.Type) {
+	if typ == reflect.TypeOf(&cell.Cell{}) {
+		return
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if _, ok := asUnmarshaler(reflect.New(typ).Interface()); ok {
+		return
+	}
+
+	if typ.Kind() != reflect.Struct {
+		c.problemf(rv.Type(), field, "type %s cannot be loaded as inner struct", typ.String())
+		return
+	}
+	c.checkStruct(typ)
+}
+
+// checkInt - validates type of '## N' field and that N bits fit into it
+func (c *checker) checkInt(rv reflect.Value, field reflect.StructField, num uint) {
+	typ := field.Type
+	if typ == reflect.TypeOf(&big.Int{}) {
+		return
+	}
+
+	if num > 64 {
+		if typ.Kind() != reflect.String {
+			c.problemf(rv.Type(), field, "## %d can be loaded only to *big.Int or string", num)
+		}
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if uint(typ.Bits()) < num {
+			c.problemf(rv.Type(), field, "type %s is too small for ## %d", typ.String(), num)
+		}
+	default:
+		c.problemf(rv.Type(), field, "## %d can be loaded only to integer or *big.Int, not %s", num, typ.String())
+	}
+}
+
+// need - reports problem when tag has less than n args
+func (c *checker) need(rv reflect.Value, field reflect.StructField, settings []string, n int) bool {
+	if len(settings)-1 < n {
+		c.problemf(rv.Type(), field, "tag '%s' should have %d args", settings[0], n)
+		return false
+	}
+	return true
+}
+
+func (c *checker) checkSize(rv reflect.Value, field reflect.StructField, sz string) {
+	if _, err := strconv.ParseUint(sz, 10, 64); err != nil {
+		c.problemf(rv.Type(), field, "bad size '%s'", sz)
+	}
+}
+
+func (c *checker) checkType(rv reflect.Value, field reflect.StructField, allowed ...reflect.Type) {
+	names := make([]string, 0, len(allowed))
+	for _, t := range allowed {
+		if field.Type == t {
+			return
+		}
+		names = append(names, t.String())
+	}
+	c.problemf(rv.Type(), field, "type %s is not supported, should be %s", field.Type.String(), strings.Join(names, " or "))
+}
+
+func (c *checker) checkKind(rv reflect.Value, field reflect.StructField, allowed ...reflect.Kind) {
+	for _, k := range allowed {
+		if field.Type.Kind() == k {
+			return
+		}
+	}
+	c.problemf(rv.Type(), field, "type %s is not supported", field.Type.String())
+}
+
+func (c *checker) problemf(typ reflect.Type, field reflect.StructField, format string, args ...any) {
+	c.problems = append(c.problems, typ.String()+"."+field.Name+": "+fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,89 @@
+package tlb
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testCheckBad struct {
+	Small   uint8      `tlb:"## 16"`
+	Str     int        `tlb:"str 4"`
+	Ref     uint32     `tlb:"^"`
+	Cond    bool       `tlb:"if:Missing bool"`
+	Unknown *cell.Cell `tlb:"blah 3"`
+	Default uint32     `tlb:"## 32 default:5"`
+	Marked  []byte     `tlb:"bits 8 mark:x"`
+	Inner   struct {
+		Hash []byte `tlb:"hash"`
+	} `tlb:"maybe ^"`
+}
+
+func TestCheck(t *testing.T) {
+	for _, v := range []any{
+		Transaction{}, &Message{}, InternalMessage{}, ExternalMessage{}, StateInit{}, CurrencyCollection{},
+		testEitherBranch{}, testStrictOuter{}, testDeep{}, testMarshalerOuter{}, ConfigParams{},
+	} {
+		if err := Check(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := Check(&testCheckBad{})
+	var checkErr *CheckError
+	if !errors.As(err, &checkErr) || !errors.Is(err, ErrInvalidDefinition) {
+		t.Fatal("should fail with check error", err)
+	}
+
+	for i, want := range []string{"Small", "Str", "Ref", "Cond", "Unknown", "Default", "Marked", "Hash"} {
+		if i >= len(checkErr.Problems) || !strings.Contains(checkErr.Problems[i], "."+want+": ") {
+			t.Fatal("problem of", want, "is not reported", checkErr.Problems)
+		}
+	}
+
+	if err = Check(5); err == nil {
+		t.Fatal("should fail on not struct")
+	}
+}
+
+type testCheckBigCond struct {
+	Amount *big.Int `tlb:"maybe ## 64"`
+	Extra  uint8    `tlb:"if:Amount>=3 ## 8"`
+}
+
+func TestCheckBigIntCondition(t *testing.T) {
+	if err := Check(testCheckBigCond{}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := cell.BeginCell().MustStoreBoolBit(true).MustStoreUInt(5, 64).MustStoreUInt(7, 8).EndCell()
+
+	var x testCheckBigCond
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Amount.Uint64() != 5 || x.Extra != 7 {
+		t.Fatal("values not eq")
+	}
+
+	// absent *big.Int does not satisfy ordered condition
+	x.Extra = 1
+	if err := LoadFromCell(&x, cell.BeginCell().MustStoreBoolBit(false).EndCell().BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Amount != nil || x.Extra != 0 {
+		t.Fatal("condition should not be satisfied")
+	}
+
+	if err := Check(struct {
+		Flag bool  `tlb:"bool"`
+		Val  uint8 `tlb:"if:Flag>1 ## 8"`
+	}{}); err == nil {
+		t.Fatal("ordered condition on bool should be reported")
+	}
+}
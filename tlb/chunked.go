@@ -0,0 +1,60 @@
+package tlb
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// chunkSize - max bytes of chunk, which fits into one cell
+const chunkSize = 127
+
+// loadChunked - loads chunked_data#_ data:(HashmapE 32 ^(SnakeData ~0)) = ChunkedData
+func loadChunked(loader *cell.Slice) ([]byte, error) {
+	dict, err := loader.LoadDict(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dict: %w", err)
+	}
+
+	var data []byte
+	for i := 0; i < len(dict.All()); i++ {
+		v := dict.GetByIntKey(big.NewInt(int64(i)))
+		if v == nil {
+			return nil, fmt.Errorf("chunk %d is missing", i)
+		}
+
+		ref, err := v.BeginParse().LoadRef()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load ref of chunk %d: %w", i, err)
+		}
+
+		chunk, err := ref.LoadBinarySnake()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load chunk %d: %w", i, err)
+		}
+		data = append(data, chunk...)
+	}
+	return data, nil
+}
+
+func storeChunked(builder *cell.Builder, data []byte) error {
+	dict := cell.NewDict(32)
+	for i := 0; len(data) > 0; i++ {
+		n := chunkSize
+		if len(data) < n {
+			n = len(data)
+		}
+
+		chunk := cell.BeginCell().MustStoreSlice(data[:n], uint(n)*8).EndCell()
+		if err := dict.SetIntKey(big.NewInt(int64(i)), cell.BeginCell().MustStoreRef(chunk).EndCell()); err != nil {
+			return fmt.Errorf("failed to set chunk %d: %w", i, err)
+		}
+		data = data[n:]
+	}
+
+	if len(dict.All()) == 0 {
+		return builder.StoreDict(nil)
+	}
+	return builder.StoreDict(dict)
+}
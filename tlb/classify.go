@@ -0,0 +1,164 @@
+package tlb
+
+import (
+	"context"
+	"errors"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// Classification - best guess of message body kind
+type Classification struct {
+	// Label - kind of body, name of registered type, or one of known kinds,
+	// like "comment", "jetton_transfer", "nft_transfer", "empty", "unknown"
+	Label string
+	// Opcode - first 32 bits of body, valid when HasOpcode is true
+	Opcode    uint32
+	HasOpcode bool
+	// Confidence - from 0 to 1, how much the guess can be trusted
+	Confidence float64
+}
+
+type knownOpcode struct {
+	label string
+	// layout - checks rest of body after opcode, nil if layout is not checked
+	layout func(loader *cell.Slice) error
+}
+
+// layouts of standard bodies, which starts with query_id:uint64 after opcode
+var knownOpcodes = map[uint32]knownOpcode{
+	0xffffffff: {label: "bounced"},
+	0x2167da4b: {label: "encrypted_comment"},
+	0x0f8a7ea5: {label: "jetton_transfer", layout: layoutOf("## 64", "coins", "addr", "addr")},
+	0x178d4519: {label: "jetton_internal_transfer", layout: layoutOf("## 64", "coins", "addr", "addr", "coins")},
+	0x7362d09c: {label: "jetton_transfer_notification", layout: layoutOf("## 64", "coins", "addr")},
+	0xd53276db: {label: "excesses", layout: layoutOf("## 64")},
+	0x595f07bc: {label: "jetton_burn", layout: layoutOf("## 64", "coins", "addr")},
+	0x7bdd97de: {label: "jetton_burn_notification", layout: layoutOf("## 64", "coins", "addr", "addr")},
+	0x5fcc3d14: {label: "nft_transfer", layout: layoutOf("## 64", "addr", "addr")},
+	0x05138d91: {label: "nft_ownership_assigned", layout: layoutOf("## 64", "addr")},
+	0x2fcb26a2: {label: "nft_get_static_data", layout: layoutOf("## 64")},
+	0x8b771735: {label: "nft_report_static_data", layout: layoutOf("## 64", "## 256", "addr")},
+}
+
+// Classify - heuristically detects kind of message body, useful to triage traffic of unknown contracts.
+// Registered types are checked first, then known standard opcodes, text comments and plain text.
+// Body is not modified.
+func Classify(body *cell.Cell) Classification {
+	return ClassifyContext(context.Background(), body)
+}
+
+// ClassifyContext - the same as Classify, but options set using WithOptions in ctx are applied
+// when registered types are decoded
+func ClassifyContext(ctx context.Context, body *cell.Cell) Classification {
+	if body == nil || (body.BitsSize() == 0 && body.RefsNum() == 0) {
+		return Classification{Label: "empty", Confidence: 1}
+	}
+
+	if body.BitsSize() < 32 {
+		return Classification{Label: "unknown", Confidence: 0.1}
+	}
+
+	loader := body.BeginParse()
+	op := uint32(loader.MustLoadUInt(32))
+	res := Classification{Opcode: op, HasOpcode: true}
+
+	if op == 0 {
+		res.Label = "comment"
+		res.Confidence = 0.6
+		if isText(loader.Copy()) {
+			res.Confidence = 0.99
+		}
+		return res
+	}
+
+	_, name, err := loadAny(ctx, body.BeginParse(), 0, false)
+	if err == nil {
+		res.Label = name
+		res.Confidence = 0.95
+		return res
+	}
+
+	known, isKnown := knownOpcodes[op]
+	if !errors.Is(err, ErrNoMatchingType) && !isKnown {
+		// magic matched registered type, but body is corrupted
+		res.Label, _ = matchingTypeName(body.BeginParse())
+		res.Confidence = 0.4
+		return res
+	}
+
+	if isKnown {
+		res.Label = known.label
+		res.Confidence = 0.7
+		if known.layout != nil {
+			if known.layout(loader.Copy()) == nil {
+				res.Confidence = 0.9
+			} else {
+				res.Confidence = 0.4
+			}
+		}
+		return res
+	}
+
+	if body.RefsNum() == 0 && isText(body.BeginParse()) {
+		return Classification{Label: "text", Confidence: 0.5}
+	}
+
+	res.Label = "unknown"
+	res.Confidence = 0.1
+	return res
+}
+
+// matchingTypeName - returns name of registered type which magic matches loader, without loading it
+func matchingTypeName(loader *cell.Slice) (string, bool) {
+	registry.mx.RLock()
+	magics := registry.magics
+	registry.mx.RUnlock()
+
+	for _, m := range magics {
+		if ok, err := m.magic.match(loader); err == nil && ok {
+			return m.name, true
+		}
+	}
+	return "", false
+}
+
+// layoutOf - builds checker which loads sequence of simple tags
+func layoutOf(tags ...string) func(loader *cell.Slice) error {
+	return func(loader *cell.Slice) error {
+		for _, tag := range tags {
+			var err error
+			switch tag {
+			case "## 64":
+				_, err = loader.LoadUInt(64)
+			case "## 256":
+				_, err = loader.LoadBigUInt(256)
+			case "coins":
+				_, err = loader.LoadBigCoins()
+			case "addr":
+				_, err = loader.LoadAddr()
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// isText - checks that snake data of loader is printable utf8 text
+func isText(loader *cell.Slice) bool {
+	str, err := loader.LoadStringSnake()
+	if err != nil || len(str) == 0 || !utf8.ValidString(str) {
+		return false
+	}
+
+	for _, r := range str {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
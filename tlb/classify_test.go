@@ -0,0 +1,44 @@
+package tlb
+
+import (
+	"testing"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testClassified struct {
+	_   Magic  `tlb:"#a1b2c3d4"`
+	Val uint32 `tlb:"## 32"`
+}
+
+func TestClassify(t *testing.T) {
+	Register("TestClassified", testClassified{})
+
+	addr := address.MustParseAddr("EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N")
+
+	tests := []struct {
+		name  string
+		body  *cell.Cell
+		label string
+		min   float64
+	}{
+		{"nil", nil, "empty", 1},
+		{"comment", cell.BeginCell().MustStoreUInt(0, 32).MustStoreStringSnake("hello").EndCell(), "comment", 0.9},
+		{"registered", cell.BeginCell().MustStoreUInt(0xa1b2c3d4, 32).MustStoreUInt(1, 32).EndCell(), "TestClassified", 0.9},
+		{"corrupted registered", cell.BeginCell().MustStoreUInt(0xa1b2c3d4, 32).MustStoreUInt(1, 8).EndCell(), "TestClassified", 0.3},
+		{"jetton", cell.BeginCell().MustStoreUInt(0x0f8a7ea5, 32).MustStoreUInt(1, 64).MustStoreBigCoins(MustFromTON("1").NanoTON()).
+			MustStoreAddr(addr).MustStoreAddr(addr).MustStoreUInt(0, 1).EndCell(), "jetton_transfer", 0.9},
+		{"broken jetton", cell.BeginCell().MustStoreUInt(0x0f8a7ea5, 32).MustStoreUInt(1, 16).EndCell(), "jetton_transfer", 0.3},
+		{"unknown", cell.BeginCell().MustStoreUInt(0xdeadbeef, 32).MustStoreUInt(1, 16).EndCell(), "unknown", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Classify(tt.body)
+			if res.Label != tt.label || res.Confidence < tt.min {
+				t.Fatal("incorrect classification", res)
+			}
+		})
+	}
+}
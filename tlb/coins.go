@@ -26,6 +26,11 @@ func (g Coins) TON() string {
 		return a
 	}
 
+	sign := ""
+	if g.val.Sign() < 0 {
+		sign, a = "-", a[1:]
+	}
+
 	splitter := len(a) - 9
 	if splitter <= 0 {
 		a = "0." + strings.Repeat("0", 9-len(a)) + a
@@ -46,7 +51,7 @@ func (g Coins) TON() string {
 		}
 	}
 
-	return a
+	return sign + a
 }
 
 func (g Coins) NanoTON() *big.Int {
@@ -124,6 +129,36 @@ func FromTON(val string) (Coins, error) {
 	}, nil
 }
 
+// Add - returns sum of g and x
+func (g Coins) Add(x Coins) Coins {
+	return Coins{val: new(big.Int).Add(g.NanoTON(), x.NanoTON())}
+}
+
+// Sub - returns g minus x, error is returned when x is greater than g, because coins cannot be negative
+func (g Coins) Sub(x Coins) (Coins, error) {
+	v := new(big.Int).Sub(g.NanoTON(), x.NanoTON())
+	if v.Sign() < 0 {
+		return Coins{}, fmt.Errorf("cannot subtract %s from %s, result is negative", x.TON(), g.TON())
+	}
+	return Coins{val: v}, nil
+}
+
+// MulRat - returns g multiplied by num/denom, rounded down, useful to calculate fees and percents
+func (g Coins) MulRat(num, denom uint64) Coins {
+	v := new(big.Int).Mul(g.NanoTON(), new(big.Int).SetUint64(num))
+	return Coins{val: v.Quo(v, new(big.Int).SetUint64(denom))}
+}
+
+// Cmp - compares g and x, returns -1, 0 or +1
+func (g Coins) Cmp(x Coins) int {
+	return g.NanoTON().Cmp(x.NanoTON())
+}
+
+// IsZero - checks is amount 0
+func (g Coins) IsZero() bool {
+	return g.NanoTON().Sign() == 0
+}
+
 func (g *Coins) LoadFromCell(loader *cell.Slice) error {
 	coins, err := loader.LoadBigCoins()
 	if err != nil {
@@ -134,13 +169,35 @@ func (g *Coins) LoadFromCell(loader *cell.Slice) error {
 }
 
 func (g Coins) ToCell() (*cell.Cell, error) {
-	return cell.BeginCell().MustStoreBigCoins(g.NanoTON()).EndCell(), nil
+	b := cell.BeginCell()
+	if err := b.StoreBigCoins(g.NanoTON()); err != nil {
+		return nil, fmt.Errorf("failed to store coins %s: %w", g.TON(), err)
+	}
+	return b.EndCell(), nil
 }
 
 func (g Coins) MarshalJSON() ([]byte, error) {
 	return []byte(fmt.Sprintf("%q", g.NanoTON().String())), nil
 }
 
+func (g *Coins) UnmarshalJSON(data []byte) error {
+	str, err := strconv.Unquote(string(data))
+	if err != nil {
+		str = string(data)
+	}
+
+	val, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return fmt.Errorf("incorrect coins value %s", string(data))
+	}
+
+	if val.Sign() < 0 {
+		return fmt.Errorf("coins value should not be negative")
+	}
+	g.val = val
+	return nil
+}
+
 func (g Coins) String() string {
 	return g.TON()
 }
@@ -1,7 +1,11 @@
 package tlb
 
 import (
+	"bytes"
+	"math/big"
 	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
 )
 
 func TestCoins_FromTON(t *testing.T) {
@@ -102,3 +106,80 @@ func TestCoins_TON(t *testing.T) {
 		t.Fatalf("350 wrong: %s", g.TON())
 	}
 }
+
+func TestCoins_Arithmetic(t *testing.T) {
+	a := MustFromTON("1.5")
+	b := MustFromTON("0.25")
+
+	diff, err := a.Sub(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Add(b).String() != "1.75" || diff.String() != "1.25" {
+		t.Fatal("add/sub wrong")
+	}
+
+	if _, err = b.Sub(a); err == nil {
+		t.Fatal("should fail on negative result")
+	}
+
+	if a.MulRat(1, 3).NanoTON().Uint64() != 500000000 {
+		t.Fatal("mul rat wrong")
+	}
+
+	if a.Cmp(b) != 1 || b.Cmp(a) != -1 || a.Cmp(MustFromTON("1.5")) != 0 {
+		t.Fatal("cmp wrong")
+	}
+
+	if !(Coins{}).IsZero() || a.IsZero() {
+		t.Fatal("is zero wrong")
+	}
+}
+
+type testCoinsTag struct {
+	Amount  Coins    `tlb:"coins"`
+	Fee     *big.Int `tlb:"coins"`
+	Forward uint64   `tlb:"coins"`
+}
+
+func TestCoins_Negative(t *testing.T) {
+	g := FromNanoTON(big.NewInt(-500000000))
+	if g.TON() != "-0.5" {
+		t.Fatal("incorrect negative format", g.TON())
+	}
+
+	if FromNanoTON(big.NewInt(-1500000001)).TON() != "-1.500000001" {
+		t.Fatal("incorrect negative format with hi part")
+	}
+
+	if _, err := g.ToCell(); err == nil {
+		t.Fatal("should fail to store negative coins")
+	}
+
+	if _, err := ToCell(testCoinsTag{Amount: g}); err == nil {
+		t.Fatal("should fail to store negative coins tag")
+	}
+}
+
+func TestCoins_Tag(t *testing.T) {
+	a := cell.BeginCell().MustStoreBigCoins(big.NewInt(1500000000)).MustStoreBigCoins(big.NewInt(7)).MustStoreBigCoins(big.NewInt(0)).EndCell()
+
+	var x testCoinsTag
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Amount.String() != "1.5" || x.Fee.Uint64() != 7 || x.Forward != 0 {
+		t.Fatal("not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
@@ -0,0 +1,63 @@
+package tlb
+
+import (
+	"encoding/hex"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// SuspendedAddressList - config param 44
+type SuspendedAddressList struct {
+	_ Magic `tlb:"#00"`
+	// Addresses - dict with keys of workchain (32 bits) + address (256 bits) and empty values
+	Addresses      *cell.Dictionary `tlb:"dict 288"`
+	SuspendedUntil uint32           `tlb:"## 32"`
+}
+
+// PrecompiledSmc - gas usage of precompiled contract
+type PrecompiledSmc struct {
+	_        Magic  `tlb:"#b0"`
+	GasUsage uint64 `tlb:"## 64"`
+}
+
+// PrecompiledContractsConfig - config param 45, keys are hex of contract code hashes
+type PrecompiledContractsConfig struct {
+	_    Magic                     `tlb:"#c0"`
+	List map[string]PrecompiledSmc `tlb:"dict 256 -> map"`
+}
+
+// List - returns all suspended addresses
+func (s *SuspendedAddressList) List() []*address.Address {
+	if s.Addresses == nil {
+		return nil
+	}
+
+	var res []*address.Address
+	for _, kv := range s.Addresses.All() {
+		ld := kv.Key.BeginParse()
+		wc := ld.MustLoadInt(32)
+		data := ld.MustLoadSlice(256)
+		res = append(res, address.NewAddress(0, byte(wc), data))
+	}
+	return res
+}
+
+// IsSuspended - checks is address in list, time of suspension should be checked separately using SuspendedUntil
+func (s *SuspendedAddressList) IsSuspended(addr *address.Address) bool {
+	if s.Addresses == nil {
+		return false
+	}
+
+	key := cell.BeginCell().MustStoreInt(int64(addr.Workchain()), 32).MustStoreSlice(addr.Data(), 256).EndCell()
+	return s.Addresses.Get(key) != nil
+}
+
+// GasUsage - returns fixed gas usage of precompiled contract with code hash
+func (p *PrecompiledContractsConfig) GasUsage(codeHash []byte) (uint64, bool) {
+	smc, ok := p.List[hex.EncodeToString(codeHash)]
+	if !ok {
+		return 0, false
+	}
+	return smc.GasUsage, true
+}
@@ -0,0 +1,63 @@
+package tlb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+func TestSuspendedAddressList(t *testing.T) {
+	addr := address.MustParseAddr("EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N")
+
+	d := cell.NewDict(288)
+	_ = d.Set(cell.BeginCell().MustStoreInt(0, 32).MustStoreSlice(addr.Data(), 256).EndCell(), cell.BeginCell().EndCell())
+
+	a := cell.BeginCell().MustStoreUInt(0, 8).MustStoreDict(d).MustStoreUInt(1700000000, 32).EndCell()
+
+	var x SuspendedAddressList
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.SuspendedUntil != 1700000000 || !x.IsSuspended(addr) {
+		t.Fatal("list not eq")
+	}
+
+	list := x.List()
+	if len(list) != 1 || !bytes.Equal(list[0].Data(), addr.Data()) || list[0].Workchain() != 0 {
+		t.Fatal("addresses not eq")
+	}
+
+	if x.IsSuspended(address.NewAddress(0, 0, make([]byte, 32))) {
+		t.Fatal("should not be suspended")
+	}
+}
+
+func TestPrecompiledContractsConfig(t *testing.T) {
+	hash := bytes.Repeat([]byte{0x11}, 32)
+
+	d := cell.NewDict(256)
+	_ = d.Set(cell.BeginCell().MustStoreSlice(hash, 256).EndCell(), cell.BeginCell().MustStoreUInt(0xb0, 8).MustStoreUInt(1000, 64).EndCell())
+
+	a := cell.BeginCell().MustStoreUInt(0xc0, 8).MustStoreDict(d).EndCell()
+
+	var x PrecompiledContractsConfig
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if gas, ok := x.GasUsage(hash); !ok || gas != 1000 {
+		t.Fatal("gas usage not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
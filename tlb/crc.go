@@ -0,0 +1,24 @@
+package tlb
+
+import (
+	"hash/crc32"
+	"strings"
+)
+
+// crcMagic - computes constructor tag from TL-B declaration, the same way as TL-B compiler does:
+// crc32 of declaration without constructor tag, parentheses and semicolon, with the highest bit cleared
+func crcMagic(decl string) uint32 {
+	decl = strings.NewReplacer("(", "", ")", " ", ";", " ").Replace(decl)
+
+	parts := strings.Fields(decl)
+	if len(parts) == 0 {
+		panic("empty declaration in crc magic tag")
+	}
+
+	// cut explicit constructor tag, like 'transfer#_' or 'transfer#0f8a7ea5'
+	if i := strings.IndexAny(parts[0], "#$"); i >= 0 {
+		parts[0] = parts[0][:i]
+	}
+
+	return crc32.ChecksumIEEE([]byte(strings.Join(parts, " "))) & 0x7fffffff
+}
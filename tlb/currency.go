@@ -0,0 +1,86 @@
+package tlb
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// ExtraCurrencyCollection - amounts of extra currencies by their ids,
+// extra_currencies$_ dict:(HashmapE 32 (VarUInteger 32)), can be used in structs with '.' tag
+type ExtraCurrencyCollection map[uint32]*big.Int
+
+// ExtraCurrenciesFromDict - parses dictionary of extra currencies, nil dict is empty collection
+func ExtraCurrenciesFromDict(dict *cell.Dictionary) (ExtraCurrencyCollection, error) {
+	res := ExtraCurrencyCollection{}
+	if dict == nil {
+		return res, nil
+	}
+
+	for _, kv := range dict.All() {
+		id, err := kv.Key.BeginParse().LoadUInt(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load currency id: %w", err)
+		}
+
+		amount, err := kv.Value.BeginParse().LoadVarUInt(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load amount of currency %d: %w", id, err)
+		}
+		res[uint32(id)] = amount
+	}
+	return res, nil
+}
+
+// ToDict - serializes collection to dictionary, returns nil for empty collection
+func (e ExtraCurrencyCollection) ToDict() (*cell.Dictionary, error) {
+	if len(e) == 0 {
+		return nil, nil
+	}
+
+	dict := cell.NewDict(32)
+	for id, amount := range e {
+		if amount == nil || amount.Sign() < 0 {
+			return nil, fmt.Errorf("amount of currency %d should not be nil or negative", id)
+		}
+
+		ln := uint((amount.BitLen() + 7) / 8)
+		if ln >= 32 {
+			return nil, fmt.Errorf("amount of currency %d is too big", id)
+		}
+
+		b := cell.BeginCell().MustStoreUInt(uint64(ln), 5)
+		if err := b.StoreBigUInt(amount, ln*8); err != nil {
+			return nil, fmt.Errorf("failed to store amount of currency %d: %w", id, err)
+		}
+
+		if err := dict.Set(cell.BeginCell().MustStoreUInt(uint64(id), 32).EndCell(), b.EndCell()); err != nil {
+			return nil, fmt.Errorf("failed to set currency %d: %w", id, err)
+		}
+	}
+	return dict, nil
+}
+
+func (e *ExtraCurrencyCollection) LoadFromCell(loader *cell.Slice) error {
+	dict, err := loader.LoadDict(32)
+	if err != nil {
+		return fmt.Errorf("failed to load extra currencies dict: %w", err)
+	}
+
+	*e, err = ExtraCurrenciesFromDict(dict)
+	return err
+}
+
+func (e ExtraCurrencyCollection) ToCell() (*cell.Cell, error) {
+	dict, err := e.ToDict()
+	if err != nil {
+		return nil, err
+	}
+	return cell.BeginCell().MustStoreDict(dict).EndCell(), nil
+}
+
+// Extra - returns parsed extra currencies of collection
+func (c CurrencyCollection) Extra() (ExtraCurrencyCollection, error) {
+	return ExtraCurrenciesFromDict(c.ExtraCurrencies)
+}
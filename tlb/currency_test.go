@@ -0,0 +1,53 @@
+package tlb
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testExtraCurrencies struct {
+	Coins Coins                   `tlb:"coins"`
+	Extra ExtraCurrencyCollection `tlb:"."`
+}
+
+func TestExtraCurrencyCollection(t *testing.T) {
+	d := cell.NewDict(32)
+	_ = d.Set(cell.BeginCell().MustStoreUInt(239, 32).EndCell(), cell.BeginCell().MustStoreUInt(2, 5).MustStoreUInt(1000, 16).EndCell())
+
+	a := cell.BeginCell().MustStoreBigCoins(big.NewInt(5)).MustStoreDict(d).EndCell()
+
+	var x testExtraCurrencies
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.Extra) != 1 || x.Extra[239].Uint64() != 1000 {
+		t.Fatal("not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	var cc CurrencyCollection
+	if err = LoadFromCell(&cc, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	extra, err := cc.Extra()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if extra[239].Uint64() != 1000 {
+		t.Fatal("not eq from collection")
+	}
+}
@@ -0,0 +1,67 @@
+package tlb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// TagHandler - implementation of custom tag keyword, registered using RegisterTag.
+// Args are the rest settings of the tag after keyword, value is the field to load to or store from.
+type TagHandler struct {
+	Load  func(loader *cell.Slice, args []string, value reflect.Value) error
+	Store func(builder *cell.Builder, args []string, value reflect.Value) error
+}
+
+var customTags = struct {
+	mx       sync.RWMutex
+	handlers map[string]TagHandler
+}{
+	handlers: map[string]TagHandler{},
+}
+
+// prefixTags - builtin keywords which are not in dataTags, because they wrap the definition of the field
+var prefixTags = map[string]bool{"maybe": true, "either": true}
+
+// RegisterTag - registers custom tag keyword, so domain specific encodings can be used in struct tags,
+// for example after RegisterTag("myenc", handler) field with tag `tlb:"myenc 8"` is loaded using handler.Load
+// with args ["8"]. Custom tags can be combined with modifiers and with maybe, either and '^' prefixes.
+func RegisterTag(name string, handler TagHandler) {
+	if name == "" || strings.ContainsAny(name, " :#$()") || prefixTags[name] || dataTags[name].load != nil || len(expandAlias([]string{name})) > 1 {
+		panic("invalid custom tag name")
+	}
+
+	if handler.Load == nil || handler.Store == nil {
+		panic("custom tag handler should have both load and store")
+	}
+
+	customTags.mx.Lock()
+	defer customTags.mx.Unlock()
+
+	customTags.handlers[name] = handler
+}
+
+func customTag(name string) (TagHandler, bool) {
+	customTags.mx.RLock()
+	defer customTags.mx.RUnlock()
+
+	h, ok := customTags.handlers[name]
+	return h, ok
+}
+
+func loadCustomTag(h TagHandler, field reflect.StructField, fieldVal reflect.Value, settings []string, loader *cell.Slice) error {
+	if err := h.Load(loader, settings[1:], fieldVal); err != nil {
+		return fmt.Errorf("failed to load %s using custom tag '%s', err: %w", field.Name, settings[0], err)
+	}
+	return nil
+}
+
+func storeCustomTag(h TagHandler, field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder) error {
+	if err := h.Store(builder, settings[1:], fieldVal); err != nil {
+		return fmt.Errorf("failed to store %s using custom tag '%s', err: %w", field.Name, settings[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package tlb
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// bcd N - N decimal digits, 4 bits each
+var testBCDHandler = TagHandler{
+	Load: func(loader *cell.Slice, args []string, value reflect.Value) error {
+		n, _ := strconv.Atoi(args[0])
+
+		var res uint64
+		for i := 0; i < n; i++ {
+			d, err := loader.LoadUInt(4)
+			if err != nil {
+				return err
+			}
+			res = res*10 + d
+		}
+		value.SetUint(res)
+		return nil
+	},
+	Store: func(builder *cell.Builder, args []string, value reflect.Value) error {
+		n, _ := strconv.Atoi(args[0])
+
+		digits := make([]uint64, n)
+		v := value.Uint()
+		for i := n - 1; i >= 0; i-- {
+			digits[i] = v % 10
+			v /= 10
+		}
+
+		for _, d := range digits {
+			if err := builder.StoreUInt(d, 4); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+type testCustomTag struct {
+	Code  uint32 `tlb:"bcd 4"`
+	Extra uint16 `tlb:"maybe bcd 2"`
+}
+
+func TestRegisterTag(t *testing.T) {
+	RegisterTag("bcd", testBCDHandler)
+
+	a := cell.BeginCell().MustStoreUInt(0x1234, 16).MustStoreBoolBit(true).MustStoreUInt(0x56, 8).EndCell()
+
+	var x testCustomTag
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Code != 1234 || x.Extra != 56 {
+		t.Fatal("incorrect values", x)
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("should panic on builtin tag name")
+		}
+	}()
+	RegisterTag("dict", testBCDHandler)
+}
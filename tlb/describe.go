@@ -0,0 +1,168 @@
+package tlb
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Constructor - magic prefix of the type
+type Constructor struct {
+	Tag string
+	// Value - magic number, 0 when magic is longer than 64 bits
+	Value uint64
+	Bits  uint
+	// Alternatives - other accepted constructors, declared like '#aabbccdd|#11223344'
+	Alternatives []Constructor
+}
+
+type FieldDescriptor struct {
+	Name string
+	// Tag - full tlb tag of the field, with expanded fragments
+	Tag string
+	// GoType - type of the struct field
+	GoType string
+	// Bits - fixed size of the field in bits, 0 when size is dynamic
+	Bits uint
+	// Maybe - field is prefixed with maybe bit
+	Maybe bool
+	// Ref - field is stored in a separate cell
+	Ref bool
+	// Union - names of candidate types for union fields
+	Union []string
+	// Inner - descriptor of the nested struct, when field is loaded using '.' or '^'
+	Inner *TypeDescriptor
+}
+
+type TypeDescriptor struct {
+	Name        string
+	Constructor *Constructor
+	Fields      []FieldDescriptor
+}
+
+// DescribeType - returns description of the struct schema declared using tlb tags,
+// it can be used by generic tooling to introspect types. Accepts value or pointer to struct.
+func DescribeType(v any) (*TypeDescriptor, error) {
+	typ := reflect.TypeOf(v)
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errors.New("v should be a struct or pointer to struct")
+	}
+
+	return describeType(typ, map[reflect.Type]*TypeDescriptor{}), nil
+}
+
+func describeType(typ reflect.Type, seen map[reflect.Type]*TypeDescriptor) *TypeDescriptor {
+	if d, ok := seen[typ]; ok {
+		// recursive type, return already known descriptor
+		return d
+	}
+
+	desc := &TypeDescriptor{
+		Name: typ.String(),
+	}
+	seen[typ] = desc
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := fieldTag(field)
+		if tag == "-" {
+			continue
+		}
+
+		if field.Type == reflect.TypeOf(Magic{}) {
+			alts, _ := magicAlternatives(tag)
+			for j, magic := range parseMagics(tag) {
+				val, _ := magic.uint64()
+				c := Constructor{
+					Tag:   alts[j],
+					Value: val,
+					Bits:  magic.sz,
+				}
+
+				if desc.Constructor == nil {
+					desc.Constructor = &c
+					continue
+				}
+				desc.Constructor.Alternatives = append(desc.Constructor.Alternatives, c)
+			}
+			continue
+		}
+
+		fd := FieldDescriptor{
+			Name:   field.Name,
+			Tag:    tag,
+			GoType: field.Type.String(),
+		}
+
+		var settings []string
+		for _, s := range strings.Split(tag, " ") {
+			if strings.HasPrefix(s, "maybe:") {
+				fd.Maybe = true
+			}
+
+			// skip modifiers
+			if !strings.Contains(s, ":") {
+				settings = append(settings, s)
+			}
+		}
+
+		if len(settings) > 0 && settings[0] == "maybe" {
+			fd.Maybe = true
+			settings = settings[1:]
+		}
+
+		if len(settings) > 0 && settings[0] == "^" {
+			fd.Ref = true
+			if len(settings) > 1 {
+				settings = settings[1:]
+			}
+		}
+
+		settings = expandAlias(settings)
+		if len(settings) > 0 {
+			switch settings[0] {
+			case "##", "bits", "timestamp", "flags", "pad":
+				if len(settings) > 1 {
+					if n, err := strconv.ParseUint(settings[1], 10, 64); err == nil {
+						fd.Bits = uint(n)
+					}
+				}
+			case "str":
+				if len(settings) > 1 {
+					if n, err := strconv.ParseUint(settings[1], 10, 64); err == nil {
+						fd.Bits = uint(n) * 8
+					}
+				}
+			case "bool":
+				fd.Bits = 1
+				if len(settings) > 1 {
+					if n, err := strconv.ParseUint(settings[1], 10, 64); err == nil {
+						fd.Bits = uint(n)
+					}
+				}
+			case "hash":
+				fd.Bits = 256
+			case "union":
+				fd.Union = settings[1:]
+			case "^", ".":
+				inner := field.Type
+				if inner.Kind() == reflect.Pointer {
+					inner = inner.Elem()
+				}
+
+				if inner.Kind() == reflect.Struct {
+					fd.Inner = describeType(inner, seen)
+				}
+			}
+		}
+
+		desc.Fields = append(desc.Fields, fd)
+	}
+
+	return desc
+}
@@ -0,0 +1,36 @@
+package tlb
+
+import (
+	"testing"
+)
+
+func TestDescribeType(t *testing.T) {
+	d, err := DescribeType(&testTLB{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Constructor == nil || d.Constructor.Value != 0xffaa || d.Constructor.Bits != 16 {
+		t.Fatal("constructor not eq")
+	}
+
+	if len(d.Fields) != 6 {
+		t.Fatal("fields num not eq", len(d.Fields))
+	}
+
+	if d.Fields[0].Name != "Val" || d.Fields[0].Bits != 32 {
+		t.Fatal("field val not eq")
+	}
+
+	if !d.Fields[2].Maybe || !d.Fields[2].Ref || d.Fields[2].Inner == nil {
+		t.Fatal("maybe ref field not eq")
+	}
+
+	if d.Fields[1].Inner.Constructor.Value != 0b1011 || d.Fields[1].Inner.Fields[0].Bits != 34 {
+		t.Fatal("inner not eq")
+	}
+
+	if _, err = DescribeType(1); err == nil {
+		t.Fatal("should be error for not struct")
+	}
+}
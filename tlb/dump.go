@@ -0,0 +1,186 @@
+package tlb
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// Dump - returns annotated view of v serialized to cell, in the format close to fift, useful to debug mismatched schemas.
+// Each stored field is printed on its own line with its tag, stored bits as size[HEX] (including maybe and either bits),
+// decoded value of simple types and indexes of refs it took. Inner structs stored using '.', '^' and union
+// are printed indented under their fields, other refs are printed as cells. Accepts value or pointer to struct,
+// when it cannot be serialized, error is printed instead of fields.
+func Dump(v any) string {
+	var sb strings.Builder
+	dumpStruct(&sb, reflect.ValueOf(v), "")
+	return sb.String()
+}
+
+func dumpStruct(sb *strings.Builder, rv reflect.Value, indent string) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			sb.WriteString("nil\n")
+			return
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		sb.WriteString("error: value should be a struct or pointer to struct\n")
+		return
+	}
+
+	c, spans, err := storeSpans(rv)
+	if err != nil {
+		fmt.Fprintf(sb, "%s {\n%s  error: %v\n%s}\n", rv.Type().Name(), indent, err, indent)
+		return
+	}
+
+	var refs []*cell.Cell
+	for loader := c.BeginParse(); loader.RefsNum() > 0; {
+		ref := loader.MustLoadRef()
+		refs = append(refs, ref.MustToCell())
+	}
+
+	fmt.Fprintf(sb, "%s = %d bits, %d refs {\n", rv.Type().Name(), c.BitsSize(), c.RefsNum())
+	if spans == nil {
+		// stored using Marshaler or custom ToCell, fields are unknown
+		sb.WriteString(dumpCell(c, indent+"  ") + "\n")
+	}
+
+	plan := planOf(rv.Type())
+	for _, sp := range spans {
+		field, fieldVal := rv.Type().Field(sp.index), rv.Field(sp.index)
+
+		fmt.Fprintf(sb, "%s  %s `%s`: %s", indent, field.Name, fieldTag(field), dumpBits(c, sp.bitsFrom, sp.bitsTo))
+		if val, ok := dumpScalar(fieldVal); ok {
+			sb.WriteString(" = " + val)
+		}
+
+		if sp.refsTo > sp.refsFrom {
+			sb.WriteString(" ->")
+			for r := sp.refsFrom; r < sp.refsTo; r++ {
+				fmt.Fprintf(sb, " ^%d", r)
+			}
+		}
+
+		if inner, ok := dumpInner(plan.fields[sp.index].settings, fieldVal); ok {
+			sb.WriteString(" ")
+			dumpStruct(sb, inner, indent+"  ")
+			continue
+		}
+		sb.WriteString("\n")
+
+		for r := sp.refsFrom; r < sp.refsTo; r++ {
+			fmt.Fprintf(sb, "%s    ^%d = %s\n", indent, r, strings.TrimLeft(dumpCell(refs[r], indent+"    "), " "))
+		}
+	}
+	sb.WriteString(indent + "}\n")
+}
+
+// storeSpans - serializes struct and returns spans of its fields, spans are nil when Marshaler or custom ToCell is used
+func storeSpans(rv reflect.Value) (c *cell.Cell, spans []fieldSpan, err error) {
+	defer recoverDefinition(rv.Type(), &err)
+
+	if !rv.CanInterface() {
+		// embedded struct of unexported type cannot be accessed as a whole, so we copy its fields
+		cp := reflect.New(rv.Type()).Elem()
+		copyFields(cp, rv)
+		rv = cp
+	}
+
+	if store, ok := asMarshaler(rv.Interface()); ok {
+		c, err = store()
+		return c, nil, err
+	}
+
+	spans = []fieldSpan{}
+	builder := cell.BeginCell()
+	if err = storeToBuilder(rv.Interface(), builder, storeOptions{}, &auditor{spans: &spans}, nil); err != nil {
+		return nil, nil, err
+	}
+	return builder.EndCell(), spans, nil
+}
+
+// dumpInner - returns inner struct of the field, when it is stored using '.', '^' or union
+func dumpInner(settings []string, fieldVal reflect.Value) (reflect.Value, bool) {
+	if len(settings) > 0 && settings[0] == "maybe" {
+		settings = settings[1:]
+	}
+
+	switch {
+	case len(settings) == 1 && (settings[0] == "." || settings[0] == "^"):
+	case len(settings) > 1 && settings[0] == "union":
+	case len(settings) > 2 && settings[0] == "^" && settings[1] == "union":
+	default:
+		return reflect.Value{}, false
+	}
+
+	for fieldVal.Kind() == reflect.Pointer || fieldVal.Kind() == reflect.Interface {
+		if fieldVal.IsNil() {
+			return reflect.Value{}, false
+		}
+		fieldVal = fieldVal.Elem()
+	}
+
+	switch fieldVal.Type() {
+	case reflect.TypeOf(cell.Cell{}), reflect.TypeOf(cell.Slice{}), reflect.TypeOf(cell.Dictionary{}), reflect.TypeOf(Coins{}):
+		return reflect.Value{}, false
+	}
+
+	if fieldVal.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return fieldVal, true
+}
+
+// dumpScalar - formats value of simple types, like integers, addresses and coins
+func dumpScalar(fieldVal reflect.Value) (string, bool) {
+	if !fieldVal.CanInterface() {
+		return "", false
+	}
+
+	switch v := fieldVal.Interface().(type) {
+	case *big.Int:
+		if v != nil {
+			return v.String(), true
+		}
+	case *address.Address:
+		if v != nil {
+			return v.String(), true
+		}
+	case Coins:
+		return v.String() + " TON", true
+	case Bits256:
+		return v.String(), true
+	}
+
+	switch fieldVal.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%v", fieldVal.Interface()), true
+	}
+	return "", false
+}
+
+// dumpBits - returns bits [from, to) of cell in fift format
+func dumpBits(c *cell.Cell, from, to uint) string {
+	loader := c.BeginParse()
+	loader.MustLoadSlice(from)
+	return cell.BeginCell().MustStoreSlice(loader.MustLoadSlice(to-from), to-from).EndCell().Dump()
+}
+
+// dumpCell - returns dump of cell with all lines indented
+func dumpCell(c *cell.Cell, indent string) string {
+	lines := strings.Split(strings.TrimRight(c.Dump(), "\n"), "\n")
+	for i := range lines {
+		lines[i] = indent + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,50 @@
+package tlb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testDumpInner struct {
+	Val uint8 `tlb:"## 8"`
+}
+
+type testDump struct {
+	_       Magic          `tlb:"#aa"`
+	Flag    bool           `tlb:"bool"`
+	Num     uint32         `tlb:"## 32"`
+	Inner   testDumpInner  `tlb:"^"`
+	Opt     *testDumpInner `tlb:"maybe ."`
+	Payload *cell.Cell     `tlb:"^"`
+}
+
+func TestDump(t *testing.T) {
+	v := testDump{
+		Flag:    true,
+		Num:     42,
+		Inner:   testDumpInner{Val: 7},
+		Payload: cell.BeginCell().MustStoreUInt(0xCAFE, 16).EndCell(),
+	}
+
+	got := Dump(&v)
+	want := "testDump = 42 bits, 2 refs {\n" +
+		"  _ `#aa`: 8[AA]\n" +
+		"  Flag `bool`: 1[8_] = true\n" +
+		"  Num `## 32`: 32[0000002A] = 42\n" +
+		"  Inner `^`: 0[] -> ^0 testDumpInner = 8 bits, 0 refs {\n" +
+		"    Val `## 8`: 8[07] = 7\n" +
+		"  }\n" +
+		"  Opt `maybe .`: 1[0_]\n" +
+		"  Payload `^`: 0[] -> ^1\n" +
+		"    ^1 = 16[CAFE]\n" +
+		"}\n"
+	if got != want {
+		t.Fatalf("incorrect dump:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got = Dump(struct{ A uint8 }{}); !strings.Contains(got, "error:") {
+		t.Fatal("untagged field should be reported as error", got)
+	}
+}
@@ -0,0 +1,171 @@
+package tlb
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// Equal - compares decoded values deeply, unlike reflect.DeepEqual cells, slices and dictionaries are compared by hash
+// of their data, big ints and coins by numeric value, addresses by their string and time by Equal.
+// Blank fields (like Magic), Marks fields and fields with 'cell' tag are not compared, because they are filled only on load.
+func Equal(a, b any) bool {
+	return Diff(a, b) == ""
+}
+
+// Diff - the same as Equal, but returns path of the first difference with short reason, like 'Msg.Body (cell hash)',
+// or empty string when values are equal
+func Diff(a, b any) string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		if va.IsValid() != vb.IsValid() {
+			return "(nil)"
+		}
+		return ""
+	}
+
+	typ := va.Type()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return diffValues(va, vb, typ.Name())
+}
+
+func diffValues(a, b reflect.Value, path string) string {
+	if a.Type() != b.Type() {
+		return fmt.Sprintf("%s (type %s != %s)", path, a.Type(), b.Type())
+	}
+
+	switch a.Type() {
+	case reflect.TypeOf(&cell.Cell{}), reflect.TypeOf(&cell.Slice{}), reflect.TypeOf(&cell.Dictionary{}),
+		reflect.TypeOf(&big.Int{}), reflect.TypeOf(&address.Address{}):
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return path + " (nil)"
+			}
+			return ""
+		}
+	}
+
+	switch a.Type() {
+	case reflect.TypeOf(&cell.Cell{}):
+		if !bytes.Equal(a.Interface().(*cell.Cell).Hash(), b.Interface().(*cell.Cell).Hash()) {
+			return path + " (cell hash)"
+		}
+		return ""
+	case reflect.TypeOf(&cell.Slice{}):
+		ca, errA := a.Interface().(*cell.Slice).Copy().ToCell()
+		cb, errB := b.Interface().(*cell.Slice).Copy().ToCell()
+		if errA != nil || errB != nil || !bytes.Equal(ca.Hash(), cb.Hash()) {
+			return path + " (slice data)"
+		}
+		return ""
+	case reflect.TypeOf(&cell.Dictionary{}):
+		ca, errA := a.Interface().(*cell.Dictionary).ToCell()
+		cb, errB := b.Interface().(*cell.Dictionary).ToCell()
+		if errA != nil || errB != nil || (ca == nil) != (cb == nil) || (ca != nil && !bytes.Equal(ca.Hash(), cb.Hash())) {
+			return path + " (dict data)"
+		}
+		return ""
+	case reflect.TypeOf(&big.Int{}):
+		if a.Interface().(*big.Int).Cmp(b.Interface().(*big.Int)) != 0 {
+			return fmt.Sprintf("%s (%v != %v)", path, a.Interface(), b.Interface())
+		}
+		return ""
+	case reflect.TypeOf(Coins{}):
+		if a.Interface().(Coins).Cmp(b.Interface().(Coins)) != 0 {
+			return fmt.Sprintf("%s (%v != %v)", path, a.Interface(), b.Interface())
+		}
+		return ""
+	case reflect.TypeOf(&address.Address{}):
+		if a.Interface().(*address.Address).String() != b.Interface().(*address.Address).String() {
+			return fmt.Sprintf("%s (%v != %v)", path, a.Interface(), b.Interface())
+		}
+		return ""
+	case reflect.TypeOf(time.Time{}):
+		if !a.Interface().(time.Time).Equal(b.Interface().(time.Time)) {
+			return fmt.Sprintf("%s (%v != %v)", path, a.Interface(), b.Interface())
+		}
+		return ""
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return path + " (nil)"
+			}
+			return ""
+		}
+		return diffValues(a.Elem(), b.Elem(), path)
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if field := a.Type().Field(i); !field.IsExported() && field.Name != "_" {
+				// unexported state is compared as a whole
+				return diffOpaque(a, b, path)
+			}
+		}
+
+		for i := 0; i < a.NumField(); i++ {
+			field := a.Type().Field(i)
+			if field.Name == "_" || field.Type == reflect.TypeOf(Marks{}) || field.Tag.Get("tlb") == "cell" {
+				continue
+			}
+
+			if d := diffValues(a.Field(i), b.Field(i), path+"."+field.Name); d != "" {
+				return d
+			}
+		}
+		return ""
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return fmt.Sprintf("%s (len %d != %d)", path, a.Len(), b.Len())
+		}
+
+		for i := 0; i < a.Len(); i++ {
+			if d := diffValues(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i)); d != "" {
+				return d
+			}
+		}
+		return ""
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return fmt.Sprintf("%s (len %d != %d)", path, a.Len(), b.Len())
+		}
+
+		iter := a.MapRange()
+		for iter.Next() {
+			bv := b.MapIndex(iter.Key())
+			if !bv.IsValid() {
+				return fmt.Sprintf("%s[%v] (missing)", path, iter.Key())
+			}
+
+			if d := diffValues(iter.Value(), bv, fmt.Sprintf("%s[%v]", path, iter.Key())); d != "" {
+				return d
+			}
+		}
+		return ""
+	}
+	return diffOpaque(a, b, path)
+}
+
+// diffOpaque - compares values with unexported state, using String when it is implemented
+func diffOpaque(a, b reflect.Value, path string) string {
+	ai, bi := a.Interface(), b.Interface()
+	if s, ok := ai.(fmt.Stringer); ok {
+		if s.String() != bi.(fmt.Stringer).String() {
+			return fmt.Sprintf("%s (%v != %v)", path, ai, bi)
+		}
+		return ""
+	}
+
+	if !reflect.DeepEqual(ai, bi) {
+		return fmt.Sprintf("%s (%v != %v)", path, ai, bi)
+	}
+	return ""
+}
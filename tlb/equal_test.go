@@ -0,0 +1,59 @@
+package tlb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testEqual struct {
+	_      Magic            `tlb:"#aa"`
+	Addr   *address.Address `tlb:"addr"`
+	Amount Coins            `tlb:"."`
+	Big    *big.Int         `tlb:"## 64"`
+	Body   *cell.Cell       `tlb:"^"`
+	Items  []uint8
+	Marks  Marks
+}
+
+func TestEqual(t *testing.T) {
+	addr := "EQC6KV4zs8TJtSZapOrRFmqSkxzpq-oSCoxekQRKElf4nC1I"
+
+	a := testEqual{
+		Addr:   address.MustParseAddr(addr),
+		Amount: MustFromTON("1"),
+		Big:    big.NewInt(100),
+		Body:   cell.BeginCell().MustStoreUInt(7, 8).EndCell(),
+		Items:  []uint8{1, 2},
+		Marks:  Marks{"x": {}},
+	}
+
+	b := testEqual{
+		Addr:   address.MustParseAddr(addr),
+		Amount: FromNanoTONU(1000000000),
+		Big:    new(big.Int).SetUint64(100),
+		Body:   cell.BeginCell().MustStoreUInt(7, 8).EndCell(),
+		Items:  []uint8{1, 2},
+	}
+
+	if !Equal(a, b) || !Equal(&a, &b) {
+		t.Fatal("values should be equal", Diff(a, b))
+	}
+
+	b.Body = cell.BeginCell().MustStoreUInt(8, 8).EndCell()
+	if d := Diff(a, b); d != "testEqual.Body (cell hash)" {
+		t.Fatal("incorrect diff", d)
+	}
+
+	b.Body = a.Body
+	b.Items = []uint8{1}
+	if d := Diff(&a, &b); d != "testEqual.Items (len 2 != 1)" {
+		t.Fatal("incorrect diff", d)
+	}
+
+	if Equal(a, nil) || !Equal(nil, nil) {
+		t.Fatal("incorrect nil comparison")
+	}
+}
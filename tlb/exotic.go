@@ -0,0 +1,68 @@
+package tlb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// ErrPrunedBranch - matches ExoticCellError of pruned branch, data of such cell is not available,
+// it happens when struct is loaded from proof which not includes it
+var ErrPrunedBranch = errors.New("cell is pruned branch")
+
+// exotic cell types, stored in the first byte of its data
+const (
+	exoticPrunedBranch = 1
+	exoticLibrary      = 2
+	exoticMerkleProof  = 3
+	exoticMerkleUpdate = 4
+)
+
+// ExoticCellError - returned when exotic cell, which cannot be loaded, is met in place of inner struct
+type ExoticCellError struct {
+	Type byte
+}
+
+func (e *ExoticCellError) Error() string {
+	switch e.Type {
+	case exoticPrunedBranch:
+		return "cell is pruned branch"
+	case exoticLibrary:
+		return "cell is library reference"
+	case exoticMerkleProof:
+		return "cell is merkle proof"
+	case exoticMerkleUpdate:
+		return "cell is merkle update"
+	}
+	return fmt.Sprintf("cell is exotic of unknown type %d", e.Type)
+}
+
+func (e *ExoticCellError) Is(target error) bool {
+	return target == ErrPrunedBranch && e.Type == exoticPrunedBranch
+}
+
+// checkExotic - returns cell to load inner struct from ref, unwraps merkle proof when it is allowed by options,
+// returns ExoticCellError for pruned branch, other exotic cells are returned as is, to be loaded by structs which describe them
+func checkExotic(ctx context.Context, ref *cell.Slice) (*cell.Slice, error) {
+	for ref.IsSpecial() {
+		typ, err := ref.Copy().LoadUInt(8)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load type of exotic cell: %w", err)
+		}
+
+		if typ == exoticPrunedBranch {
+			return nil, &ExoticCellError{Type: byte(typ)}
+		}
+
+		if typ != exoticMerkleProof || !optionsOf(ctx).UnwrapMerkleProofs {
+			break
+		}
+
+		if ref, err = ref.Copy().LoadRef(); err != nil {
+			return nil, fmt.Errorf("failed to load content of merkle proof: %w", err)
+		}
+	}
+	return ref, nil
+}
@@ -0,0 +1,73 @@
+package tlb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testExoticInner struct {
+	Val uint32 `tlb:"## 32"`
+}
+
+type testExoticOuter struct {
+	Inner testExoticInner `tlb:"^"`
+}
+
+func testBOC(cells ...[]byte) []byte {
+	var payload []byte
+	for _, c := range cells {
+		payload = append(payload, c...)
+	}
+
+	boc := []byte{0xB5, 0xEE, 0x9C, 0x72, 0x01, 0x01, byte(len(cells)), 0x01, 0x00, byte(len(payload)), 0x00}
+	return append(boc, payload...)
+}
+
+func TestLoadFromCellExotic(t *testing.T) {
+	proof := append([]byte{0x09, 70, 0x03}, bytes.Repeat([]byte{0}, 34)...)
+	proof = append(proof, 2)
+
+	c, err := cell.FromBOC(testBOC(
+		[]byte{0x01, 0x00, 0x01},
+		proof,
+		[]byte{0x00, 0x08, 0x00, 0x00, 0x00, 0x07},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without option proof cell itself is loaded
+	var x testExoticOuter
+	if err = LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Inner.Val != 0x03000000 {
+		t.Fatal("proof cell should be loaded as is")
+	}
+
+	err = LoadFromCellContext(WithOptions(context.Background(), Options{UnwrapMerkleProofs: true}), &x, c.BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Inner.Val != 7 {
+		t.Fatal("not eq")
+	}
+
+	pruned := append([]byte{0x28, 72, 0x01, 0x01}, bytes.Repeat([]byte{0}, 34)...)
+	c, err = cell.FromBOC(testBOC([]byte{0x01, 0x00, 0x01}, pruned))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = LoadFromCellContext(WithOptions(context.Background(), Options{UnwrapMerkleProofs: true}), &x, c.BeginParse())
+	var exotic *ExoticCellError
+	if !errors.Is(err, ErrPrunedBranch) || !errors.As(err, &exotic) || exotic.Type != exoticPrunedBranch {
+		t.Fatal("should fail with pruned error", err)
+	}
+}
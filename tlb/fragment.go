@@ -0,0 +1,63 @@
+package tlb
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var fragments = struct {
+	mx   sync.RWMutex
+	tags map[string]string
+}{
+	tags: map[string]string{},
+}
+
+// RegisterFragment - registers named tag, which can be referenced in struct tags as 'use:Name',
+// for example after RegisterFragment("QueryID", "## 64") tag `tlb:"use:QueryID"` is the same as `tlb:"## 64"`.
+// Fragments can be combined with other settings, like `tlb:"maybe use:QueryID"`
+func RegisterFragment(name, tag string) {
+	if name == "" || strings.ContainsAny(name, " :") {
+		panic("invalid fragment name")
+	}
+
+	fragments.mx.Lock()
+	defer fragments.mx.Unlock()
+
+	fragments.tags[name] = strings.TrimSpace(tag)
+	resetPlans()
+}
+
+// fieldTag - returns tlb tag of the field with expanded fragments,
+// embedded struct (or pointer to exported struct) without tag is treated as inline '.'
+func fieldTag(field reflect.StructField) string {
+	tag := strings.TrimSpace(field.Tag.Get("tlb"))
+	if tag == "" && field.Anonymous && (field.Type.Kind() == reflect.Struct ||
+		(field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct && field.IsExported())) {
+		return "."
+	}
+
+	if !strings.Contains(tag, "use:") {
+		return tag
+	}
+
+	fragments.mx.RLock()
+	defer fragments.mx.RUnlock()
+
+	var res []string
+	for _, s := range strings.Split(tag, " ") {
+		if strings.HasPrefix(s, "use:") {
+			frag, ok := fragments.tags[s[4:]]
+			if !ok {
+				// we panic, because its developer's issue, need to register fragment
+				panic(fmt.Sprintf("fragment '%s' of field '%s' is not registered", s[4:], field.Name))
+			}
+			res = append(res, frag)
+			continue
+		}
+		res = append(res, s)
+	}
+
+	return strings.Join(res, " ")
+}
@@ -0,0 +1,568 @@
+package tlb
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+var (
+	jsonCellType  = reflect.TypeOf(&cell.Cell{})
+	jsonSliceType = reflect.TypeOf(&cell.Slice{})
+	jsonDictType  = reflect.TypeOf(&cell.Dictionary{})
+	jsonBigType   = reflect.TypeOf(&big.Int{})
+	jsonAddrType  = reflect.TypeOf(&address.Address{})
+	jsonTimeType  = reflect.TypeOf(time.Time{})
+	jsonBytesType = reflect.TypeOf([]byte{})
+)
+
+// jsonTypeKey - key of registered type name in JSON object of union value
+const jsonTypeKey = "@type"
+
+// MarshalJSON - converts decoded struct to canonical JSON: addresses are user-friendly strings (empty for addr_none),
+// big ints and coins are decimal strings, cells and slices are base64 BOC, []byte is base64,
+// *cell.Dictionary is object of hex keys and base64 BOC values, maps are objects, time is RFC 3339,
+// union values are objects with registered name of the type in '@type' key.
+// Field names are taken from json tags when they are set, blank fields are omitted. Named Magic fields are strings
+// with matched constructor of the tag, like '#02', or null when constructor is not set.
+func MarshalJSON(v any) ([]byte, error) {
+	x, err := jsonValue(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(x)
+}
+
+// UnmarshalJSON - reverse of MarshalJSON, v should be a pointer,
+// size of *cell.Dictionary keys is taken from 'dict N' tag of the field
+func UnmarshalJSON(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("v should be a pointer and not nil")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var x any
+	if err := dec.Decode(&x); err != nil {
+		return fmt.Errorf("failed to decode json: %w", err)
+	}
+	return jsonFill(rv.Elem(), x, nil)
+}
+
+func jsonValue(rv reflect.Value) (any, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+	}
+
+	switch rv.Type() {
+	case jsonCellType:
+		return base64.StdEncoding.EncodeToString(rv.Interface().(*cell.Cell).ToBOC()), nil
+	case jsonSliceType:
+		c, err := rv.Interface().(*cell.Slice).Copy().ToCell()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert slice to cell: %w", err)
+		}
+		return base64.StdEncoding.EncodeToString(c.ToBOC()), nil
+	case jsonDictType:
+		res := map[string]any{}
+		for _, kv := range rv.Interface().(*cell.Dictionary).All() {
+			key, err := kv.Key.BeginParse().LoadBigUInt(kv.Key.BitsSize())
+			if err != nil {
+				return nil, fmt.Errorf("failed to load dict key: %w", err)
+			}
+			res[key.Text(16)] = base64.StdEncoding.EncodeToString(kv.Value.ToBOC())
+		}
+		return res, nil
+	case jsonBigType:
+		return rv.Interface().(*big.Int).String(), nil
+	case jsonAddrType:
+		addr := rv.Interface().(*address.Address)
+		switch addr.Type() {
+		case address.NoneAddress:
+			return "", nil
+		case address.StdAddress:
+			return addr.String(), nil
+		}
+		return nil, fmt.Errorf("address of type %d cannot be represented in json", addr.Type())
+	case jsonTimeType, jsonBytesType:
+		return rv.Interface(), nil
+	}
+
+	if m, ok := rv.Interface().(json.Marshaler); ok {
+		return m, nil
+	}
+
+	if m, ok := rv.Interface().(encoding.TextMarshaler); ok {
+		return m, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		return jsonValue(rv.Elem())
+	case reflect.Interface:
+		x, err := jsonValue(rv.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		obj, ok := x.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("union value of type %s is not a struct", rv.Elem().Type())
+		}
+
+		typ := rv.Elem().Type()
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		name, ok := registeredName(typ)
+		if !ok {
+			return nil, fmt.Errorf("type %s of union value is not registered", typ)
+		}
+		obj[jsonTypeKey] = name
+		return obj, nil
+	case reflect.Struct:
+		res := map[string]any{}
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			name, ok := jsonFieldName(field)
+			if !ok {
+				continue
+			}
+
+			if field.Type == reflect.TypeOf(Magic{}) {
+				x, err := jsonMagic(rv.Field(i).Interface().(Magic), field)
+				if err != nil {
+					return nil, err
+				}
+				res[name] = x
+				continue
+			}
+
+			x, err := jsonValue(rv.Field(i))
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert %s: %w", field.Name, err)
+			}
+			res[name] = x
+		}
+		return res, nil
+	case reflect.Slice, reflect.Array:
+		res := make([]any, rv.Len())
+		for i := range res {
+			x, err := jsonValue(rv.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert element %d: %w", i, err)
+			}
+			res[i] = x
+		}
+		return res, nil
+	case reflect.Map:
+		res := map[string]any{}
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, err := jsonKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+
+			x, err := jsonValue(iter.Value())
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert value of %s: %w", key, err)
+			}
+			res[key] = x
+		}
+		return res, nil
+	}
+	return rv.Interface(), nil
+}
+
+func jsonKey(key reflect.Value) (string, error) {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+
+	x, err := jsonValue(key)
+	if err != nil {
+		return "", err
+	}
+
+	str, ok := x.(string)
+	if !ok {
+		return "", fmt.Errorf("map key of type %s cannot be represented in json", key.Type())
+	}
+	return str, nil
+}
+
+// jsonFieldName - returns name of the field in JSON object, false when field is omitted
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	}
+	return name, true
+}
+
+// jsonFill - sets value parsed from JSON to rv, field is struct field of value, if it is known
+func jsonFill(rv reflect.Value, x any, field *reflect.StructField) error {
+	if x == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	str, isStr := x.(string)
+	switch rv.Type() {
+	case jsonCellType, jsonSliceType:
+		if !isStr {
+			return fmt.Errorf("cell should be base64 string")
+		}
+
+		c, err := cellFromBase64(str)
+		if err != nil {
+			return err
+		}
+
+		if rv.Type() == jsonSliceType {
+			rv.Set(reflect.ValueOf(c.BeginParse()))
+			return nil
+		}
+		rv.Set(reflect.ValueOf(c))
+		return nil
+	case jsonDictType:
+		return jsonFillDict(rv, x, field)
+	case jsonBigType:
+		v, ok := jsonBigInt(x)
+		if !ok {
+			return fmt.Errorf("incorrect big int value %v", x)
+		}
+		rv.Set(reflect.ValueOf(v))
+		return nil
+	case jsonAddrType:
+		if !isStr {
+			return fmt.Errorf("address should be string")
+		}
+
+		addr, err := addrFromString(str)
+		if err != nil {
+			return fmt.Errorf("failed to parse address: %w", err)
+		}
+		rv.Set(reflect.ValueOf(addr))
+		return nil
+	case jsonBytesType:
+		if !isStr {
+			return fmt.Errorf("bytes should be base64 string")
+		}
+
+		data, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			return fmt.Errorf("failed to decode base64: %w", err)
+		}
+		rv.SetBytes(data)
+		return nil
+	case jsonTimeType:
+		if !isStr {
+			return fmt.Errorf("time should be string")
+		}
+
+		tm, err := time.Parse(time.RFC3339, str)
+		if err != nil {
+			return fmt.Errorf("failed to parse time: %w", err)
+		}
+		rv.Set(reflect.ValueOf(tm))
+		return nil
+	case reflect.TypeOf(Magic{}):
+		if !isStr || field == nil {
+			return fmt.Errorf("magic should be string of constructor from tag")
+		}
+		return jsonFillMagic(rv, str, *field)
+	}
+
+	if rv.CanAddr() {
+		if u, ok := rv.Addr().Interface().(json.Unmarshaler); ok {
+			data, err := json.Marshal(x)
+			if err != nil {
+				return err
+			}
+			return u.UnmarshalJSON(data)
+		}
+
+		if u, ok := rv.Addr().Interface().(encoding.TextUnmarshaler); ok && isStr {
+			return u.UnmarshalText([]byte(str))
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		val := reflect.New(rv.Type().Elem())
+		if err := jsonFill(val.Elem(), x, field); err != nil {
+			return err
+		}
+		rv.Set(val)
+		return nil
+	case reflect.Interface:
+		obj, ok := x.(map[string]any)
+		if !ok {
+			return fmt.Errorf("union value should be object")
+		}
+
+		name, ok := obj[jsonTypeKey].(string)
+		if !ok {
+			return fmt.Errorf("union value should have %s", jsonTypeKey)
+		}
+
+		typ, ok := lookupRegistered(name)
+		if !ok {
+			return fmt.Errorf("type '%s' is not registered", name)
+		}
+
+		val := reflect.New(typ)
+		if err := jsonFill(val.Elem(), x, nil); err != nil {
+			return err
+		}
+
+		if !val.Type().Implements(rv.Type()) {
+			val = val.Elem()
+		}
+
+		if !val.Type().Implements(rv.Type()) {
+			return fmt.Errorf("type '%s' not implements %s", name, rv.Type())
+		}
+		rv.Set(val)
+		return nil
+	case reflect.Struct:
+		obj, ok := x.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s should be object", rv.Type())
+		}
+
+		for i := 0; i < rv.NumField(); i++ {
+			f := rv.Type().Field(i)
+			name, ok := jsonFieldName(f)
+			if !ok {
+				continue
+			}
+
+			fx, ok := obj[name]
+			if !ok {
+				continue
+			}
+
+			if err := jsonFill(rv.Field(i), fx, &f); err != nil {
+				return fmt.Errorf("failed to fill %s: %w", f.Name, err)
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		arr, ok := x.([]any)
+		if !ok {
+			return fmt.Errorf("%s should be array", rv.Type())
+		}
+
+		if rv.Kind() == reflect.Slice {
+			rv.Set(reflect.MakeSlice(rv.Type(), len(arr), len(arr)))
+		} else if rv.Len() != len(arr) {
+			return fmt.Errorf("%s should have %d elements", rv.Type(), rv.Len())
+		}
+
+		for i, ex := range arr {
+			if err := jsonFill(rv.Index(i), ex, nil); err != nil {
+				return fmt.Errorf("failed to fill element %d: %w", i, err)
+			}
+		}
+		return nil
+	case reflect.Map:
+		obj, ok := x.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s should be object", rv.Type())
+		}
+
+		mp := reflect.MakeMapWithSize(rv.Type(), len(obj))
+		for k, vx := range obj {
+			key := reflect.New(rv.Type().Key()).Elem()
+			if err := jsonFillKey(key, k); err != nil {
+				return err
+			}
+
+			val := reflect.New(rv.Type().Elem()).Elem()
+			if err := jsonFill(val, vx, nil); err != nil {
+				return fmt.Errorf("failed to fill value of %s: %w", k, err)
+			}
+			mp.SetMapIndex(key, val)
+		}
+		rv.Set(mp)
+		return nil
+	case reflect.Bool:
+		b, ok := x.(bool)
+		if !ok {
+			return fmt.Errorf("%s should be bool", rv.Type())
+		}
+		rv.SetBool(b)
+		return nil
+	case reflect.String:
+		if !isStr {
+			return fmt.Errorf("%s should be string", rv.Type())
+		}
+		rv.SetString(str)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(fmt.Sprint(x), 10, rv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("incorrect int value %v: %w", x, err)
+		}
+		rv.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(fmt.Sprint(x), 10, rv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("incorrect uint value %v: %w", x, err)
+		}
+		rv.SetUint(n)
+		return nil
+	}
+	return fmt.Errorf("type %s cannot be filled from json", rv.Type())
+}
+
+func jsonFillKey(key reflect.Value, k string) error {
+	switch key.Kind() {
+	case reflect.String:
+		key.SetString(k)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonFill(key, json.Number(k), nil)
+	}
+	return jsonFill(key, k, nil)
+}
+
+// jsonFillDict - fills *cell.Dictionary from object of hex keys and base64 BOC values, key size is taken from tag
+func jsonFillDict(rv reflect.Value, x any, field *reflect.StructField) error {
+	obj, ok := x.(map[string]any)
+	if !ok {
+		return fmt.Errorf("dict should be object")
+	}
+
+	var sz uint64
+	if field != nil {
+		settings := strings.Fields(fieldTag(*field))
+		for i, s := range settings {
+			if s == "dict" && i+1 < len(settings) {
+				sz, _ = strconv.ParseUint(settings[i+1], 10, 64)
+				break
+			}
+		}
+	}
+
+	if sz == 0 {
+		return fmt.Errorf("key size of dict is unknown, field should have 'dict N' tag")
+	}
+
+	dict := cell.NewDict(uint(sz))
+	for k, vx := range obj {
+		key, ok := new(big.Int).SetString(k, 16)
+		if !ok {
+			return fmt.Errorf("incorrect dict key %s", k)
+		}
+
+		str, ok := vx.(string)
+		if !ok {
+			return fmt.Errorf("dict value of %s should be base64 string", k)
+		}
+
+		val, err := cellFromBase64(str)
+		if err != nil {
+			return err
+		}
+
+		keyCell := cell.BeginCell()
+		if err = keyCell.StoreBigUInt(key, uint(sz)); err != nil {
+			return fmt.Errorf("failed to store dict key %s: %w", k, err)
+		}
+
+		if err = dict.Set(keyCell.EndCell(), val); err != nil {
+			return fmt.Errorf("failed to set dict value of %s: %w", k, err)
+		}
+	}
+	rv.Set(reflect.ValueOf(dict))
+	return nil
+}
+
+// jsonMagic - returns alternative of magic tag matched by value of the field, nil when it is not set
+func jsonMagic(m Magic, field reflect.StructField) (any, error) {
+	if m.bits == nil {
+		return nil, nil
+	}
+
+	alts, _ := magicAlternatives(fieldTag(field))
+	for _, alt := range alts {
+		if parseMagic(alt).equal(*m.bits) {
+			return alt, nil
+		}
+	}
+	return nil, fmt.Errorf("magic %s of %s is not accepted by its tag", m.bits, field.Name)
+}
+
+// jsonFillMagic - sets magic field to alternative of its tag, reverse of jsonMagic
+func jsonFillMagic(rv reflect.Value, str string, field reflect.StructField) error {
+	alts, _ := magicAlternatives(fieldTag(field))
+	for _, alt := range alts {
+		if alt == str {
+			bits := parseMagic(alt)
+			rv.Set(reflect.ValueOf(Magic{bits: &bits}))
+			return nil
+		}
+	}
+	return fmt.Errorf("magic '%s' is not one of alternatives %v", str, alts)
+}
+
+func jsonBigInt(x any) (*big.Int, bool) {
+	switch v := x.(type) {
+	case string:
+		return new(big.Int).SetString(v, 10)
+	case json.Number:
+		return new(big.Int).SetString(v.String(), 10)
+	}
+	return nil, false
+}
+
+func cellFromBase64(str string) (*cell.Cell, error) {
+	data, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
+	}
+
+	c, err := cell.FromBOC(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse boc: %w", err)
+	}
+	return c, nil
+}
@@ -0,0 +1,184 @@
+package tlb
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testJSON struct {
+	_       Magic            `tlb:"#5a"`
+	Addr    *address.Address `tlb:"addr" json:"addr"`
+	None    *address.Address `tlb:"addr"`
+	Amount  Coins            `tlb:"."`
+	Big     *big.Int         `tlb:"## 128"`
+	Hash    Bits256          `tlb:"hash"`
+	Flag    bool             `tlb:"bool"`
+	Payload *cell.Cell       `tlb:"^"`
+	Dict    *cell.Dictionary `tlb:"dict 16"`
+	Union   testUnionAny     `tlb:"^ union TestUnionA TestUnionB"`
+	Skipped uint8            `tlb:"## 8" json:"-"`
+}
+
+func TestMarshalJSON(t *testing.T) {
+	Register("TestUnionA", testUnionA{})
+	Register("TestUnionB", testUnionB{})
+
+	dict := cell.NewDict(16)
+	if err := dict.SetIntKey(big.NewInt(0x1f), cell.BeginCell().MustStoreUInt(7, 8).EndCell()); err != nil {
+		t.Fatal(err)
+	}
+
+	v := testJSON{
+		Addr:    address.MustParseAddr("EQC6KV4zs8TJtSZapOrRFmqSkxzpq-oSCoxekQRKElf4nC1I"),
+		None:    address.NewAddressNone(),
+		Amount:  MustFromTON("1.5"),
+		Big:     new(big.Int).Lsh(big.NewInt(1), 100),
+		Hash:    Bits256{0xAB},
+		Flag:    true,
+		Payload: cell.BeginCell().MustStoreUInt(0xCAFE, 16).EndCell(),
+		Dict:    dict,
+		Union:   &testUnionB{Val: 123456789012},
+		Skipped: 3,
+	}
+
+	c, err := ToCell(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded testJSON
+	if err = LoadFromCell(&loaded, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := MarshalJSON(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var obj map[string]any
+	if err = json.Unmarshal(data, &obj); err != nil {
+		t.Fatal(err)
+	}
+
+	if obj["addr"] != "EQC6KV4zs8TJtSZapOrRFmqSkxzpq-oSCoxekQRKElf4nC1I" || obj["None"] != "" {
+		t.Fatal("incorrect addresses", string(data))
+	}
+
+	if obj["Amount"] != "1500000000" || obj["Big"] != "1267650600228229401496703205376" || obj["Flag"] != true {
+		t.Fatal("incorrect numbers", string(data))
+	}
+
+	if obj["Hash"] != (Bits256{0xAB}).String() {
+		t.Fatal("incorrect hash", string(data))
+	}
+
+	if _, ok := obj["Skipped"]; ok {
+		t.Fatal("field with json:\"-\" should be omitted")
+	}
+
+	if _, ok := obj["_"]; ok {
+		t.Fatal("magic should be omitted")
+	}
+
+	if d, ok := obj["Dict"].(map[string]any); !ok || d["1f"] == nil {
+		t.Fatal("incorrect dict", string(data))
+	}
+
+	if u, ok := obj["Union"].(map[string]any); !ok || u["@type"] != "TestUnionB" || u["Val"] != float64(123456789012) {
+		t.Fatal("incorrect union", string(data))
+	}
+
+	var back testJSON
+	if err = UnmarshalJSON(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	back.Skipped = 3
+
+	c2, err := ToCell(back)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), c2.Hash()) {
+		t.Fatal("cell not same after json round trip")
+	}
+
+	data2, err := MarshalJSON(&back)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, data2) {
+		t.Fatal("json not same after round trip", string(data), string(data2))
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	var v testJSON
+	if err := UnmarshalJSON([]byte(`{"Big":"abc"}`), &v); err == nil {
+		t.Fatal("should be error for incorrect big int")
+	}
+
+	if err := UnmarshalJSON([]byte(`{"Union":{"Val":1}}`), &v); err == nil {
+		t.Fatal("should be error for union without type")
+	}
+
+	if err := UnmarshalJSON([]byte(`{}`), v); err == nil {
+		t.Fatal("should be error for non pointer")
+	}
+
+	if _, err := MarshalJSON(struct{ A *address.Address }{address.NewAddressExt(0, 8, []byte{1})}); err == nil {
+		t.Fatal("should be error for ext address")
+	}
+}
+
+type testJSONMagic struct {
+	Op  Magic  `tlb:"#01|#02"`
+	Val uint16 `tlb:"## 16"`
+}
+
+func TestMarshalJSONMagic(t *testing.T) {
+	c := cell.BeginCell().MustStoreUInt(0x02, 8).MustStoreUInt(7, 16).EndCell()
+
+	var x testJSONMagic
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := MarshalJSON(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != `{"Op":"#02","Val":7}` {
+		t.Fatal("incorrect json", string(data))
+	}
+
+	var y testJSONMagic
+	if err = UnmarshalJSON(data, &y); err != nil {
+		t.Fatal(err)
+	}
+
+	c2, err := ToCell(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), c2.Hash()) {
+		t.Fatal("magic not same after json round trip")
+	}
+
+	if data, err = MarshalJSON(testJSONMagic{}); err != nil || string(data) != `{"Op":null,"Val":0}` {
+		t.Fatal("zero magic should be null", string(data), err)
+	}
+
+	if err = UnmarshalJSON([]byte(`{"Op":"#03"}`), &y); err == nil {
+		t.Fatal("should fail on magic not from tag")
+	}
+}
@@ -0,0 +1,80 @@
+package tlb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// Lazy - value of T which is decoded only on first call of Get, on load its cell is kept as is.
+// Useful for big structs, like blocks, when only a few fields are needed, for example:
+//
+//	Extra tlb.Lazy[BlockExtra] `tlb:"^"`
+//
+// It should be loaded from ref using '^', or be the last field, because all the rest data of loader is captured.
+// Copies of Lazy share decoded value, it is safe to call Get concurrently.
+type Lazy[T any] struct {
+	state *lazyState[T]
+}
+
+type lazyState[T any] struct {
+	cell *cell.Cell
+
+	once sync.Once
+	val  T
+	err  error
+}
+
+// NewLazy - returns Lazy with already known value, it is serialized on store
+func NewLazy[T any](v T) Lazy[T] {
+	st := &lazyState[T]{val: v}
+	st.once.Do(func() {})
+	return Lazy[T]{state: st}
+}
+
+// Get - decodes value on first call using Load, and returns it, next calls return the same value and error
+func (l Lazy[T]) Get() (T, error) {
+	if l.state == nil {
+		var zero T
+		return zero, errors.New("lazy value is not loaded")
+	}
+
+	l.state.once.Do(func() {
+		l.state.val, l.state.err = Load[T](l.state.cell.BeginParse())
+		if l.state.err != nil {
+			l.state.err = fmt.Errorf("failed to decode lazy value: %w", l.state.err)
+		}
+	})
+	return l.state.val, l.state.err
+}
+
+// Cell - returns undecoded cell of the value, nil when Lazy is created using NewLazy
+func (l Lazy[T]) Cell() *cell.Cell {
+	if l.state == nil {
+		return nil
+	}
+	return l.state.cell
+}
+
+// UnmarshalTLB - captures all the rest data of loader without decoding
+func (l *Lazy[T]) UnmarshalTLB(loader *cell.Slice) error {
+	c, err := loadRemaining(loader)
+	if err != nil {
+		return fmt.Errorf("failed to capture lazy value: %w", err)
+	}
+	l.state = &lazyState[T]{cell: c}
+	return nil
+}
+
+// MarshalTLB - returns loaded cell as is, or serializes value of NewLazy
+func (l Lazy[T]) MarshalTLB() (*cell.Cell, error) {
+	switch {
+	case l.state == nil:
+		return nil, errors.New("lazy value is not set")
+	case l.state.cell != nil:
+		return l.state.cell, nil
+	}
+	return Store(l.state.val)
+}
@@ -0,0 +1,69 @@
+package tlb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testLazy struct {
+	Val   uint8                 `tlb:"## 8"`
+	Inner Lazy[testStrictInner] `tlb:"^"`
+}
+
+func TestLazy(t *testing.T) {
+	root := cell.BeginCell().MustStoreUInt(1, 8).
+		MustStoreRef(cell.BeginCell().MustStoreUInt(77, 32).EndCell()).EndCell()
+
+	var v testLazy
+	if err := LoadFromCell(&v, root.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Inner.Cell() == nil || v.Inner.Cell().BitsSize() != 32 {
+		t.Fatal("cell of lazy value should be kept")
+	}
+
+	inner, err := v.Inner.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if inner.Val != 77 {
+		t.Fatal("incorrect lazy value", inner.Val)
+	}
+
+	c, err := ToCell(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), root.Hash()) {
+		t.Fatal("cell not same after load and store")
+	}
+
+	c, err = ToCell(testLazy{Val: 1, Inner: NewLazy(testStrictInner{Val: 77})})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), root.Hash()) {
+		t.Fatal("cell of new lazy value not same")
+	}
+
+	root = cell.BeginCell().MustStoreUInt(1, 8).
+		MustStoreRef(cell.BeginCell().MustStoreUInt(77, 8).EndCell()).EndCell()
+
+	if err = LoadFromCell(&v, root.BeginParse()); err != nil {
+		t.Fatal("lazy value should not be decoded on load", err)
+	}
+
+	if _, err = v.Inner.Get(); err == nil {
+		t.Fatal("should be error on decode of truncated value")
+	}
+
+	if _, err = (Lazy[testStrictInner]{}).Get(); err == nil {
+		t.Fatal("should be error for not loaded value")
+	}
+}
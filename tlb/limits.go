@@ -0,0 +1,130 @@
+package tlb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited - returned by LoadAny and its variants when decodes of matched registered type
+// exceed Limits.MaxPerSecond
+var ErrRateLimited = errors.New("decode rate limit of type is reached")
+
+// ErrTypeDisabled - returned by LoadAny and its variants when matched registered type was disabled
+// after Limits.MaxFailures failed decodes in a row, it can be enabled again using EnableType
+var ErrTypeDisabled = errors.New("type is disabled")
+
+// Limits - limits of decoding of registered type by LoadAny and its variants, zero value means no limit.
+// Protects shared decoding service from schema of one contract flooding the chain with bad data
+type Limits struct {
+	// MaxPerSecond - max number of decodes of the type per second
+	MaxPerSecond int
+	// MaxFailures - number of failed decodes in a row, after which the type is disabled
+	MaxFailures int
+}
+
+type typeLimiter struct {
+	limits Limits
+
+	windowStart time.Time
+	decodes     int
+	failures    int
+	disabled    bool
+}
+
+var limiters = struct {
+	mx    sync.Mutex
+	types map[string]*typeLimiter
+}{
+	types: map[string]*typeLimiter{},
+}
+
+// SetLimits - sets limits of decoding of type registered under name, counters are kept when limits are changed,
+// zero Limits removes limits and state of the type
+func SetLimits(name string, limits Limits) {
+	limiters.mx.Lock()
+	defer limiters.mx.Unlock()
+
+	if limits == (Limits{}) {
+		delete(limiters.types, name)
+		return
+	}
+
+	l := limiters.types[name]
+	if l == nil {
+		l = &typeLimiter{}
+		limiters.types[name] = l
+	}
+	l.limits = limits
+}
+
+// EnableType - enables type which was disabled after Limits.MaxFailures failed decodes, and resets its failures
+func EnableType(name string) {
+	limiters.mx.Lock()
+	defer limiters.mx.Unlock()
+
+	if l := limiters.types[name]; l != nil {
+		l.disabled = false
+		l.failures = 0
+	}
+}
+
+// IsTypeDisabled - checks that type registered under name was disabled after Limits.MaxFailures failed decodes
+func IsTypeDisabled(name string) bool {
+	limiters.mx.Lock()
+	defer limiters.mx.Unlock()
+
+	l := limiters.types[name]
+	return l != nil && l.disabled
+}
+
+// acquireDecode - checks limits of type before it is decoded, and counts decode
+func acquireDecode(name string) error {
+	limiters.mx.Lock()
+	defer limiters.mx.Unlock()
+
+	l := limiters.types[name]
+	if l == nil {
+		return nil
+	}
+
+	if l.disabled {
+		return fmt.Errorf("%w: %s", ErrTypeDisabled, name)
+	}
+
+	if l.limits.MaxPerSecond > 0 {
+		now := time.Now()
+		if now.Sub(l.windowStart) >= time.Second {
+			l.windowStart = now
+			l.decodes = 0
+		}
+
+		if l.decodes >= l.limits.MaxPerSecond {
+			return fmt.Errorf("%w: %s", ErrRateLimited, name)
+		}
+		l.decodes++
+	}
+	return nil
+}
+
+// recordDecode - records result of decode of type, disables it when failures limit is reached
+func recordDecode(name string, err error) {
+	limiters.mx.Lock()
+	defer limiters.mx.Unlock()
+
+	l := limiters.types[name]
+	if l == nil {
+		return
+	}
+
+	if err == nil {
+		l.failures = 0
+		return
+	}
+
+	l.failures++
+	if l.limits.MaxFailures > 0 && l.failures >= l.limits.MaxFailures {
+		l.disabled = true
+	}
+}
@@ -0,0 +1,61 @@
+package tlb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testLimited struct {
+	_   Magic  `tlb:"#6c1d"`
+	Val uint32 `tlb:"## 32"`
+}
+
+func TestLoadAnyLimits(t *testing.T) {
+	Register("TestLimited", testLimited{})
+	defer SetLimits("TestLimited", Limits{})
+
+	good := cell.BeginCell().MustStoreUInt(0x6c1d, 16).MustStoreUInt(5, 32).EndCell()
+	bad := cell.BeginCell().MustStoreUInt(0x6c1d, 16).MustStoreUInt(5, 8).EndCell()
+
+	SetLimits("TestLimited", Limits{MaxPerSecond: 2})
+	for i := 0; i < 2; i++ {
+		if _, err := LoadAny(good.BeginParse()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := LoadAny(good.BeginParse()); !errors.Is(err, ErrRateLimited) {
+		t.Fatal("should fail with rate limit error", err)
+	}
+
+	SetLimits("TestLimited", Limits{MaxFailures: 2})
+	if _, err := LoadAny(bad.BeginParse()); err == nil || errors.Is(err, ErrTypeDisabled) {
+		t.Fatal("should fail to decode", err)
+	}
+
+	// success resets failures in a row
+	if _, err := LoadAny(good.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := LoadAny(bad.BeginParse()); err == nil || errors.Is(err, ErrTypeDisabled) {
+			t.Fatal("should fail to decode", err)
+		}
+	}
+
+	if !IsTypeDisabled("TestLimited") {
+		t.Fatal("type should be disabled")
+	}
+
+	if _, err := LoadAny(good.BeginParse()); !errors.Is(err, ErrTypeDisabled) {
+		t.Fatal("should fail with disabled type error", err)
+	}
+
+	EnableType("TestLimited")
+	if _, err := LoadAny(good.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+}
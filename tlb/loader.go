@@ -1,503 +1,2584 @@
 package tlb
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/xssnick/tonutils-go/address"
 	"github.com/xssnick/tonutils-go/tvm/cell"
 )
 
-type Magic struct{}
+// Magic - constructor prefix of the struct, declared in its tag. When Magic field is named (not '_'),
+// matched constructor is kept in it on load, and on store it is used to choose one of alternatives of the tag,
+// if it is not accepted by the tag, error is returned. Zero Magic is stored as declared in tag.
+type Magic struct {
+	bits *magicBits
+}
+
+// Region - position of the loaded field inside the cell it was loaded from
+type Region struct {
+	BitsOffset uint
+	Bits       uint
+	RefsOffset int
+	Refs       int
+}
+
+// DictEntry - key and value of dictionary entry, slice of it can be used
+// with 'dict N -> array' transformation to not lose keys
+type DictEntry[K, V any] struct {
+	Key   K
+	Value V
+}
+
+func (DictEntry[K, V]) isDictEntry() {}
+
+var dictEntryType = reflect.TypeOf((*interface{ isDictEntry() })(nil)).Elem()
+
+// Marks - regions of fields tagged with 'mark:name', field of this type
+// in the struct is filled on load, it should be tagged with '-'
+type Marks map[string]Region
 
+// Unmarshaler - can be implemented by type to load it from cell manually, instead of using struct tags,
+// it is used when type is loaded as inner struct, dict value, union member or using Load
+type Unmarshaler interface {
+	UnmarshalTLB(loader *cell.Slice) error
+}
+
+// Marshaler - can be implemented by type to store it to cell manually, instead of using struct tags,
+// it is used when type is stored as inner struct, dict value, union member or using Store
+type Marshaler interface {
+	MarshalTLB() (*cell.Cell, error)
+}
+
+// manualLoader - the same as Unmarshaler, kept for types which implement LoadFromCell
 type manualLoader interface {
 	LoadFromCell(loader *cell.Slice) error
 }
 
+// manualStore - the same as Marshaler, kept for types which implement ToCell
 type manualStore interface {
 	ToCell() (*cell.Cell, error)
 }
 
+// asUnmarshaler - returns manual loader of value, if its type implements one
+func asUnmarshaler(inf any) (func(loader *cell.Slice) error, bool) {
+	switch ld := inf.(type) {
+	case Unmarshaler:
+		return ld.UnmarshalTLB, true
+	case manualLoader:
+		return ld.LoadFromCell, true
+	}
+	return nil, false
+}
+
+// asMarshaler - returns manual storer of value, if its type implements one
+func asMarshaler(inf any) (func() (*cell.Cell, error), bool) {
+	switch ld := inf.(type) {
+	case Marshaler:
+		return ld.MarshalTLB, true
+	case manualStore:
+		return ld.ToCell, true
+	}
+	return nil, false
+}
+
 // LoadFromCell automatically parses cell based on struct tags
 // ## N - means integer with N bits, if size <= 64 it loads to uint of any size, if > 64 it loads to *big.Int
+// uN, iN, bN - shorthands for '## N' and 'bits N', for example u32, i64 or b256, sign is defined by type of the field, as for ##
 // ^ - loads ref and calls recursively, if field type is *cell.Cell, it loads without parsing
-// . - calls recursively to continue load from current loader (inner struct)
+// . - calls recursively to continue load from current loader (inner struct), embedded structs without tag are loaded this way too
+// maybe . - inline inner struct pointer, which is nil when maybe bit is 0, on store nil is written as 0 bit
 // [^]dict N [-> array [^]] - loads dictionary with key size N, transformation '->' can be applied to convert dict to array, example: 'dict 256 -> array ^' will give you array of deserialized refs (^) of values
-// bits N - loads bit slice N len to []byte
+// dict N -> map [^] - converts dict to map[K]T, K can be integer (N <= 64), *big.Int, *address.Address or string (hex of key bits)
+// dict 267 -> map addr [^] - converts dict keyed by addresses to map[string]T with user-friendly address keys
+// dict 256 -> map sha256[:name1,name2] [^] - converts dict keyed by sha256 of string to map[string]T, keys are hashed on store,
+// listed names are resolved on load, unknown hashes are presented as '#' + hex of hash
+// dict N -> array [^] into []DictEntry[K, T] keeps keys too, maps and slices of DictEntry are also supported on store
+// chunked - loads TEP-64 chunked_data (dict 32 of ^SnakeData chunks) to []byte, on store data is split to chunks of 127 bytes
+// pfxdict N - loads prefix dictionary (PfxHashmapE) with max key size N to *cell.PrefixDictionary
+// dictaug N - loads augmented dictionary (HashmapAugE) with key size N to AugDict[E], E is type of extra
+// bits N - loads bit slice N len to []byte, or to *big.Int as unsigned big-endian integer, or to string as lowercase hex (## N > 64 too)
+// hash - loads 256 bits to Bits256, which is rendered as hex in JSON
 // bool - loads 1 bit boolean
-// addr - loads ton address
+// flags N - loads N bits to struct of bool fields, first field is the highest bit, not mapped bits are ignored on load and zero on store
+// timestamp N - loads N bits unix time to time.Time, 0 is zero time
+// unary - loads unary number (N ones followed by zero) to uint field
+// addr - loads ton address of any type (none, extern, std, var), anycast is kept
+// addr [nobounce] [testnet] - for string field loads std address in user-friendly form, empty string is addr_none
 // maybe - reads 1 bit, and loads rest if its 1, can be used in combination with others only
-// either X Y - reads 1 bit, if its 0 - loads X, if 1 - loads Y
+// maybe:Field - the same as maybe, but presence bit is also written to bool Field on load and taken from it on store,
+// useful for non-pointer fields where zero value cannot be distinguished from absence, for example "maybe:HasFee ## 32"
+// default:V - value assigned on load when maybe bit is 0, on store value is always written as present, for example "maybe ## 32 default:100"
+// either X Y - reads 1 bit, if its 0 - loads X, if 1 - loads Y, on store ref option is preferred
+// either . ^ fit - the same as 'either . ^' (or 'either ^ .'), but on store value is put inline if it fits into the rest of the cell,
+// otherwise to ref, like wallets build message bodies
+// either (X) (Y) - branches can be grouped with parentheses to use few tags, for example "either (## 32) (^ ## 32)"
+// maybe and either can be chained in any order, for example "maybe maybe ^" or "maybe either (maybe .) ^"
+// either:Field X Y - the same as either, but chosen branch is written to bool Field on load (true for Y),
+// and taken from it on store, so value can be serialized back identically, for example "either:BodyInRef . ^"
+// ^ or ^ max:N on []T - loads element from each ref until refs are exhausted or N elements are loaded, T can be struct or *cell.Cell,
+// on store each element is written to its own ref
+// bool N - loads N bits integer to bool, it should be 0 or 1, for example "bool 8"
+// str N - loads N bytes to utf-8 string, trailing zero bytes are trimmed, on store string is padded with zero bytes
+// pad N - skips N bits, on store zero bits are written, field value is not used, for example "_ struct{} `tlb:"pad 4"`"
+// skip N [X] - skips N reserved bits before the field value loaded using X, on store zero bits are written,
+// without X it is the same as pad, for example "skip 3 ## 5"
+// align N - skips bits until offset in the cell is multiple of N, on store zero bits are written, offset is counted
+// from the beginning of the cell, so it should not be used in inner structs which are stored inline after other data
+// remaining - loads all the rest bits and refs of the current loader to *cell.Cell or *cell.Slice
+// unknown - *cell.Cell which receives bits and refs left in the cell of struct after all fields are loaded,
+// when Options.ForwardCompatible is set, on store its data is written back, so it should be the last field
+// coins - loads VarUInteger 16 amount to Coins, *big.Int or uint64
+// cell - snapshots all the rest bits and refs of the current loader to *cell.Cell without consuming them,
+// so next fields are loaded from the same data, useful to keep original payload for hashing, on store it is skipped
+// refs - loads all the rest refs of the current loader to []*cell.Cell
+// repeat N [X] - loads N bits count and then that many elements to slice, each using tag X ('.' by default), for example "repeat 8 ^" or "repeat 4 ## 32"
+// try(A; B) - tries to load candidates in order and keeps the first one which is decoded, candidate can start with magic,
+// for example "try(#aabbccdd .; #ddccbbaa ^)", with 'variant:Field' index of matched candidate is written to integer Field on load
+// and taken from it on store, otherwise the first one is stored, try should be the whole tag of the field except modifiers
+// union A B C - loads one of the types registered using Register to interface field, type is chosen by its Magic,
+// can be combined with ref: '^ union A B C'
 // Some tags can be combined, for example "dict 256", "maybe ^"
-// Magic can be used to load first bits and check struct type, in tag can be specified magic number itself, in [#]HEX or [$]BIN format
+// use:Name - inserts tag registered with RegisterFragment, for example "maybe use:QueryID"
+// custom tag keywords can be added using RegisterTag
+// assert:V - loaded value must be equal to V, otherwise error is returned, on store V is always written, for example "## 8 assert:2"
+// mark:name - records Region of the loaded field to the Marks field of the struct, for example "bits 512 mark:sig"
+// enum:A,B,C or enum - value of integer field must be one of listed or returned by EnumValues of the field type, for example "## 4 enum:0,1,3"
+// if:Field or if:Field=V - field is loaded and stored only when previous Field is true (non-zero) or equals V, for example "if:Version=2 ## 32",
+// condition can be negated with '!' and compared using !=, <, <=, >, >=, for example "if:!HasExtra", "if:Version>=3"
+// group:a,b - labels field with groups, ToCellGroups serializes only fields of requested groups, on load it is ignored
+// cap:N - field is loaded only when capability bits N are enabled in capabilities passed with WithCapabilities to LoadFromCellContext
+// (when they are not passed, field is always loaded), on store the same is done with capabilities passed to ToCellContext, for example "cap:0x10 maybe ^"
+// Magic can be used to load first bits and check struct type, in tag can be specified magic number itself, in [#]HEX or [$]BIN format of any length
+// or it can be computed from TL-B declaration as crc32 of it, like in 'crc transfer query_id:uint64 ... = InternalMsgBody'
+// few alternatives can be accepted, like '#aabbccdd|#11223344', with optional 'variant:Field' suffix, index of matched alternative
+// is written to integer Field on load and taken from it on store, otherwise the first one is stored
 // Example:
 // _ Magic `tlb:"#deadbeef"
 // _ Magic `tlb:"$1101"
 func LoadFromCell(v any, loader *cell.Slice) error {
+	return loadFromCell(context.Background(), v, loader, nil)
+}
+
+// LoadFromCellContext - the same as LoadFromCell, but stops with error of ctx when it is done,
+// ctx is checked before each field, including fields of nested structs (except ones implementing Unmarshaler or custom LoadFromCell),
+// can be used with timeout to limit decoding time of untrusted inputs
+func LoadFromCellContext(ctx context.Context, v any, loader *cell.Slice) error {
+	if err := loadFromCell(ctx, v, loader, nil); err != nil {
+		return err
+	}
+	return checkConsumed(ctx, reflect.ValueOf(v), loader)
+}
+
+// PeekFromCell - the same as LoadFromCell, but decodes from the copy of loader, so it is not moved,
+// useful to inspect opcode or struct and pass untouched loader to the real handler
+func PeekFromCell(v any, loader *cell.Slice) error {
+	return LoadFromCell(v, loader.Copy())
+}
+
+// LoadFromCellSafe - the same as LoadFromCell, but incorrect tags and type mismatches are returned
+// as errors wrapping ErrInvalidDefinition, and panics of manual loaders as errors wrapping ErrLoaderPanic,
+// instead of panic, see Options.NoPanic
+func LoadFromCellSafe(v any, loader *cell.Slice) error {
+	return LoadFromCellContext(WithOptions(context.Background(), Options{NoPanic: true}), v, loader)
+}
+
+// LoadFromCellStrict - the same as LoadFromCell, but fails with ErrNotFullyConsumed when bits or refs are left
+// in the cell or in cells of inner structs after all fields are loaded, see Options.Strict
+func LoadFromCellStrict(v any, loader *cell.Slice) error {
+	return LoadFromCellContext(WithOptions(context.Background(), Options{Strict: true}), v, loader)
+}
+
+// Load - generic wrapper of LoadFromCell, T can be struct or pointer to struct, for example tlb.Load[InternalMessage](loader),
+// Unmarshaler (or custom LoadFromCell) of the type is used when it is implemented
+func Load[T any](loader *cell.Slice) (T, error) {
+	var v T
+	val, err := structLoad(context.Background(), reflect.TypeOf(&v).Elem(), loader)
+	if err != nil {
+		return v, err
+	}
+	return val.Interface().(T), nil
+}
+
+// loadFromCell - loads struct fields, if salvage is not nil, stops on first failed field
+// and records result to it instead of returning error
+func loadFromCell(ctx context.Context, v any, loader *cell.Slice, salvage *Salvage) (err error) {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Pointer || rv.IsNil() {
 		return fmt.Errorf("v should be a pointer and not nil")
 	}
 	rv = rv.Elem()
+	return loadPlan(ctx, rv, planOf(rv.Type()), loader, salvage)
+}
+
+// loadPlan - loads fields of struct rv using its plan, salvage is the same as for loadFromCell
+func loadPlan(ctx context.Context, rv reflect.Value, plan *typePlan, loader *cell.Slice, salvage *Salvage) (err error) {
+	if optionsOf(ctx).NoPanic {
+		defer recoverDefinition(rv.Type(), &err)
+	}
 
-	for i := 0; i < rv.NumField(); i++ {
-		field := rv.Type().Field(i)
-		tag := strings.TrimSpace(field.Tag.Get("tlb"))
-		if tag == "-" {
+	trace := optionsOf(ctx).Trace
+	depth, _ := ctx.Value(depthKey{}).(int)
+
+	var marks Marks
+	for i := range plan.fields {
+		fp := &plan.fields[i]
+		if fp.skip {
 			continue
 		}
-		settings := strings.Split(tag, " ")
 
-		if len(settings) == 0 {
-			continue
+		if fp.invalid != nil {
+			panic(fp.invalid)
 		}
+		// groups are used only on store
+		field, settings := fp.field, fp.settings
 
-		if settings[0] == "maybe" {
-			has, err := loader.LoadBoolBit()
-			if err != nil {
-				return fmt.Errorf("failed to load maybe for %s, err: %w", field.Name, err)
-			}
+		if fp.hasCond && !checkCondition(rv, field.Name, fp.cond) {
+			// reset value, to not keep previous one if struct is reused
+			rv.Field(i).Set(reflect.Zero(field.Type))
+			continue
+		}
 
-			if !has {
-				continue
-			}
-			settings = settings[1:]
+		if fp.hasCap && !capabilitiesEnabled(capabilitiesOf(ctx), field.Name, fp.capMask) {
+			// reset value, to not keep previous one if struct is reused
+			rv.Field(i).Set(reflect.Zero(field.Type))
+			continue
 		}
 
-		if settings[0] == "either" {
-			if len(settings) < 3 {
-				panic("either tag should have 2 args")
-			}
-			isSecond, err := loader.LoadBoolBit()
-			if err != nil {
-				return fmt.Errorf("failed to load maybe for %s, err: %w", field.Name, err)
-			}
+		want, hasAssert := fp.want, fp.hasAssert
+		markName, hasMark := fp.markName, fp.hasMark
+		enum, hasEnum := fp.enum, fp.hasEnum
+		presence, hasPresence := fp.presence, fp.hasPresence
+		def, hasDefault := fp.def, fp.hasDefault
+		branch, hasBranch := fp.branch, fp.hasBranch
 
-			if !isSecond {
-				settings = []string{settings[1]}
-			} else {
-				settings = []string{settings[2]}
-			}
+		if len(settings) == 0 {
+			continue
 		}
 
-		// bits
-		if settings[0] == "##" {
-			num, err := strconv.ParseUint(settings[1], 10, 64)
-			if err != nil {
+		optional := hasPresence
+		if hasDefault && !hasPresence {
+			if settings[0] != "maybe" {
 				// we panic, because its developer's issue, need to fix tag
-				panic("corrupted num bits in ## tag")
+				panic(fmt.Sprintf("default can be used only with maybe, field '%s'", field.Name))
 			}
+			// maybe bit is handled here, to know when default should be set
+			settings = settings[1:]
+			optional = true
+		}
 
-			switch {
-			case num <= 64:
-				var x any
-				switch field.Type.Kind() {
-				case reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
-					x, err = loader.LoadInt(uint(num))
-					if err != nil {
-						return fmt.Errorf("failed to load int %d, err: %w", num, err)
-					}
-				default:
-					if field.Type == reflect.TypeOf(&big.Int{}) {
-						x, err = loader.LoadBigInt(uint(num))
-						if err != nil {
-							return fmt.Errorf("failed to load bigint %d, err: %w", num, err)
-						}
-					} else {
-						x, err = loader.LoadUInt(uint(num))
-						if err != nil {
-							return fmt.Errorf("failed to load uint %d, err: %w", num, err)
-						}
-					}
-				}
+		var before *cell.Slice
+		if salvage != nil {
+			before = loader.Copy()
+		}
 
-				rv.Field(i).Set(reflect.ValueOf(x).Convert(field.Type))
-				continue
-			case num <= 256:
-				x, err := loader.LoadBigInt(uint(num))
-				if err != nil {
-					return fmt.Errorf("failed to load bigint %d, err: %w", num, err)
-				}
+		bitsOffset, refsOffset := loader.BitsOffset(), loader.RefsOffset()
 
-				rv.Field(i).Set(reflect.ValueOf(x))
-				continue
-			}
-		} else if settings[0] == "addr" {
-			x, err := loader.LoadAddr()
-			if err != nil {
-				return fmt.Errorf("failed to load address, err: %w", err)
-			}
+		err := ctx.Err()
+		if err != nil {
+			err = fmt.Errorf("decoding interrupted before %s: %w", field.Name, err)
+		}
 
-			rv.Field(i).Set(reflect.ValueOf(x))
-			continue
-		} else if settings[0] == "bool" {
-			x, err := loader.LoadBoolBit()
-			if err != nil {
-				return fmt.Errorf("failed to load bool, err: %w", err)
+		present := true
+		if err == nil && optional {
+			if present, err = loader.LoadBoolBit(); err != nil {
+				err = fmt.Errorf("failed to load maybe for %s, err: %w", field.Name, err)
+			} else if hasPresence {
+				boolField(rv, field.Name, presence).SetBool(present)
 			}
+		}
 
-			rv.Field(i).Set(reflect.ValueOf(x))
-			continue
-		} else if settings[0] == "bits" {
-			num, err := strconv.Atoi(settings[1])
-			if err != nil {
-				// we panic, because its developer's issue, need to fix tag
-				panic("corrupted num bits in bits tag")
+		if err == nil && present && hasBranch {
+			var second bool
+			if second, err = loader.LoadBoolBit(); err != nil {
+				err = fmt.Errorf("failed to load either for %s, err: %w", field.Name, err)
+			} else {
+				boolField(rv, field.Name, branch).SetBool(second)
+				settings = eitherBranch(settings, field.Name, second)
 			}
+		}
 
-			x, err := loader.LoadSlice(uint(num))
-			if err != nil {
-				return fmt.Errorf("failed to load uint %d, err: %w", num, err)
+		if err == nil {
+			switch {
+			case present:
+				err = loadCheckedField(ctx, rv, i, fp.load, settings, loader, enum, hasEnum, want, hasAssert)
+			case hasDefault:
+				rv.Field(i).Set(parseValue(field.Type, field.Name, def))
+			default:
+				// reset value, to not keep previous one if struct is reused
+				rv.Field(i).Set(reflect.Zero(field.Type))
 			}
+		}
 
-			rv.Field(i).Set(reflect.ValueOf(x))
-			continue
-		} else if settings[0] == "^" || settings[0] == "." {
-			next := loader
-
-			if settings[0] == "^" {
-				ref, err := loader.LoadRef()
-				if err != nil {
-					return fmt.Errorf("failed to load ref for %s, err: %w", field.Name, err)
-				}
-				next = ref
+		if err != nil {
+			err = withPath(err, rv.Type(), field.Name, bitsOffset)
+			if salvage != nil {
+				salvage.FailedField = field.Name
+				salvage.Err = err
+				salvage.Remainder = before
+				break
 			}
+			return err
+		}
 
-			switch field.Type {
-			case reflect.TypeOf(&cell.Cell{}):
-				c, err := next.ToCell()
-				if err != nil {
-					return fmt.Errorf("failed to convert ref to cell for %s, err: %w", field.Name, err)
-				}
-
-				rv.Field(i).Set(reflect.ValueOf(c))
-				continue
-			default:
-				nVal, err := structLoad(field.Type, next)
-				if err != nil {
-					return err
-				}
+		if salvage != nil {
+			salvage.Loaded = append(salvage.Loaded, field.Name)
+		}
 
-				rv.Field(i).Set(nVal)
-				continue
+		if hasMark {
+			if marks == nil {
+				marks = Marks{}
 			}
-		} else if field.Type == reflect.TypeOf(Magic{}) {
-			var sz, base int
-			if strings.HasPrefix(settings[0], "#") {
-				base = 16
-				sz = (len(settings[0]) - 1) * 4
-			} else if strings.HasPrefix(settings[0], "$") {
-				base = 2
-				sz = len(settings[0]) - 1
-			} else {
-				panic("unknown magic value type in tag")
+			marks[markName] = Region{
+				BitsOffset: bitsOffset,
+				Bits:       loader.BitsOffset() - bitsOffset,
+				RefsOffset: refsOffset,
+				Refs:       loader.RefsOffset() - refsOffset,
 			}
+		}
 
-			if sz > 64 {
-				panic("too big magic value type in tag")
-			}
+		if trace != nil {
+			trace(newFieldTrace(rv, i, depth, false, bitsOffset, loader.BitsOffset(), refsOffset, loader.RefsOffset()))
+		}
+	}
 
-			magic, err := strconv.ParseInt(settings[0][1:], base, 64)
-			if err != nil {
-				panic("corrupted magic value in tag")
-			}
+	if marks != nil && plan.marks >= 0 {
+		rv.Field(plan.marks).Set(reflect.ValueOf(marks))
+	}
 
-			ldMagic, err := loader.LoadUInt(uint(sz))
-			if err != nil {
-				return fmt.Errorf("failed to load magic: %w", err)
-			}
+	return nil
+}
 
-			if ldMagic != uint64(magic) {
-				return fmt.Errorf("magic is not correct for %s, want %x, got %x", rv.Type().String(), magic, ldMagic)
-			}
-			continue
-		} else if settings[0] == "dict" {
-			sz, err := strconv.ParseUint(settings[1], 10, 64)
-			if err != nil {
-				panic(fmt.Sprintf("cannot deserialize field '%s' as dict, bad size '%s'", field.Name, settings[1]))
-			}
+// loadRemaining - loads all the rest bits and refs of loader to cell
+func loadRemaining(loader *cell.Slice) (*cell.Cell, error) {
+	c, err := loader.ToCell()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to cell: %w", err)
+	}
 
-			dict, err := loader.LoadDict(uint(sz))
-			if err != nil {
-				return fmt.Errorf("failed to load ref for %s, err: %w", field.Name, err)
-			}
+	// consume everything what we captured
+	if _, err = loader.LoadSlice(loader.BitsLeft()); err != nil {
+		return nil, fmt.Errorf("failed to skip bits: %w", err)
+	}
+	for loader.RefsNum() > 0 {
+		if _, err = loader.LoadRef(); err != nil {
+			return nil, fmt.Errorf("failed to skip refs: %w", err)
+		}
+	}
+	return c, nil
+}
 
-			if len(settings) >= 4 {
-				// transformation
-				if settings[2] == "->" {
-					isRef := false
-					if len(settings) >= 5 {
-						if settings[4] == "^" {
-							isRef = true
-						}
-					}
+func ToCell(v any) (*cell.Cell, error) {
+	return toCell(v, storeOptions{}, nil)
+}
 
-					switch settings[3] {
-					case "array":
-						arr := rv.Field(i)
-						for _, kv := range dict.All() {
-							ld := kv.Value.BeginParse()
-							if isRef {
-								ld, err = ld.LoadRef()
-								if err != nil {
-									return fmt.Errorf("failed to load ref in dict transform: %w", err)
-								}
-							}
-
-							nVal, err := structLoad(field.Type.Elem(), ld)
-							if err != nil {
-								return fmt.Errorf("failed to load struct in dict transform: %w", err)
-							}
-
-							arr = reflect.Append(arr, nVal)
-						}
-						rv.Field(i).Set(arr)
-						continue
-					default:
-						panic("transformation to this type is not supported")
-					}
-				}
-			}
+// ToCellSafe - the same as ToCell, but incorrect tags and type mismatches are returned
+// as errors wrapping ErrInvalidDefinition instead of panic
+func ToCellSafe(v any) (c *cell.Cell, err error) {
+	defer recoverDefinition(reflect.TypeOf(v), &err)
+	return ToCell(v)
+}
 
-			rv.Field(i).Set(reflect.ValueOf(dict))
-			continue
-		}
+// Store - generic wrapper of ToCell, T can be struct or pointer to struct,
+// Marshaler (or custom ToCell) of the type is used when it is implemented
+func Store[T any](v T) (*cell.Cell, error) {
+	return structStore(reflect.ValueOf(&v).Elem(), reflect.TypeOf(&v).Elem().String(), storeOptions{}, nil)
+}
+
+// StoreToBuilder - the same as ToCell, but appends fields of v to the existing builder,
+// for example after manually written header, instead of creating a separate cell
+func StoreToBuilder(v any, b *cell.Builder) error {
+	return storeToBuilder(v, b, storeOptions{}, nil, nil)
+}
 
-		panic(fmt.Sprintf("cannot deserialize field '%s' as tag '%s'", field.Name, tag))
+// ToCellGroups - serializes only fields of v labeled with one of groups using 'group:a,b' modifier,
+// so partial layout can be built from the struct of full data, inner structs are serialized fully
+func ToCellGroups(v any, groups ...string) (*cell.Cell, error) {
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("at least one group should be specified")
 	}
+	return toCellGroups(v, storeOptions{}, nil, groups)
+}
 
-	return nil
+func toCell(v any, options storeOptions, audit *auditor) (*cell.Cell, error) {
+	return toCellGroups(v, options, audit, nil)
 }
 
-func ToCell(v any) (*cell.Cell, error) {
+// toCellGroups - serializes v, when groups are not empty only fields of these groups are serialized
+func toCellGroups(v any, options storeOptions, audit *auditor, groups []string) (*cell.Cell, error) {
+	builder := cell.BeginCell()
+	if err := storeToBuilder(v, builder, options, audit, groups); err != nil {
+		return nil, err
+	}
+	return builder.EndCell(), nil
+}
+
+// storeToBuilder - serializes fields of v to builder, when groups are not empty only fields of these groups are serialized
+func storeToBuilder(v any, builder *cell.Builder, options storeOptions, audit *auditor, groups []string) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() == reflect.Pointer {
 		if rv.IsNil() {
-			return nil, fmt.Errorf("v should not be nil")
+			return fmt.Errorf("v should not be nil")
 		}
 		rv = rv.Elem()
 	}
+	return storePlan(rv, planOf(rv.Type()), builder, options, audit, groups)
+}
 
-	builder := cell.BeginCell()
-
-	for i := 0; i < rv.NumField(); i++ {
-		field := rv.Type().Field(i)
-		fieldVal := rv.Field(i)
-		tag := strings.TrimSpace(field.Tag.Get("tlb"))
-		if tag == "-" {
+// storePlan - serializes fields of struct rv to builder using its plan, groups are the same as for storeToBuilder
+func storePlan(rv reflect.Value, plan *typePlan, builder *cell.Builder, options storeOptions, audit *auditor, groups []string) error {
+	for i := range plan.fields {
+		fp := &plan.fields[i]
+		if fp.skip {
 			continue
 		}
-		settings := strings.Split(tag, " ")
 
-		if len(settings) == 0 {
+		if fp.invalid != nil {
+			panic(fp.invalid)
+		}
+		field, fieldVal, settings := fp.field, rv.Field(i), fp.settings
+		bitsOffset, refsOffset := builder.BitsUsed(), builder.RefsUsed()
+
+		if groups != nil && !inGroups(fp.group, groups) {
 			continue
 		}
 
-		if settings[0] == "maybe" {
-			if field.Type.Kind() == reflect.Pointer && fieldVal.IsNil() {
-				if err := builder.StoreBoolBit(false); err != nil {
-					return nil, fmt.Errorf("cannot store maybe bit: %w", err)
-				}
-				continue
-			}
+		if fp.hasCond && !checkCondition(rv, field.Name, fp.cond) {
+			audit.record(field.Name, fmt.Sprintf("skipped, condition '%s' is false", fp.cond))
+			continue
+		}
 
-			if err := builder.StoreBoolBit(true); err != nil {
-				return nil, fmt.Errorf("cannot store maybe bit: %w", err)
-			}
-			settings = settings[1:]
+		if fp.hasCap && !capabilitiesEnabled(options.caps, field.Name, fp.capMask) {
+			audit.record(field.Name, fmt.Sprintf("skipped, capabilities %s are not enabled", fp.capMask))
+			continue
 		}
 
-		if settings[0] == "either" {
-			if len(settings) < 3 {
-				panic("either tag should have 2 args")
+		if fp.hasPresence {
+			has := boolField(rv, field.Name, fp.presence).Bool()
+			if err := builder.StoreBoolBit(has); err != nil {
+				return withPath(fmt.Errorf("cannot store maybe bit of %s: %w", field.Name, err), rv.Type(), field.Name, bitsOffset)
 			}
 
-			// currently, if one of the options is ref - we choose it
-			second := strings.HasPrefix(settings[2], "^")
-			if err := builder.StoreBoolBit(second); err != nil {
-				return nil, fmt.Errorf("cannot store maybe bit: %w", err)
+			if !has {
+				audit.record(field.Name, fmt.Sprintf("omitted, %s is false", fp.presence))
+				audit.span(rv, i, bitsOffset, refsOffset, builder)
+				continue
 			}
+		}
 
-			if second {
-				settings = []string{settings[2]}
-			} else {
-				settings = []string{settings[1]}
+		if fp.hasAssert {
+			// we always store expected value
+			exp := parseValue(field.Type, field.Name, fp.want)
+			if !valuesEqual(fieldVal, exp) {
+				audit.record(field.Name, fmt.Sprintf("value %v replaced with asserted %s", fieldVal.Interface(), fp.want))
 			}
+			fieldVal = exp
 		}
 
-		if settings[0] == "##" {
-			num, err := strconv.ParseUint(settings[1], 10, 64)
-			if err != nil {
-				// we panic, because its developer's issue, need to fix tag
-				panic("corrupted num bits in ## tag")
+		if fp.hasEnum {
+			if err := checkEnum(fieldVal, field.Name, fp.enum); err != nil {
+				return withPath(err, rv.Type(), field.Name, bitsOffset)
 			}
+		}
 
-			switch {
-			case num <= 64:
-				switch field.Type.Kind() {
-				case reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
-					err = builder.StoreInt(fieldVal.Int(), uint(num))
-					if err != nil {
-						return nil, fmt.Errorf("failed to store int %d, err: %w", num, err)
-					}
-				default:
-					if field.Type == reflect.TypeOf(&big.Int{}) {
-						err = builder.StoreBigInt(fieldVal.Interface().(*big.Int), uint(num))
-						if err != nil {
-							return nil, fmt.Errorf("failed to store bigint %d, err: %w", num, err)
-						}
-						continue
-					}
+		if fp.hasBranch {
+			second := boolField(rv, field.Name, fp.branch).Bool()
+			settings = eitherBranch(settings, field.Name, second)
 
-					err = builder.StoreUInt(fieldVal.Uint(), uint(num))
-					if err != nil {
-						return nil, fmt.Errorf("failed to store uint %d, err: %w", num, err)
-					}
-				}
-				continue
-			case num <= 256:
-				err := builder.StoreBigInt(fieldVal.Interface().(*big.Int), uint(num))
-				if err != nil {
-					return nil, fmt.Errorf("failed to store bigint %d, err: %w", num, err)
-				}
-				continue
-			}
-		} else if settings[0] == "addr" {
-			err := builder.StoreAddr(fieldVal.Interface().(*address.Address))
-			if err != nil {
-				return nil, fmt.Errorf("failed to store address, err: %w", err)
-			}
-			continue
-		} else if settings[0] == "bool" {
-			err := builder.StoreBoolBit(fieldVal.Bool())
-			if err != nil {
-				return nil, fmt.Errorf("failed to store bool, err: %w", err)
-			}
-			continue
-		} else if settings[0] == "bits" {
-			num, err := strconv.Atoi(settings[1])
-			if err != nil {
-				// we panic, because its developer's issue, need to fix tag
-				panic("corrupted num bits in bits tag")
+			if err := builder.StoreBoolBit(second); err != nil {
+				return withPath(fmt.Errorf("cannot store either bit of %s: %w", field.Name, err), rv.Type(), field.Name, bitsOffset)
 			}
+			audit.record(field.Name, fmt.Sprintf("either stored as '%s', %s is %v", settings[0], fp.branch, second))
+		}
 
-			err = builder.StoreSlice(fieldVal.Bytes(), uint(num))
-			if err != nil {
-				return nil, fmt.Errorf("failed to store bits %d, err: %w", num, err)
-			}
+		if len(settings) == 0 {
 			continue
-		} else if settings[0] == "^" || settings[0] == "." {
-			var err error
-			var c *cell.Cell
+		}
 
-			switch field.Type {
-			case reflect.TypeOf(&cell.Cell{}):
-				c = fieldVal.Interface().(*cell.Cell)
-			default:
-				c, err = structStore(fieldVal, field.Type.Name())
-				if err != nil {
-					return nil, err
-				}
+		if strings.HasPrefix(settings[0], "try(") {
+			var err error
+			if settings, err = storeTryPrefix(rv, i, settings, builder, options, audit); err != nil {
+				return withPath(err, rv.Type(), field.Name, bitsOffset)
 			}
 
-			if settings[0] == "^" {
-				err = builder.StoreRef(c)
-				if err != nil {
-					return nil, fmt.Errorf("failed to store cell to ref for %s, err: %w", field.Name, err)
-				}
+			if len(settings) == 0 {
 				continue
 			}
+		}
 
-			err = builder.StoreBuilder(c.ToBuilder())
-			if err != nil {
-				return nil, fmt.Errorf("failed to store cell to builder for %s, err: %w", field.Name, err)
-			}
-			continue
-		} else if field.Type == reflect.TypeOf(Magic{}) {
-			var sz, base int
-			if strings.HasPrefix(settings[0], "#") {
-				base = 16
-				sz = (len(settings[0]) - 1) * 4
-			} else if strings.HasPrefix(settings[0], "$") {
-				base = 2
-				sz = len(settings[0]) - 1
-			} else {
-				panic("unknown magic value type in tag")
-			}
-
-			if sz > 64 {
-				panic("too big magic value type in tag")
-			}
-
-			magic, err := strconv.ParseInt(settings[0][1:], base, 64)
+		if field.Type == reflect.TypeOf(Magic{}) {
+			alt, err := magicVariant(rv, i, settings[0])
 			if err != nil {
-				panic("corrupted magic value in tag")
+				return withPath(err, rv.Type(), field.Name, bitsOffset)
 			}
+			settings = []string{alt}
+		}
 
-			err = builder.StoreUInt(uint64(magic), uint(sz))
-			if err != nil {
-				return nil, fmt.Errorf("failed to store magic: %w", err)
-			}
-			continue
-		} else if settings[0] == "dict" {
-			err := builder.StoreDict(fieldVal.Interface().(*cell.Dictionary))
-			if err != nil {
-				return nil, fmt.Errorf("failed to store dict for %s, err: %w", field.Name, err)
-			}
-			continue
+		var err error
+		if fp.store != nil {
+			err = fp.store(fieldVal, builder)
+		} else {
+			err = storeField(field, fieldVal, settings, builder, options, audit)
 		}
 
-		panic(fmt.Sprintf("cannot serialize field '%s' as tag '%s', use manual serialization", field.Name, tag))
+		if err != nil {
+			return withPath(err, rv.Type(), field.Name, bitsOffset)
+		}
+		audit.span(rv, i, bitsOffset, refsOffset, builder)
 	}
 
-	return builder.EndCell(), nil
+	return nil
 }
 
-func structLoad(field reflect.Type, loader *cell.Slice) (reflect.Value, error) {
-	newTyp := field
-	if newTyp.Kind() == reflect.Ptr {
-		newTyp = newTyp.Elem()
+// loadCheckedField - loads field i using compiled load handler, or using tag settings when it is nil,
+// and validates it using enum and assert modifiers
+func loadCheckedField(ctx context.Context, rv reflect.Value, i int, load fieldLoader, settings []string, loader *cell.Slice, enum string, hasEnum bool, want string, hasAssert bool) error {
+	field := rv.Type().Field(i)
+
+	var err error
+	if load != nil {
+		err = load(rv.Field(i), loader)
+	} else {
+		err = loadField(ctx, rv, i, settings, loader)
 	}
 
-	nVal := reflect.New(newTyp)
-	inf := nVal.Interface()
+	if err != nil {
+		return err
+	}
 
-	if ld, ok := inf.(manualLoader); ok {
-		err := ld.LoadFromCell(loader)
-		if err != nil {
-			return reflect.Value{}, fmt.Errorf("failed to load from cell for %s, using manual loader, err: %w", field.Name(), err)
-		}
-	} else {
-		err := LoadFromCell(nVal.Interface(), loader)
-		if err != nil {
-			return reflect.Value{}, fmt.Errorf("failed to load from cell for %s, err: %w", field.Name(), err)
+	if hasEnum {
+		if err := checkEnum(rv.Field(i), field.Name, enum); err != nil {
+			return err
 		}
 	}
 
-	if field.Kind() != reflect.Ptr {
-		nVal = nVal.Elem()
+	if hasAssert {
+		exp := parseValue(field.Type, field.Name, want)
+		if !valuesEqual(rv.Field(i), exp) {
+			return fmt.Errorf("assertion failed for %s, want %v, got %v", field.Name, exp.Interface(), rv.Field(i).Interface())
+		}
 	}
-
-	return nVal, nil
+	return nil
 }
 
-func structStore(field reflect.Value, name string) (*cell.Cell, error) {
-	inf := field.Interface()
+// loadField - loads field i of the struct rv using tag settings
+func loadField(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+	settings = expandAlias(settings)
+	tag := strings.Join(settings, " ")
+
+	if settings[0] == "maybe" {
+		if len(settings) == 2 && settings[1] == "^" && field.Type == reflect.TypeOf(&cell.Cell{}) {
+			// Maybe ^Cell, nil when not exists
+			ref, err := loader.LoadMaybeRef()
+			if err != nil {
+				return fmt.Errorf("failed to load maybe ref for %s, err: %w", field.Name, err)
+			}
+
+			var c *cell.Cell
+			if ref != nil {
+				c, err = ref.ToCell()
+				if err != nil {
+					return fmt.Errorf("failed to convert ref to cell for %s, err: %w", field.Name, err)
+				}
+			}
+
+			fieldVal.Set(reflect.ValueOf(c))
+			return nil
+		}
+
+		has, err := loader.LoadBoolBit()
+		if err != nil {
+			return fmt.Errorf("failed to load maybe for %s, err: %w", field.Name, err)
+		}
+
+		if !has {
+			// reset value, to not keep previous one if struct is reused
+			fieldVal.Set(reflect.Zero(field.Type))
+			return nil
+		}
+		// value can be wrapped by more modifiers, like 'maybe maybe ^'
+		return loadField(ctx, rv, i, settings[1:], loader)
+	}
+
+	if settings[0] == "either" {
+		args, _ := eitherFit(settings[1:])
+		first, second := splitEither(args, field.Name)
+		isSecond, err := loader.LoadBoolBit()
+		if err != nil {
+			return fmt.Errorf("failed to load maybe for %s, err: %w", field.Name, err)
+		}
+
+		if isSecond {
+			return loadField(ctx, rv, i, second, loader)
+		}
+		return loadField(ctx, rv, i, first, loader)
+	}
+
+	if strings.HasPrefix(settings[0], "try(") {
+		return loadTry(ctx, rv, i, settings, loader)
+	}
+
+	if h, ok := customTag(settings[0]); ok {
+		return loadCustomTag(h, field, fieldVal, settings, loader)
+	}
+
+	if limit, ok := refSliceTag(field, settings); ok {
+		return loadRefSlice(ctx, field, fieldVal, limit, loader)
+	}
+
+	if field.Type == reflect.TypeOf(Magic{}) {
+		return loadMagicTag(ctx, rv, i, settings, loader)
+	}
+
+	if t, ok := dataTags[settings[0]]; ok {
+		return t.load(ctx, rv, i, settings, loader)
+	}
+
+	panic(fmt.Sprintf("cannot deserialize field '%s' as tag '%s'", field.Name, tag))
+}
+
+// loadIntTag - loads '## N' field, integer of N bits
+func loadIntTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	num, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil {
+		// we panic, because its developer's issue, need to fix tag
+		panic("corrupted num bits in ## tag")
+	}
+
+	if num > 64 && field.Type.Kind() == reflect.String {
+		return loadHexBits(loader, fieldVal, uint(num))
+	}
+
+	switch {
+	case num <= 64:
+		var x any
+		switch field.Type.Kind() {
+		case reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
+			x, err = loader.LoadInt(uint(num))
+			if err != nil {
+				return fmt.Errorf("failed to load int %d, err: %w", num, err)
+			}
+		default:
+			if field.Type == reflect.TypeOf(&big.Int{}) {
+				x, err = loader.LoadBigInt(uint(num))
+				if err != nil {
+					return fmt.Errorf("failed to load bigint %d, err: %w", num, err)
+				}
+			} else {
+				x, err = loader.LoadUInt(uint(num))
+				if err != nil {
+					return fmt.Errorf("failed to load uint %d, err: %w", num, err)
+				}
+			}
+		}
+
+		fieldVal.Set(reflect.ValueOf(x).Convert(field.Type))
+		return nil
+	case num <= 256:
+		x, err := loader.LoadBigInt(uint(num))
+		if err != nil {
+			return fmt.Errorf("failed to load bigint %d, err: %w", num, err)
+		}
+
+		fieldVal.Set(reflect.ValueOf(x))
+		return nil
+	}
+	panic(fmt.Sprintf("cannot deserialize field '%s' as tag '%s'", field.Name, strings.Join(settings, " ")))
+}
+
+// loadAddrTag - loads 'addr' field
+func loadAddrTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	x, err := loader.LoadAddr()
+	if err != nil {
+		return fmt.Errorf("failed to load address, err: %w", err)
+	}
+
+	if field.Type.Kind() == reflect.String {
+		str, err := addrToString(x, settings[1:])
+		if err != nil {
+			return fmt.Errorf("failed to convert address of %s to string, err: %w", field.Name, err)
+		}
+
+		fieldVal.SetString(str)
+		return nil
+	}
+
+	fieldVal.Set(reflect.ValueOf(x))
+	return nil
+}
+
+// loadBoolTag - loads 'bool' and 'bool N' field
+func loadBoolTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	if len(settings) > 1 {
+		num := boolBits(field.Name, settings[1])
+		x, err := loader.LoadUInt(num)
+		if err != nil {
+			return fmt.Errorf("failed to load bool %d for %s, err: %w", num, field.Name, err)
+		}
+
+		if x > 1 {
+			return fmt.Errorf("incorrect bool value %d of %s, should be 0 or 1", x, field.Name)
+		}
+
+		fieldVal.SetBool(x == 1)
+		return nil
+	}
+
+	x, err := loader.LoadBoolBit()
+	if err != nil {
+		return fmt.Errorf("failed to load bool, err: %w", err)
+	}
+
+	fieldVal.Set(reflect.ValueOf(x))
+	return nil
+}
+
+// loadFlagsTag - loads 'flags N' field
+func loadFlagsTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	num := parseFlagsTag(settings, field.Type)
+
+	x, err := loader.LoadUInt(num)
+	if err != nil {
+		return fmt.Errorf("failed to load flags %d for %s, err: %w", num, field.Name, err)
+	}
+
+	for j := 0; j < field.Type.NumField(); j++ {
+		fieldVal.Field(j).SetBool(x>>(num-1-uint(j))&1 == 1)
+	}
+	return nil
+}
+
+// loadTimestampTag - loads 'timestamp N' field
+func loadTimestampTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	num, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil || num > 64 {
+		// we panic, because its developer's issue, need to fix tag
+		panic("corrupted num bits in timestamp tag")
+	}
+
+	x, err := loader.LoadUInt(uint(num))
+	if err != nil {
+		return fmt.Errorf("failed to load timestamp %d for %s, err: %w", num, field.Name, err)
+	}
+
+	var tm time.Time
+	if x != 0 {
+		tm = time.Unix(int64(x), 0).UTC()
+	}
+
+	fieldVal.Set(reflect.ValueOf(tm))
+	return nil
+}
+
+// loadStrTag - loads 'str N' field
+func loadStrTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	n := strBytes(field, settings)
+	data, err := loader.LoadSlice(n * 8)
+	if err != nil {
+		return fmt.Errorf("failed to load string of %d bytes for %s, err: %w", n, field.Name, err)
+	}
+
+	data = bytes.TrimRight(data, "\x00")
+	if !utf8.Valid(data) {
+		return fmt.Errorf("string of %s is not valid utf-8", field.Name)
+	}
+
+	fieldVal.SetString(string(data))
+	return nil
+}
+
+// loadSkipTag - loads 'skip N [tag]' field
+func loadSkipTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+
+	n := padBits(field.Name, settings, 0)
+	if _, err := loader.LoadSlice(n); err != nil {
+		return fmt.Errorf("failed to skip %d reserved bits for %s, err: %w", n, field.Name, err)
+	}
+
+	if len(settings) > 2 {
+		return loadField(ctx, rv, i, settings[2:], loader)
+	}
+	return nil
+}
+
+// loadPadTag - loads 'pad N' and 'align N' field
+func loadPadTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+
+	n := padBits(field.Name, settings, loader.BitsOffset())
+	if _, err := loader.LoadSlice(n); err != nil {
+		return fmt.Errorf("failed to skip %d padding bits for %s, err: %w", n, field.Name, err)
+	}
+	return nil
+}
+
+// loadUnaryTag - loads 'unary' field
+func loadUnaryTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	var n uint64
+	for {
+		bit, err := loader.LoadBoolBit()
+		if err != nil {
+			return fmt.Errorf("failed to load unary for %s, err: %w", field.Name, err)
+		}
+		if !bit {
+			break
+		}
+		n++
+	}
+
+	fieldVal.Set(reflect.ValueOf(n).Convert(field.Type))
+	return nil
+}
+
+// loadBitsTag - loads 'bits N' field
+func loadBitsTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	num, err := strconv.Atoi(settings[1])
+	if err != nil {
+		// we panic, because its developer's issue, need to fix tag
+		panic("corrupted num bits in bits tag")
+	}
+
+	if field.Type.Kind() == reflect.String {
+		return loadHexBits(loader, fieldVal, uint(num))
+	}
+
+	if field.Type == reflect.TypeOf(&big.Int{}) {
+		x, err := loader.LoadBigUInt(uint(num))
+		if err != nil {
+			return fmt.Errorf("failed to load bits %d, err: %w", num, err)
+		}
+
+		fieldVal.Set(reflect.ValueOf(x))
+		return nil
+	}
+
+	x, err := loader.LoadSlice(uint(num))
+	if err != nil {
+		return fmt.Errorf("failed to load uint %d, err: %w", num, err)
+	}
+
+	fieldVal.Set(reflect.ValueOf(x))
+	return nil
+}
+
+// loadHashTag - loads 'hash' field
+func loadHashTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	if field.Type != reflect.TypeOf(Bits256{}) {
+		panic(fmt.Sprintf("hash tag can be used only with Bits256, field '%s'", field.Name))
+	}
+
+	x, err := loader.LoadSlice(256)
+	if err != nil {
+		return fmt.Errorf("failed to load hash for %s, err: %w", field.Name, err)
+	}
+
+	var h Bits256
+	copy(h[:], x)
+	fieldVal.Set(reflect.ValueOf(h))
+	return nil
+}
+
+// loadRefTag - loads '^' field, and '^ tag' field with definition inside ref
+func loadRefTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	if len(settings) > 1 {
+		// ref with definition inside, like '^ union A B'
+		ref, err := loader.LoadRef()
+		if err != nil {
+			return fmt.Errorf("failed to load ref for %s, err: %w", field.Name, err)
+		}
+
+		idx := loader.RefsOffset() - 1
+		if ref, err = checkExotic(ctx, ref); err != nil {
+			return inRef(fmt.Errorf("failed to load ref for %s, err: %w", field.Name, err), idx, 0)
+		}
+		if err = loadField(ctx, rv, i, settings[1:], ref); err != nil {
+			return inRef(err, idx, 0)
+		}
+
+		if err = checkConsumed(ctx, fieldVal, ref); err != nil {
+			return inRef(fmt.Errorf("failed to load ref for %s, err: %w", field.Name, err), idx, ref.BitsOffset())
+		}
+		return nil
+	}
+	return loadInnerTag(ctx, rv, i, settings, loader)
+}
+
+// loadInnerTag - loads inner struct or cell of '^' and '.' field
+func loadInnerTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	next := loader
+
+	if settings[0] == "^" {
+		ref, err := loader.LoadRef()
+		if err != nil {
+			return fmt.Errorf("failed to load ref for %s, err: %w", field.Name, err)
+		}
+		next = ref
+	}
+
+	switch field.Type {
+	case reflect.TypeOf(&cell.Cell{}):
+		c, err := next.ToCell()
+		if err != nil {
+			return fmt.Errorf("failed to convert ref to cell for %s, err: %w", field.Name, err)
+		}
+
+		fieldVal.Set(reflect.ValueOf(c))
+		return nil
+	default:
+		idx := loader.RefsOffset() - 1
+		if settings[0] == "^" {
+			var err error
+			if next, err = checkExotic(ctx, next); err != nil {
+				return inRef(fmt.Errorf("failed to load ref for %s, err: %w", field.Name, err), idx, 0)
+			}
+		}
+
+		nVal, err := structLoad(ctx, field.Type, next)
+		if err != nil {
+			if settings[0] == "^" {
+				return inRef(err, idx, 0)
+			}
+			return err
+		}
+
+		if settings[0] == "^" {
+			if err = checkConsumed(ctx, nVal, next); err != nil {
+				return inRef(fmt.Errorf("failed to load ref for %s, err: %w", field.Name, err), idx, next.BitsOffset())
+			}
+		}
+
+		if !fieldVal.CanSet() && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			// embedded struct of unexported type, its exported fields are still settable
+			copyFields(fieldVal, nVal)
+			return nil
+		}
+
+		fieldVal.Set(nVal)
+		return nil
+	}
+}
+
+// loadUnionTag - loads 'union A B' field
+func loadUnionTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	if field.Type.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("union tag can be used only with interface field, field '%s'", field.Name))
+	}
+
+	nVal, err := unionLoad(ctx, field.Type, settings[1:], loader)
+	if err != nil {
+		return keepFieldError(err, fmt.Errorf("failed to load union for %s, err: %w", field.Name, err))
+	}
+
+	fieldVal.Set(nVal)
+	return nil
+}
+
+// loadMagicTag - loads Magic field
+func loadMagicTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	_, variant := magicAlternatives(settings[0])
+	magics := parseMagics(settings[0])
+
+	for idx, magic := range magics {
+		ok, err := magic.match(loader)
+		if err != nil {
+			return fmt.Errorf("failed to load magic: %w", err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if _, err = loader.LoadSlice(magic.sz); err != nil {
+			return fmt.Errorf("failed to load magic: %w", err)
+		}
+
+		if fieldVal.CanSet() {
+			m := magic
+			fieldVal.Set(reflect.ValueOf(Magic{bits: &m}))
+		}
+
+		if variant != "" {
+			if f := variantField(rv, field.Name, variant); f.CanInt() {
+				f.SetInt(int64(idx))
+			} else {
+				f.SetUint(uint64(idx))
+			}
+		}
+		return nil
+	}
+
+	got := "not enough data"
+	if ldMagic, err := loader.Copy().LoadSlice(magics[0].sz); err == nil {
+		got = hex.EncodeToString(ldMagic)
+	}
+
+	want := make([]string, 0, len(magics))
+	for _, magic := range magics {
+		want = append(want, magic.String())
+	}
+	return fmt.Errorf("magic is not correct for %s, want %s, got %s", rv.Type().String(), strings.Join(want, " or "), got)
+}
+
+// loadRemainingTag - loads 'remaining' field
+func loadRemainingTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	c, err := loadRemaining(loader)
+	if err != nil {
+		return fmt.Errorf("failed to load remaining data for %s, err: %w", field.Name, err)
+	}
+
+	switch field.Type {
+	case reflect.TypeOf(&cell.Cell{}):
+		fieldVal.Set(reflect.ValueOf(c))
+	case reflect.TypeOf(&cell.Slice{}):
+		fieldVal.Set(reflect.ValueOf(c.BeginParse()))
+	default:
+		panic(fmt.Sprintf("remaining tag can be used only with *cell.Cell or *cell.Slice, field '%s'", field.Name))
+	}
+	return nil
+}
+
+// loadUnknownTag - loads 'unknown' field
+func loadUnknownTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	if field.Type != reflect.TypeOf(&cell.Cell{}) {
+		panic(fmt.Sprintf("unknown tag can be used only with *cell.Cell, field '%s'", field.Name))
+	}
+
+	// filled after all fields are loaded, in forward compatible mode, reset to not keep previous value
+	fieldVal.Set(reflect.Zero(field.Type))
+	return nil
+}
+
+// loadCoinsTag - loads 'coins' field
+func loadCoinsTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	x, err := loader.LoadBigCoins()
+	if err != nil {
+		return fmt.Errorf("failed to load coins for %s, err: %w", field.Name, err)
+	}
+
+	switch {
+	case field.Type == reflect.TypeOf(Coins{}):
+		fieldVal.Set(reflect.ValueOf(Coins{val: x}))
+	case field.Type == reflect.TypeOf(&big.Int{}):
+		fieldVal.Set(reflect.ValueOf(x))
+	case field.Type.Kind() == reflect.Uint64:
+		if !x.IsUint64() {
+			return fmt.Errorf("coins value of %s is too big for uint64", field.Name)
+		}
+		fieldVal.SetUint(x.Uint64())
+	default:
+		panic(fmt.Sprintf("coins tag can be used only with Coins, *big.Int or uint64, field '%s'", field.Name))
+	}
+	return nil
+}
+
+// loadCellTag - loads 'cell' field
+func loadCellTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	if field.Type != reflect.TypeOf(&cell.Cell{}) {
+		panic(fmt.Sprintf("cell tag can be used only with *cell.Cell, field '%s'", field.Name))
+	}
+
+	// snapshot of the rest, loader is not moved
+	c, err := loader.Copy().ToCell()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot remaining data to cell for %s, err: %w", field.Name, err)
+	}
+
+	fieldVal.Set(reflect.ValueOf(c))
+	return nil
+}
+
+// loadRefsTag - loads 'refs' field
+func loadRefsTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	if field.Type != reflect.TypeOf([]*cell.Cell{}) {
+		panic(fmt.Sprintf("refs tag can be used only with []*cell.Cell, field '%s'", field.Name))
+	}
+
+	var refs []*cell.Cell
+	for loader.RefsNum() > 0 {
+		ref, err := loader.LoadRef()
+		if err != nil {
+			return fmt.Errorf("failed to load ref for %s, err: %w", field.Name, err)
+		}
+
+		c, err := ref.ToCell()
+		if err != nil {
+			return fmt.Errorf("failed to convert ref to cell for %s, err: %w", field.Name, err)
+		}
+		refs = append(refs, c)
+	}
+
+	fieldVal.Set(reflect.ValueOf(refs))
+	return nil
+}
+
+// loadRepeatTag - loads 'repeat N [tag]' field
+func loadRepeatTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	sz, elemSettings := parseRepeatTag(settings, field)
+
+	num, err := loader.LoadUInt(sz)
+	if err != nil {
+		return fmt.Errorf("failed to load count of %s, err: %w", field.Name, err)
+	}
+
+	elems := reflect.MakeSlice(field.Type, 0, 0)
+	for j := uint64(0); j < num; j++ {
+		if err = ctx.Err(); err != nil {
+			return fmt.Errorf("decoding interrupted before element %d of %s: %w", j, field.Name, err)
+		}
+
+		// element is loaded as the only field of temporary struct, to reuse all tags for it
+		tmp := reflect.New(reflect.StructOf([]reflect.StructField{{Name: field.Name, Type: field.Type.Elem()}})).Elem()
+		if err = loadField(ctx, tmp, 0, elemSettings, loader); err != nil {
+			return withIndex(keepFieldError(err, fmt.Errorf("failed to load element %d of %s, err: %w", j, field.Name, err)), int(j), loader.BitsOffset())
+		}
+		elems = reflect.Append(elems, tmp.Field(0))
+	}
+
+	fieldVal.Set(elems)
+	return nil
+}
+
+// loadChunkedTag - loads 'chunked' field
+func loadChunkedTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	if field.Type != reflect.TypeOf([]byte{}) {
+		panic(fmt.Sprintf("chunked tag can be used only with []byte, field '%s'", field.Name))
+	}
+
+	data, err := loadChunked(loader)
+	if err != nil {
+		return fmt.Errorf("failed to load chunked data for %s, err: %w", field.Name, err)
+	}
+
+	fieldVal.SetBytes(data)
+	return nil
+}
+
+// loadPfxDictTag - loads 'pfxdict N' field
+func loadPfxDictTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	sz, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("cannot deserialize field '%s' as pfxdict, bad size '%s'", field.Name, settings[1]))
+	}
+
+	dict, err := loader.LoadPrefixDict(uint(sz))
+	if err != nil {
+		return fmt.Errorf("failed to load prefix dict for %s, err: %w", field.Name, err)
+	}
+
+	fieldVal.Set(reflect.ValueOf(dict))
+	return nil
+}
+
+// loadDictAugTag - loads 'dictaug N' field
+func loadDictAugTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	sz, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("cannot deserialize field '%s' as dictaug, bad size '%s'", field.Name, settings[1]))
+	}
+
+	ptr := fieldVal.Addr()
+	if field.Type.Kind() == reflect.Ptr {
+		ptr = reflect.New(field.Type.Elem())
+	}
+
+	d, ok := ptr.Interface().(augDict)
+	if !ok {
+		panic(fmt.Sprintf("cannot deserialize field '%s' as dictaug, type should be AugDict", field.Name))
+	}
+
+	if err = d.loadAug(ctx, uint(sz), loader); err != nil {
+		return fmt.Errorf("failed to load aug dict for %s, err: %w", field.Name, err)
+	}
+
+	if field.Type.Kind() == reflect.Ptr {
+		fieldVal.Set(ptr)
+	}
+	return nil
+}
+
+// loadDictTag - loads 'dict N [-> transformation]' field
+func loadDictTag(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	fieldVal := rv.Field(i)
+
+	sz, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("cannot deserialize field '%s' as dict, bad size '%s'", field.Name, settings[1]))
+	}
+
+	dict, err := loader.LoadDict(uint(sz))
+	if err != nil {
+		return fmt.Errorf("failed to load ref for %s, err: %w", field.Name, err)
+	}
+
+	if len(settings) >= 4 {
+		// transformation
+		if settings[2] == "->" {
+			opts := parseDictOptions(settings[4:])
+
+			switch settings[3] {
+			case "array":
+				elemTyp := field.Type.Elem()
+				isEntry := elemTyp.Implements(dictEntryType)
+
+				arr := fieldVal
+				for _, kv := range dict.All() {
+					if isEntry {
+						// keep key together with value
+						entry := reflect.New(elemTyp).Elem()
+
+						key, err := dictKeyLoad(entry.Field(0).Type(), kv.Key, uint(sz), opts)
+						if err != nil {
+							return fmt.Errorf("failed to load key in dict transform: %w", err)
+						}
+
+						nVal, err := dictValueLoad(ctx, entry.Field(1).Type(), kv.Value, opts.ref)
+						if err != nil {
+							return err
+						}
+
+						entry.Field(0).Set(key)
+						entry.Field(1).Set(nVal)
+						arr = reflect.Append(arr, entry)
+						continue
+					}
+
+					nVal, err := dictValueLoad(ctx, elemTyp, kv.Value, opts.ref)
+					if err != nil {
+						return err
+					}
+
+					arr = reflect.Append(arr, nVal)
+				}
+				fieldVal.Set(arr)
+				return nil
+			case "map":
+				if field.Type.Kind() != reflect.Map {
+					panic(fmt.Sprintf("cannot deserialize field '%s' as dict map, field should be a map", field.Name))
+				}
+
+				mp := reflect.MakeMapWithSize(field.Type, len(dict.All()))
+				for _, kv := range dict.All() {
+					key, err := dictKeyLoad(field.Type.Key(), kv.Key, uint(sz), opts)
+					if err != nil {
+						return fmt.Errorf("failed to load key in dict transform: %w", err)
+					}
+
+					nVal, err := dictValueLoad(ctx, field.Type.Elem(), kv.Value, opts.ref)
+					if err != nil {
+						return err
+					}
+
+					mp.SetMapIndex(key, nVal)
+				}
+				fieldVal.Set(mp)
+				return nil
+			default:
+				panic("transformation to this type is not supported")
+			}
+		}
+	}
+
+	fieldVal.Set(reflect.ValueOf(dict))
+	return nil
+}
+
+// storeField - stores field value to builder using tag settings
+func storeField(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	settings = expandAlias(settings)
+	tag := strings.Join(settings, " ")
+
+	if settings[0] == "maybe" {
+		if len(settings) == 2 && settings[1] == "^" && field.Type == reflect.TypeOf(&cell.Cell{}) {
+			// Maybe ^Cell, 0 bit when nil, 1 bit and ref when exists
+			if fieldVal.IsNil() {
+				audit.record(field.Name, "omitted, value is nil")
+			}
+
+			if err := builder.StoreMaybeRef(fieldVal.Interface().(*cell.Cell)); err != nil {
+				return fmt.Errorf("failed to store maybe ref for %s, err: %w", field.Name, err)
+			}
+			return nil
+		}
+
+		if field.Type.Kind() == reflect.Pointer && fieldVal.IsNil() {
+			if err := builder.StoreBoolBit(false); err != nil {
+				return fmt.Errorf("cannot store maybe bit: %w", err)
+			}
+			audit.record(field.Name, "omitted, value is nil")
+			return nil
+		}
+
+		if err := builder.StoreBoolBit(true); err != nil {
+			return fmt.Errorf("cannot store maybe bit: %w", err)
+		}
+		return storeField(field, fieldVal, settings[1:], builder, options, audit)
+	}
+
+	if settings[0] == "either" {
+		args, fit := eitherFit(settings[1:])
+		first, second := splitEither(args, field.Name)
+
+		// currently, if one of the options is ref - we choose it
+		isSecond := strings.HasPrefix(second[0], "^")
+		reason := "ref option is preferred"
+
+		if fit {
+			if len(first) != 1 || len(second) != 1 || !isInlineOrRef(first[0], second[0]) {
+				// we panic, because its developer's issue, need to fix tag
+				panic(fmt.Sprintf("fit option of either can be used only with '. ^' or '^ .', field '%s'", field.Name))
+			}
+
+			if field.Type.Kind() == reflect.Pointer && fieldVal.IsNil() {
+				return fmt.Errorf("value of %s is nil, use maybe if it is optional", field.Name)
+			}
+
+			// value is stored inline if it fits into the rest of the cell, like wallets do
+			c, err := fieldCell(field, fieldVal, options, nil)
+			if err != nil {
+				return err
+			}
+
+			fits := builder.BitsLeft() > c.BitsSize() && builder.RefsLeft() >= c.RefsNum()
+			isSecond = fits == (second[0] == ".")
+
+			reason = "fits into cell"
+			if !fits {
+				reason = "does not fit into cell"
+			}
+		}
+
+		chosen := first
+		if isSecond {
+			chosen = second
+		}
+		audit.record(field.Name, fmt.Sprintf("either stored as '%s', %s", strings.Join(chosen, " "), reason))
+
+		if err := builder.StoreBoolBit(isSecond); err != nil {
+			return fmt.Errorf("cannot store maybe bit: %w", err)
+		}
+		return storeField(field, fieldVal, chosen, builder, options, audit)
+	}
+
+	if h, ok := customTag(settings[0]); ok {
+		return storeCustomTag(h, field, fieldVal, settings, builder)
+	}
+
+	if limit, ok := refSliceTag(field, settings); ok {
+		return storeRefSlice(field, fieldVal, limit, builder, options, audit)
+	}
+
+	if field.Type == reflect.TypeOf(Magic{}) {
+		return storeMagicTag(field, fieldVal, settings, builder, options, audit)
+	}
+
+	if t, ok := dataTags[settings[0]]; ok {
+		return t.store(field, fieldVal, settings, builder, options, audit)
+	}
+
+	panic(fmt.Sprintf("cannot serialize field '%s' as tag '%s', use manual serialization", field.Name, tag))
+}
+
+// storeIntTag - stores '## N' field, integer of N bits
+func storeIntTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	num, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil {
+		// we panic, because its developer's issue, need to fix tag
+		panic("corrupted num bits in ## tag")
+	}
+
+	if num > 64 && field.Type.Kind() == reflect.String {
+		return storeHexBits(builder, fieldVal.String(), uint(num))
+	}
+
+	switch {
+	case num <= 64:
+		switch field.Type.Kind() {
+		case reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
+			err = builder.StoreInt(fieldVal.Int(), uint(num))
+			if err != nil {
+				return fmt.Errorf("failed to store int %d, err: %w", num, err)
+			}
+		default:
+			if field.Type == reflect.TypeOf(&big.Int{}) {
+				err = builder.StoreBigInt(fieldVal.Interface().(*big.Int), uint(num))
+				if err != nil {
+					return fmt.Errorf("failed to store bigint %d, err: %w", num, err)
+				}
+				return nil
+			}
+
+			err = builder.StoreUInt(fieldVal.Uint(), uint(num))
+			if err != nil {
+				return fmt.Errorf("failed to store uint %d, err: %w", num, err)
+			}
+		}
+		return nil
+	case num <= 256:
+		err := builder.StoreBigInt(fieldVal.Interface().(*big.Int), uint(num))
+		if err != nil {
+			return fmt.Errorf("failed to store bigint %d, err: %w", num, err)
+		}
+		return nil
+	}
+	panic(fmt.Sprintf("cannot serialize field '%s' as tag '%s', use manual serialization", field.Name, strings.Join(settings, " ")))
+}
+
+// storeAddrTag - stores 'addr' field
+func storeAddrTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	var addr *address.Address
+	if field.Type.Kind() == reflect.String {
+		var err error
+		addr, err = addrFromString(fieldVal.String())
+		if err != nil {
+			return fmt.Errorf("failed to parse address of %s, err: %w", field.Name, err)
+		}
+	} else {
+		addr = fieldVal.Interface().(*address.Address)
+	}
+
+	err := builder.StoreAddr(addr)
+	if err != nil {
+		return fmt.Errorf("failed to store address, err: %w", err)
+	}
+	return nil
+}
+
+// storeBoolTag - stores 'bool' and 'bool N' field
+func storeBoolTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	if len(settings) > 1 {
+		var x uint64
+		if fieldVal.Bool() {
+			x = 1
+		}
+
+		if err := builder.StoreUInt(x, boolBits(field.Name, settings[1])); err != nil {
+			return fmt.Errorf("failed to store bool for %s, err: %w", field.Name, err)
+		}
+		return nil
+	}
+
+	err := builder.StoreBoolBit(fieldVal.Bool())
+	if err != nil {
+		return fmt.Errorf("failed to store bool, err: %w", err)
+	}
+	return nil
+}
+
+// storeFlagsTag - stores 'flags N' field
+func storeFlagsTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	num := parseFlagsTag(settings, field.Type)
+
+	var x uint64
+	for j := 0; j < field.Type.NumField(); j++ {
+		if fieldVal.Field(j).Bool() {
+			x |= 1 << (num - 1 - uint(j))
+		}
+	}
+
+	if err := builder.StoreUInt(x, num); err != nil {
+		return fmt.Errorf("failed to store flags %d for %s, err: %w", num, field.Name, err)
+	}
+	return nil
+}
+
+// storeTimestampTag - stores 'timestamp N' field
+func storeTimestampTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	num, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil || num > 64 {
+		// we panic, because its developer's issue, need to fix tag
+		panic("corrupted num bits in timestamp tag")
+	}
+
+	var x uint64
+	if tm := fieldVal.Interface().(time.Time); !tm.IsZero() {
+		if tm.Unix() < 0 {
+			return fmt.Errorf("failed to store timestamp for %s, time before unix epoch", field.Name)
+		}
+		x = uint64(tm.Unix())
+	}
+
+	if num < 64 && x>>num != 0 {
+		return fmt.Errorf("failed to store timestamp for %s, too big for %d bits", field.Name, num)
+	}
+
+	if err = builder.StoreUInt(x, uint(num)); err != nil {
+		return fmt.Errorf("failed to store timestamp %d for %s, err: %w", num, field.Name, err)
+	}
+	return nil
+}
+
+// storeStrTag - stores 'str N' field
+func storeStrTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	n := strBytes(field, settings)
+	str := fieldVal.String()
+	if uint(len(str)) > n {
+		return fmt.Errorf("string of %s is longer than %d bytes", field.Name, n)
+	}
+
+	if !utf8.ValidString(str) || strings.IndexByte(str, 0) >= 0 {
+		return fmt.Errorf("string of %s is not valid utf-8 or contains zero bytes", field.Name)
+	}
+
+	data := make([]byte, n)
+	copy(data, str)
+	if err := builder.StoreSlice(data, n*8); err != nil {
+		return fmt.Errorf("failed to store string for %s, err: %w", field.Name, err)
+	}
+	return nil
+}
+
+// storeSkipTag - stores 'skip N [tag]' field
+func storeSkipTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	n := padBits(field.Name, settings, 0)
+	if err := builder.StoreSlice(make([]byte, (n+7)/8), n); err != nil {
+		return fmt.Errorf("failed to store %d reserved bits for %s, err: %w", n, field.Name, err)
+	}
+
+	if len(settings) > 2 {
+		return storeField(field, fieldVal, settings[2:], builder, options, audit)
+	}
+	return nil
+}
+
+// storePadTag - stores 'pad N' and 'align N' field
+func storePadTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	n := padBits(field.Name, settings, builder.BitsUsed())
+	if err := builder.StoreSlice(make([]byte, (n+7)/8), n); err != nil {
+		return fmt.Errorf("failed to store %d padding bits for %s, err: %w", n, field.Name, err)
+	}
+	return nil
+}
+
+// storeUnaryTag - stores 'unary' field
+func storeUnaryTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	n := fieldVal.Uint()
+	if n >= uint64(builder.BitsLeft()) {
+		return fmt.Errorf("failed to store unary for %s, not enough space for %d", field.Name, n)
+	}
+
+	for j := uint64(0); j < n; j++ {
+		if err := builder.StoreBoolBit(true); err != nil {
+			return fmt.Errorf("failed to store unary, err: %w", err)
+		}
+	}
+
+	if err := builder.StoreBoolBit(false); err != nil {
+		return fmt.Errorf("failed to store unary, err: %w", err)
+	}
+	return nil
+}
+
+// storeBitsTag - stores 'bits N' field
+func storeBitsTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	num, err := strconv.Atoi(settings[1])
+	if err != nil {
+		// we panic, because its developer's issue, need to fix tag
+		panic("corrupted num bits in bits tag")
+	}
+
+	if field.Type.Kind() == reflect.String {
+		return storeHexBits(builder, fieldVal.String(), uint(num))
+	}
+
+	if field.Type == reflect.TypeOf(&big.Int{}) {
+		x := fieldVal.Interface().(*big.Int)
+		if x == nil {
+			return fmt.Errorf("failed to store bits %d, err: value is nil", num)
+		}
+
+		if err = builder.StoreBigUInt(x, uint(num)); err != nil {
+			return fmt.Errorf("failed to store bits %d, err: %w", num, err)
+		}
+		return nil
+	}
+
+	err = builder.StoreSlice(fieldVal.Bytes(), uint(num))
+	if err != nil {
+		return fmt.Errorf("failed to store bits %d, err: %w", num, err)
+	}
+	return nil
+}
+
+// storeHashTag - stores 'hash' field
+func storeHashTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	if field.Type != reflect.TypeOf(Bits256{}) {
+		panic(fmt.Sprintf("hash tag can be used only with Bits256, field '%s'", field.Name))
+	}
+
+	h := fieldVal.Interface().(Bits256)
+	if err := builder.StoreSlice(h[:], 256); err != nil {
+		return fmt.Errorf("failed to store hash for %s, err: %w", field.Name, err)
+	}
+	return nil
+}
+
+// storeRefTag - stores '^' field, and '^ tag' field with definition inside ref
+func storeRefTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	if len(settings) > 1 {
+		// ref with definition inside, like '^ union A B'
+		b := cell.BeginCell()
+		if err := storeField(field, fieldVal, settings[1:], b, options, audit); err != nil {
+			return err
+		}
+
+		err := builder.StoreRef(b.EndCell())
+		if err != nil {
+			return fmt.Errorf("failed to store cell to ref for %s, err: %w", field.Name, err)
+		}
+		return nil
+	}
+	return storeInnerTag(field, fieldVal, settings, builder, options, audit)
+}
+
+// storeInnerTag - stores inner struct or cell of '^' and '.' field
+func storeInnerTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	if field.Type.Kind() == reflect.Pointer && fieldVal.IsNil() {
+		return fmt.Errorf("value of %s is nil, use maybe if it is optional", field.Name)
+	}
+
+	c, err := fieldCell(field, fieldVal, options, audit)
+	if err != nil {
+		return err
+	}
+
+	if settings[0] == "^" {
+		err = builder.StoreRef(c)
+		if err != nil {
+			return fmt.Errorf("failed to store cell to ref for %s, err: %w", field.Name, err)
+		}
+		return nil
+	}
+
+	err = builder.StoreBuilder(c.ToBuilder())
+	if err != nil {
+		return fmt.Errorf("failed to store cell to builder for %s, err: %w", field.Name, err)
+	}
+	return nil
+}
+
+// storeUnionTag - stores 'union A B' field
+func storeUnionTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	c, err := unionStore(fieldVal, settings[1:], options, audit.nested(field.Name))
+	if err != nil {
+		return keepFieldError(err, fmt.Errorf("failed to store union for %s, err: %w", field.Name, err))
+	}
+
+	err = builder.StoreBuilder(c.ToBuilder())
+	if err != nil {
+		return fmt.Errorf("failed to store union to builder for %s, err: %w", field.Name, err)
+	}
+	return nil
+}
+
+// storeMagicTag - stores Magic field
+func storeMagicTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	if err := parseMagics(settings[0])[0].store(builder); err != nil {
+		return fmt.Errorf("failed to store magic: %w", err)
+	}
+	return nil
+}
+
+// storeRemainingTag - stores 'remaining' field
+func storeRemainingTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	var c *cell.Cell
+
+	switch field.Type {
+	case reflect.TypeOf(&cell.Cell{}):
+		c = fieldVal.Interface().(*cell.Cell)
+	case reflect.TypeOf(&cell.Slice{}):
+		if sl := fieldVal.Interface().(*cell.Slice); sl != nil {
+			var err error
+			c, err = sl.ToCell()
+			if err != nil {
+				return fmt.Errorf("failed to convert remaining slice to cell for %s, err: %w", field.Name, err)
+			}
+		}
+	default:
+		panic(fmt.Sprintf("remaining tag can be used only with *cell.Cell or *cell.Slice, field '%s'", field.Name))
+	}
+
+	if c != nil {
+		err := builder.StoreBuilder(c.ToBuilder())
+		if err != nil {
+			return fmt.Errorf("failed to store remaining data for %s, err: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// storeUnknownTag - stores 'unknown' field
+func storeUnknownTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	if field.Type != reflect.TypeOf(&cell.Cell{}) {
+		panic(fmt.Sprintf("unknown tag can be used only with *cell.Cell, field '%s'", field.Name))
+	}
+
+	if c := fieldVal.Interface().(*cell.Cell); c != nil {
+		if err := builder.StoreBuilder(c.ToBuilder()); err != nil {
+			return fmt.Errorf("failed to store unknown data for %s, err: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// storeCoinsTag - stores 'coins' field
+func storeCoinsTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	var x *big.Int
+	switch {
+	case field.Type == reflect.TypeOf(Coins{}):
+		x = fieldVal.Interface().(Coins).NanoTON()
+	case field.Type == reflect.TypeOf(&big.Int{}):
+		if x = fieldVal.Interface().(*big.Int); x == nil {
+			x = big.NewInt(0)
+		}
+	case field.Type.Kind() == reflect.Uint64:
+		x = new(big.Int).SetUint64(fieldVal.Uint())
+	default:
+		panic(fmt.Sprintf("coins tag can be used only with Coins, *big.Int or uint64, field '%s'", field.Name))
+	}
+
+	if err := builder.StoreBigCoins(x); err != nil {
+		return fmt.Errorf("failed to store coins for %s, err: %w", field.Name, err)
+	}
+	return nil
+}
+
+// storeCellTag - stores 'cell' field
+func storeCellTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	// snapshot is only a view of data, which is written by the next fields
+	audit.record(field.Name, "skipped, cell snapshot is not stored")
+	return nil
+}
+
+// storeRefsTag - stores 'refs' field
+func storeRefsTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	if field.Type != reflect.TypeOf([]*cell.Cell{}) {
+		panic(fmt.Sprintf("refs tag can be used only with []*cell.Cell, field '%s'", field.Name))
+	}
+
+	for _, ref := range fieldVal.Interface().([]*cell.Cell) {
+		err := builder.StoreRef(ref)
+		if err != nil {
+			return fmt.Errorf("failed to store ref for %s, err: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// storeRepeatTag - stores 'repeat N [tag]' field
+func storeRepeatTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	sz, elemSettings := parseRepeatTag(settings, field)
+
+	num := uint64(fieldVal.Len())
+	if sz < 64 && num>>sz != 0 {
+		return fmt.Errorf("too many elements in %s to store count in %d bits", field.Name, sz)
+	}
+
+	if err := builder.StoreUInt(num, sz); err != nil {
+		return fmt.Errorf("failed to store count of %s, err: %w", field.Name, err)
+	}
+
+	elemField := reflect.StructField{Name: field.Name, Type: field.Type.Elem()}
+	for j := 0; j < fieldVal.Len(); j++ {
+		if err := storeField(elemField, fieldVal.Index(j), elemSettings, builder, options, audit.nested(fmt.Sprintf("%s[%d]", field.Name, j))); err != nil {
+			return withIndex(keepFieldError(err, fmt.Errorf("failed to store element %d of %s, err: %w", j, field.Name, err)), int(j), builder.BitsUsed())
+		}
+	}
+	return nil
+}
+
+// storeChunkedTag - stores 'chunked' field
+func storeChunkedTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	if field.Type != reflect.TypeOf([]byte{}) {
+		panic(fmt.Sprintf("chunked tag can be used only with []byte, field '%s'", field.Name))
+	}
+
+	if err := storeChunked(builder, fieldVal.Bytes()); err != nil {
+		return fmt.Errorf("failed to store chunked data for %s, err: %w", field.Name, err)
+	}
+	return nil
+}
+
+// storePfxDictTag - stores 'pfxdict N' field
+func storePfxDictTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	err := builder.StorePrefixDict(fieldVal.Interface().(*cell.PrefixDictionary))
+	if err != nil {
+		return fmt.Errorf("failed to store prefix dict for %s, err: %w", field.Name, err)
+	}
+	return nil
+}
+
+// storeDictAugTag - stores 'dictaug N' field
+func storeDictAugTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	var d augDict
+	if field.Type.Kind() == reflect.Ptr {
+		if fieldVal.IsNil() {
+			return fmt.Errorf("failed to store aug dict for %s, it is nil", field.Name)
+		}
+		d, _ = fieldVal.Interface().(augDict)
+	} else {
+		// value can be not addressable, so copy it
+		ptr := reflect.New(field.Type)
+		ptr.Elem().Set(fieldVal)
+		d, _ = ptr.Interface().(augDict)
+	}
+
+	if d == nil {
+		panic(fmt.Sprintf("cannot serialize field '%s' as dictaug, type should be AugDict", field.Name))
+	}
+
+	sz, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("cannot serialize field '%s' as dictaug, bad size '%s'", field.Name, settings[1]))
+	}
+
+	if err = d.storeAug(uint(sz), builder, options); err != nil {
+		return fmt.Errorf("failed to store aug dict for %s, err: %w", field.Name, err)
+	}
+	return nil
+}
+
+// storeDictTag - stores 'dict N [-> transformation]' field
+func storeDictTag(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	dict, ok := fieldVal.Interface().(*cell.Dictionary)
+	if !ok {
+		var err error
+		dict, err = dictFromValue(field, fieldVal, settings, options)
+		if err != nil {
+			return keepFieldError(err, fmt.Errorf("failed to build dict for %s, err: %w", field.Name, err))
+		}
+	}
+
+	err := builder.StoreDict(dict)
+	if err != nil {
+		return fmt.Errorf("failed to store dict for %s, err: %w", field.Name, err)
+	}
+	return nil
+}
+
+// loadTry - tries candidates of 'try(A; B)' tag in order on copy of loader, and keeps the first one which is decoded
+func loadTry(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error {
+	field := rv.Type().Field(i)
+	candidates, variant := tryCandidates(settings, field.Name)
+
+	var errs []string
+	for idx, candidate := range candidates {
+		// reset value, to not keep result of failed candidate
+		rv.Field(i).Set(reflect.Zero(field.Type))
+
+		ld := loader.Copy()
+		bitsOffset, refsOffset := ld.BitsOffset(), ld.RefsOffset()
+
+		magic, rest, hasMagic := candidateMagic(candidate)
+		if hasMagic {
+			ok, err := magic.match(ld)
+			if err != nil {
+				return fmt.Errorf("failed to load magic of %s: %w", field.Name, err)
+			}
 
-	if ld, ok := inf.(manualStore); ok {
+			if !ok {
+				errs = append(errs, fmt.Sprintf("%d: magic %s not matched", idx, magic))
+				continue
+			}
+			ld.MustLoadSlice(magic.sz)
+		}
+
+		if len(rest) > 0 {
+			if err := loadField(ctx, rv, i, rest, ld); err != nil {
+				errs = append(errs, fmt.Sprintf("%d: %s", idx, err.Error()))
+				continue
+			}
+		}
+
+		if variant != "" {
+			if f := variantField(rv, field.Name, variant); f.CanInt() {
+				f.SetInt(int64(idx))
+			} else {
+				f.SetUint(uint64(idx))
+			}
+		}
+
+		// skip in the original loader what candidate has consumed
+		if _, err := loader.LoadSlice(ld.BitsOffset() - bitsOffset); err != nil {
+			return fmt.Errorf("failed to skip bits of %s: %w", field.Name, err)
+		}
+		for j := refsOffset; j < ld.RefsOffset(); j++ {
+			if _, err := loader.LoadRef(); err != nil {
+				return fmt.Errorf("failed to skip refs of %s: %w", field.Name, err)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no candidate of %s is matched: %s", field.Name, strings.Join(errs, "; "))
+}
+
+// storeTryPrefix - chooses candidate of 'try(A; B)' tag by its variant field, or the first one,
+// stores its magic prefix and returns the rest settings of it
+func storeTryPrefix(rv reflect.Value, i int, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) ([]string, error) {
+	field := rv.Type().Field(i)
+	candidates, variant := tryCandidates(settings, field.Name)
+
+	idx := uint64(0)
+	if variant != "" {
+		f := variantField(rv, field.Name, variant)
+		if f.CanInt() {
+			if f.Int() < 0 {
+				return nil, fmt.Errorf("try variant %d of %s is out of range", f.Int(), field.Name)
+			}
+			idx = uint64(f.Int())
+		} else {
+			idx = f.Uint()
+		}
+
+		if idx >= uint64(len(candidates)) {
+			return nil, fmt.Errorf("try variant %d of %s is out of range", idx, field.Name)
+		}
+	}
+	audit.record(field.Name, fmt.Sprintf("try stored as '%s'", strings.Join(candidates[idx], " ")))
+
+	magic, rest, hasMagic := candidateMagic(candidates[idx])
+	if hasMagic {
+		if err := magic.store(builder); err != nil {
+			return nil, fmt.Errorf("failed to store magic of %s: %w", field.Name, err)
+		}
+	}
+	return rest, nil
+}
+
+// boolBits - returns width of 'bool N' tag
+func boolBits(fieldName, setting string) uint {
+	n, err := strconv.ParseUint(setting, 10, 64)
+	if err != nil || n == 0 || n > 64 {
+		// we panic, because its developer's issue, need to fix tag
+		panic(fmt.Sprintf("corrupted size in bool tag of '%s'", fieldName))
+	}
+	return uint(n)
+}
+
+// strBytes - returns size in bytes of 'str N' tag
+func strBytes(field reflect.StructField, settings []string) uint {
+	if field.Type.Kind() != reflect.String {
+		panic(fmt.Sprintf("str tag can be used only with string, field '%s'", field.Name))
+	}
+
+	if len(settings) < 2 {
+		panic(fmt.Sprintf("str tag of '%s' should have size", field.Name))
+	}
+
+	n, err := strconv.ParseUint(settings[1], 10, 8)
+	if err != nil || n == 0 || n > 127 {
+		// we panic, because its developer's issue, need to fix tag
+		panic(fmt.Sprintf("corrupted size in str tag of '%s'", field.Name))
+	}
+	return uint(n)
+}
+
+// padBits - returns number of bits to skip for 'pad N', 'skip N' or 'align N' tag at offset of the cell
+func padBits(fieldName string, settings []string, offset uint) uint {
+	if len(settings) < 2 {
+		panic(fmt.Sprintf("%s tag of '%s' should have size", settings[0], fieldName))
+	}
+
+	n, err := strconv.ParseUint(settings[1], 10, 16)
+	if err != nil || n == 0 {
+		// we panic, because its developer's issue, need to fix tag
+		panic(fmt.Sprintf("corrupted size in %s tag of '%s'", settings[0], fieldName))
+	}
+
+	if settings[0] != "align" {
+		return uint(n)
+	}
+	return (uint(n) - offset%uint(n)) % uint(n)
+}
+
+// splitTag - splits tag to settings, magic tag is kept whole because it can contain TL-B declaration
+func splitTag(field reflect.StructField, tag string) []string {
+	if field.Type == reflect.TypeOf(Magic{}) {
+		return []string{tag}
+	}
+	return strings.Split(tag, " ")
+}
+
+// expandAlias - expands shorthand in the first setting, uN and iN to '## N', bN to 'bits N', for example u32 or b256
+func expandAlias(settings []string) []string {
+	if len(settings) == 0 || len(settings[0]) < 2 {
+		return settings
+	}
+
+	var kind string
+	switch settings[0][0] {
+	case 'u', 'i':
+		kind = "##"
+	case 'b':
+		kind = "bits"
+	default:
+		return settings
+	}
+
+	num := settings[0][1:]
+	if _, err := strconv.ParseUint(num, 10, 16); err != nil {
+		return settings
+	}
+	return append([]string{kind, num}, settings[1:]...)
+}
+
+func isInlineOrRef(a, b string) bool {
+	return (a == "." && b == "^") || (a == "^" && b == ".")
+}
+
+// dictValueLoad - loads value of dict transformation to type typ, from ref if isRef
+func dictValueLoad(ctx context.Context, typ reflect.Type, value *cell.Cell, isRef bool) (reflect.Value, error) {
+	ld := value.BeginParse()
+	if isRef {
+		var err error
+		ld, err = ld.LoadRef()
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("failed to load ref in dict transform: %w", err)
+		}
+	}
+
+	if typ == reflect.TypeOf(&cell.Cell{}) {
 		c, err := ld.ToCell()
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("failed to convert value to cell in dict transform: %w", err)
+		}
+		return reflect.ValueOf(c), nil
+	}
+
+	nVal, err := structLoad(ctx, typ, ld)
+	if err != nil {
+		return reflect.Value{}, keepFieldError(err, fmt.Errorf("failed to load struct in dict transform: %w", err))
+	}
+
+	if err = checkConsumed(ctx, nVal, ld); err != nil {
+		return reflect.Value{}, fmt.Errorf("failed to load struct in dict transform: %w", err)
+	}
+	return nVal, nil
+}
+
+// loadHexBits - loads num bits to string field as lowercase hex
+func loadHexBits(loader *cell.Slice, fieldVal reflect.Value, num uint) error {
+	x, err := loader.LoadSlice(num)
+	if err != nil {
+		return fmt.Errorf("failed to load bits %d, err: %w", num, err)
+	}
+
+	fieldVal.SetString(hex.EncodeToString(x))
+	return nil
+}
+
+// storeHexBits - stores hex string as num bits, reverse of loadHexBits
+func storeHexBits(builder *cell.Builder, str string, num uint) error {
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		return fmt.Errorf("failed to store bits %d, value should be hex: %w", num, err)
+	}
+
+	if uint(len(data)) != (num+7)/8 {
+		return fmt.Errorf("failed to store bits %d, value should be %d bytes, got %d", num, (num+7)/8, len(data))
+	}
+
+	if err = builder.StoreSlice(data, num); err != nil {
+		return fmt.Errorf("failed to store bits %d, err: %w", num, err)
+	}
+	return nil
+}
+
+// dictOptions - options of dict transformation, which are going after its type, like 'dict 267 -> map addr ^'
+type dictOptions struct {
+	// ref - value is stored in ref
+	ref bool
+	// addr - string key is user-friendly form of address
+	addr bool
+	// sha256 - dict key is sha256 of string key, known names are resolved on load,
+	// unknown hashes are presented as '#' + hex and stored back as is
+	sha256 bool
+	names  map[string]string
+}
+
+func parseDictOptions(opts []string) dictOptions {
+	var res dictOptions
+	for _, opt := range opts {
+		switch opt {
+		case "^":
+			res.ref = true
+		case "addr":
+			res.addr = true
+		default:
+			if opt == "sha256" || strings.HasPrefix(opt, "sha256:") {
+				res.sha256 = true
+				if list := strings.TrimPrefix(opt, "sha256"); list != "" {
+					res.names = map[string]string{}
+					for _, name := range strings.Split(list[1:], ",") {
+						h := sha256.Sum256([]byte(name))
+						res.names[hex.EncodeToString(h[:])] = name
+					}
+				}
+				continue
+			}
+
+			// we panic, because its developer's issue, need to fix tag
+			panic("unknown dict transformation option " + opt)
+		}
+	}
+	return res
+}
+
+// dictKeyLoad - decodes dict key of sz bits to map key type, unsigned and signed integers,
+// *big.Int, *address.Address and string (hex, address with addr option, or name with sha256 option) are supported
+func dictKeyLoad(typ reflect.Type, key *cell.Cell, sz uint, opts dictOptions) (reflect.Value, error) {
+	ld := key.BeginParse()
+
+	if typ == reflect.TypeOf(&address.Address{}) || (opts.addr && typ.Kind() == reflect.String) {
+		addr, err := ld.LoadAddr()
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		if typ.Kind() == reflect.String {
+			str, err := addrToString(addr, nil)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(str).Convert(typ), nil
+		}
+		return reflect.ValueOf(addr), nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.Uint:
+		if sz > 64 {
+			panic("dict key is too big for map key type " + typ.String())
+		}
+
+		x, err := ld.LoadUInt(sz)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(x).Convert(typ), nil
+	case reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
+		if sz > 64 {
+			panic("dict key is too big for map key type " + typ.String())
+		}
+
+		x, err := ld.LoadInt(sz)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(x).Convert(typ), nil
+	case reflect.String:
+		x, err := ld.LoadSlice(sz)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		str := hex.EncodeToString(x)
+		if opts.sha256 {
+			if name, ok := opts.names[str]; ok {
+				str = name
+			} else {
+				str = "#" + str
+			}
+		}
+		return reflect.ValueOf(str).Convert(typ), nil
+	}
+
+	if typ == reflect.TypeOf(&big.Int{}) {
+		x, err := ld.LoadBigUInt(sz)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(x), nil
+	}
+
+	panic("map key type " + typ.String() + " is not supported for dict")
+}
+
+// dictFromValue - builds dictionary from map or slice of DictEntry, using key size and value options of the tag
+func dictFromValue(field reflect.StructField, fieldVal reflect.Value, settings []string, options storeOptions) (*cell.Dictionary, error) {
+	sz, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("cannot serialize field '%s' as dict, bad size '%s'", field.Name, settings[1]))
+	}
+	var opts dictOptions
+	if len(settings) >= 4 && settings[2] == "->" {
+		opts = parseDictOptions(settings[4:])
+	}
+
+	var keys, values []reflect.Value
+	switch {
+	case fieldVal.Kind() == reflect.Map:
+		iter := fieldVal.MapRange()
+		for iter.Next() {
+			keys = append(keys, iter.Key())
+			values = append(values, iter.Value())
+		}
+	case fieldVal.Kind() == reflect.Slice && field.Type.Elem().Implements(dictEntryType):
+		for i := 0; i < fieldVal.Len(); i++ {
+			keys = append(keys, fieldVal.Index(i).Field(0))
+			values = append(values, fieldVal.Index(i).Field(1))
+		}
+	default:
+		panic(fmt.Sprintf("cannot serialize field '%s' as dict, type should be *cell.Dictionary, map or slice of DictEntry", field.Name))
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	dict := cell.NewDict(uint(sz))
+	for i := range keys {
+		key, err := dictKeyStore(keys[i], uint(sz), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store key %v: %w", keys[i].Interface(), err)
+		}
+
+		value, err := dictValueStore(values[i], opts.ref, options)
+		if err != nil {
+			return nil, keepFieldError(err, fmt.Errorf("failed to store value of key %v: %w", keys[i].Interface(), err))
+		}
+
+		if err = dict.Set(key, value); err != nil {
+			return nil, fmt.Errorf("failed to set key %v: %w", keys[i].Interface(), err)
+		}
+	}
+	return dict, nil
+}
+
+// dictKeyStore - encodes map key to dict key of sz bits, reverse of dictKeyLoad
+func dictKeyStore(key reflect.Value, sz uint, opts dictOptions) (*cell.Cell, error) {
+	if key.Type() == reflect.TypeOf(&address.Address{}) || (opts.addr && key.Kind() == reflect.String) {
+		var addr *address.Address
+		if key.Kind() == reflect.String {
+			var err error
+			if addr, err = address.ParseAddr(key.String()); err != nil {
+				return nil, fmt.Errorf("key should be address: %w", err)
+			}
+		} else {
+			addr = key.Interface().(*address.Address)
+		}
+
+		b := cell.BeginCell()
+		if err := b.StoreAddr(addr); err != nil {
+			return nil, err
+		}
+
+		if b.BitsUsed() != sz {
+			return nil, fmt.Errorf("address key should be %d bits, got %d", sz, b.BitsUsed())
+		}
+		return b.EndCell(), nil
+	}
+
+	var x *big.Int
+	switch key.Kind() {
+	case reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.Uint:
+		x = new(big.Int).SetUint64(key.Uint())
+	case reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
+		x = big.NewInt(key.Int())
+
+		// signed key of sz bits is in range [-2^(sz-1), 2^(sz-1))
+		limit := new(big.Int).Lsh(big.NewInt(1), sz-1)
+		if x.Cmp(limit) >= 0 || x.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("key is too big for %d bits", sz)
+		}
+		return cell.BeginCell().MustStoreBigInt(x, sz).EndCell(), nil
+	case reflect.String:
+		if opts.sha256 {
+			if sz != 256 {
+				return nil, fmt.Errorf("sha256 key should be 256 bits, got %d", sz)
+			}
+
+			str := key.String()
+			if len(str) == 65 && str[0] == '#' {
+				data, err := hex.DecodeString(str[1:])
+				if err != nil {
+					return nil, fmt.Errorf("key hash should be hex: %w", err)
+				}
+				return cell.BeginCell().MustStoreSlice(data, sz).EndCell(), nil
+			}
+
+			h := sha256.Sum256([]byte(str))
+			return cell.BeginCell().MustStoreSlice(h[:], sz).EndCell(), nil
+		}
+
+		data, err := hex.DecodeString(key.String())
+		if err != nil {
+			return nil, fmt.Errorf("key should be hex: %w", err)
+		}
+
+		if uint(len(data)) != (sz+7)/8 {
+			return nil, fmt.Errorf("key should be %d bits", sz)
+		}
+		return cell.BeginCell().MustStoreSlice(data, sz).EndCell(), nil
+	default:
+		var ok bool
+		if x, ok = key.Interface().(*big.Int); !ok {
+			panic("map key type " + key.Type().String() + " is not supported for dict")
+		}
+	}
+
+	if x.Sign() < 0 || x.BitLen() > int(sz) {
+		return nil, fmt.Errorf("key is out of range for %d bits", sz)
+	}
+	return cell.BeginCell().MustStoreBigUInt(x, sz).EndCell(), nil
+}
+
+// dictValueStore - serializes dict value, reverse of dictValueLoad
+func dictValueStore(value reflect.Value, isRef bool, options storeOptions) (*cell.Cell, error) {
+	var c *cell.Cell
+	if value.Type() == reflect.TypeOf(&cell.Cell{}) {
+		c = value.Interface().(*cell.Cell)
+		if c == nil {
+			return nil, fmt.Errorf("value cell is nil")
+		}
+	} else {
+		var err error
+		c, err = structStore(value, value.Type().Name(), options, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if isRef {
+		return cell.BeginCell().MustStoreRef(c).EndCell(), nil
+	}
+	return c, nil
+}
+
+// parseRepeatTag - parses 'repeat N [elem tag]' tag, returns size of count and tag of elements, '.' by default
+func parseRepeatTag(settings []string, field reflect.StructField) (uint, []string) {
+	if field.Type.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("repeat tag can be used only with slice, field '%s'", field.Name))
+	}
+
+	if len(settings) < 2 {
+		panic("repeat tag should have size of count")
+	}
+
+	sz, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil || sz == 0 || sz > 64 {
+		panic("corrupted size of count in repeat tag")
+	}
+
+	if len(settings) == 2 {
+		return uint(sz), []string{"."}
+	}
+	return uint(sz), settings[2:]
+}
+
+// parseFlagsTag - parses size of 'flags N' tag and validates that typ is a struct of bools which fits into it
+func parseFlagsTag(settings []string, typ reflect.Type) uint {
+	if len(settings) < 2 {
+		panic("flags tag should have size")
+	}
+
+	num, err := strconv.ParseUint(settings[1], 10, 64)
+	if err != nil || num == 0 || num > 64 {
+		// we panic, because its developer's issue, need to fix tag
+		panic("corrupted num bits in flags tag")
+	}
+
+	if typ.Kind() != reflect.Struct || uint64(typ.NumField()) > num {
+		panic("flags tag field should be a struct with not more than " + settings[1] + " bool fields")
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).Type.Kind() != reflect.Bool {
+			panic("flags tag field " + typ.Field(i).Name + " should be bool")
+		}
+	}
+
+	return uint(num)
+}
+
+// addrToString - converts std or none address to user-friendly form, opts can be 'nobounce' and 'testnet'
+func addrToString(addr *address.Address, opts []string) (string, error) {
+	switch addr.Type() {
+	case address.NoneAddress:
+		return "", nil
+	case address.StdAddress:
+	default:
+		return "", fmt.Errorf("address of type %d cannot be represented as string", addr.Type())
+	}
+
+	addr.SetBounce(true)
+	for _, opt := range opts {
+		switch opt {
+		case "nobounce":
+			addr.SetBounce(false)
+		case "testnet":
+			addr.SetTestnetOnly(true)
+		default:
+			// we panic, because its developer's issue, need to fix tag
+			panic("unknown addr option " + opt)
+		}
+	}
+
+	return addr.String(), nil
+}
+
+// addrFromString - parses user-friendly address, empty string is addr_none
+func addrFromString(str string) (*address.Address, error) {
+	if str == "" {
+		return address.NewAddressNone(), nil
+	}
+	return address.ParseAddr(str)
+}
+
+// fieldCell - serializes value of the field which is stored using '.' or '^'
+func fieldCell(field reflect.StructField, fieldVal reflect.Value, options storeOptions, audit *auditor) (*cell.Cell, error) {
+	if field.Type == reflect.TypeOf(&cell.Cell{}) {
+		return fieldVal.Interface().(*cell.Cell), nil
+	}
+
+	if !fieldVal.CanInterface() && field.Anonymous && field.Type.Kind() == reflect.Struct {
+		// embedded struct of unexported type cannot be accessed as a whole, so we copy its fields
+		cp := reflect.New(field.Type).Elem()
+		copyFields(cp, fieldVal)
+		fieldVal = cp
+	}
+	return structStore(fieldVal, field.Type.Name(), options, audit.nested(field.Name))
+}
+
+// copyFields - copies values of all settable fields from src struct to dst
+func copyFields(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Field(i).CanSet() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+}
+
+func structLoad(ctx context.Context, field reflect.Type, loader *cell.Slice) (reflect.Value, error) {
+	ctx, err := enterDepth(ctx)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	newTyp := field
+	if newTyp.Kind() == reflect.Ptr {
+		newTyp = newTyp.Elem()
+	}
+
+	nVal := reflect.New(newTyp)
+	inf := nVal.Interface()
+
+	if load, ok := asUnmarshaler(inf); ok {
+		if optionsOf(ctx).NoPanic {
+			load = recoverLoader(load)
+		}
+
+		err = load(loader)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("failed to load from cell for %s, using manual loader, err: %w", newTyp.Name(), err)
+		}
+	} else {
+		err = loadFromCell(ctx, nVal.Interface(), loader, nil)
+		if err != nil {
+			return reflect.Value{}, keepFieldError(err, fmt.Errorf("failed to load from cell for %s, err: %w", newTyp.Name(), err))
+		}
+	}
+
+	if field.Kind() != reflect.Ptr {
+		nVal = nVal.Elem()
+	}
+
+	return nVal, nil
+}
+
+func structStore(field reflect.Value, name string, options storeOptions, audit *auditor) (*cell.Cell, error) {
+	inf := field.Interface()
+
+	if store, ok := asMarshaler(inf); ok {
+		c, err := store()
 		if err != nil {
 			return nil, fmt.Errorf("failed to store to cell for %s, using manual storer, err: %w", name, err)
 		}
 		return c, nil
 	}
 
-	c, err := ToCell(inf)
+	c, err := toCell(inf, options, audit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to store to cell for %s, err: %w", name, err)
+		return nil, keepFieldError(err, fmt.Errorf("failed to store to cell for %s, err: %w", name, err))
 	}
 	return c, nil
 }
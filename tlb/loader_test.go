@@ -2,8 +2,14 @@ package tlb
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/xssnick/tonutils-go/address"
 	"github.com/xssnick/tonutils-go/tvm/cell"
@@ -172,3 +178,1927 @@ func TestLoadFromCellTransform(t *testing.T) {
 		}
 	}
 }
+
+type testRemaining struct {
+	_       Magic      `tlb:"#abcd"`
+	QueryID uint64     `tlb:"## 64"`
+	Payload *cell.Cell `tlb:"remaining"`
+}
+
+type testRemainingSlice struct {
+	QueryID uint64      `tlb:"## 64"`
+	Payload *cell.Slice `tlb:"remaining"`
+}
+
+func TestLoadFromCellRemaining(t *testing.T) {
+	ref := cell.BeginCell().MustStoreUInt(0xAA, 8).EndCell()
+	a := cell.BeginCell().MustStoreUInt(0xABCD, 16).MustStoreUInt(777, 64).
+		MustStoreUInt(0xDEAD, 16).MustStoreBoolBit(true).MustStoreRef(ref).EndCell()
+
+	loader := a.BeginParse()
+
+	var x testRemaining
+	if err := LoadFromCell(&x, loader); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.QueryID != 777 {
+		t.Fatal("query id not eq")
+	}
+
+	if x.Payload.BitsSize() != 17 || x.Payload.RefsNum() != 1 {
+		t.Fatal("payload size not eq")
+	}
+
+	if loader.BitsLeft() != 0 || loader.RefsNum() != 0 {
+		t.Fatal("loader should be fully consumed")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	var xs testRemainingSlice
+	if err = LoadFromCell(&xs, cell.BeginCell().MustStoreUInt(1, 64).MustStoreUInt(0xDEAD, 16).EndCell().BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if xs.Payload.MustLoadUInt(16) != 0xDEAD {
+		t.Fatal("payload slice not eq")
+	}
+}
+
+type testRefs struct {
+	Flags uint8        `tlb:"## 8"`
+	Refs  []*cell.Cell `tlb:"refs"`
+}
+
+func TestLoadFromCellRefs(t *testing.T) {
+	r1 := cell.BeginCell().MustStoreUInt(1, 8).EndCell()
+	r2 := cell.BeginCell().MustStoreUInt(2, 8).EndCell()
+	a := cell.BeginCell().MustStoreUInt(7, 8).MustStoreRef(r1).MustStoreRef(r2).EndCell()
+
+	var x testRefs
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.Refs) != 2 {
+		t.Fatal("refs len not 2")
+	}
+
+	if !bytes.Equal(x.Refs[1].Hash(), r2.Hash()) {
+		t.Fatal("ref not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	if err = LoadFromCell(&x, cell.BeginCell().MustStoreUInt(7, 8).EndCell().BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.Refs) != 0 {
+		t.Fatal("refs should be empty")
+	}
+}
+
+type testFragment struct {
+	_       Magic            `tlb:"#0f8a7ea5"`
+	QueryID uint64           `tlb:"use:TestQueryID"`
+	Owner   *address.Address `tlb:"maybe use:TestAddr"`
+}
+
+func TestLoadFromCellFragment(t *testing.T) {
+	RegisterFragment("TestQueryID", "## 64")
+	RegisterFragment("TestAddr", "addr")
+
+	addr := address.MustParseAddr("EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N")
+	a := cell.BeginCell().MustStoreUInt(0x0f8a7ea5, 32).MustStoreUInt(17, 64).
+		MustStoreBoolBit(true).MustStoreAddr(addr).EndCell()
+
+	var x testFragment
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.QueryID != 17 || x.Owner.String() != addr.String() {
+		t.Fatal("fragment fields not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testMaybeCell struct {
+	Code *cell.Cell `tlb:"maybe ^"`
+	Data *cell.Cell `tlb:"maybe ^"`
+}
+
+func TestLoadFromCellMaybeRef(t *testing.T) {
+	data := cell.BeginCell().MustStoreUInt(0xBEEF, 16).EndCell()
+	a := cell.BeginCell().MustStoreMaybeRef(nil).MustStoreMaybeRef(data).EndCell()
+
+	x := testMaybeCell{
+		Code: cell.BeginCell().EndCell(), // must be reset by loader
+	}
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Code != nil {
+		t.Fatal("code should be nil")
+	}
+
+	if x.Data == nil || !bytes.Equal(x.Data.Hash(), data.Hash()) {
+		t.Fatal("data not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	c, err = ToCell(testMaybeCell{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.BitsSize() != 2 || c.RefsNum() != 0 {
+		t.Fatal("empty maybe refs should be stored as 2 zero bits")
+	}
+}
+
+type testMaybePresence struct {
+	HasFee bool   `tlb:"-"`
+	Fee    uint32 `tlb:"maybe:HasFee ## 32"`
+	HasVal bool   `tlb:"-"`
+	Val    uint8  `tlb:"maybe:HasVal ## 8"`
+}
+
+func TestLoadFromCellMaybePresence(t *testing.T) {
+	a := cell.BeginCell().MustStoreBoolBit(true).MustStoreUInt(0, 32).MustStoreBoolBit(false).EndCell()
+
+	x := testMaybePresence{Val: 7} // must be reset by loader
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !x.HasFee || x.Fee != 0 || x.HasVal || x.Val != 0 {
+		t.Fatal("incorrect presence", x)
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	// value is ignored when presence flag is false
+	c, err = ToCell(testMaybePresence{Fee: 5, HasVal: true, Val: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.BitsSize() != 10 {
+		t.Fatal("absent fee should be stored as single zero bit")
+	}
+}
+
+type testMaybeDefault struct {
+	Timeout uint32 `tlb:"maybe ## 32 default:100"`
+	HasMode bool   `tlb:"-"`
+	Mode    int8   `tlb:"maybe:HasMode ## 8 default:-1"`
+}
+
+func TestLoadFromCellMaybeDefault(t *testing.T) {
+	var x testMaybeDefault
+	if err := LoadFromCell(&x, cell.BeginCell().MustStoreUInt(0, 2).EndCell().BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Timeout != 100 || x.HasMode || x.Mode != -1 {
+		t.Fatal("defaults not set", x)
+	}
+
+	a := cell.BeginCell().MustStoreBoolBit(true).MustStoreUInt(0, 32).MustStoreBoolBit(true).MustStoreInt(3, 8).EndCell()
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Timeout != 0 || !x.HasMode || x.Mode != 3 {
+		t.Fatal("loaded values should be used", x)
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testEitherAuto struct {
+	Val  uint64     `tlb:"## 64"`
+	Body *cell.Cell `tlb:"either . ^ fit"`
+}
+
+func TestToCellEitherAuto(t *testing.T) {
+	small := cell.BeginCell().MustStoreUInt(0xAB, 8).EndCell()
+	c, err := ToCell(testEitherAuto{Val: 1, Body: small})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.BitsSize() != 64+1+8 || c.RefsNum() != 0 {
+		t.Fatal("small body should be stored inline")
+	}
+
+	big := cell.BeginCell().MustStoreSlice(make([]byte, 120), 960).EndCell()
+	c, err = ToCell(testEitherAuto{Val: 1, Body: big})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.BitsSize() != 64+1 || c.RefsNum() != 1 {
+		t.Fatal("big body should be stored as ref")
+	}
+
+	var x testEitherAuto
+	if err = LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(x.Body.Hash(), big.Hash()) {
+		t.Fatal("body not eq")
+	}
+
+	if _, err = ToCell(testEitherAuto{Val: 1}); err == nil {
+		t.Fatal("should fail on nil body")
+	}
+
+	// without fit option ref is always preferred
+	c, err = ToCell(struct {
+		Body *cell.Cell `tlb:"either . ^"`
+	}{Body: small})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.BitsSize() != 1 || c.RefsNum() != 1 {
+		t.Fatal("body should be stored as ref")
+	}
+}
+
+type testRepeatSigner struct {
+	Key  []byte `tlb:"bits 16"`
+	Flag bool   `tlb:"bool"`
+}
+
+type testRepeat struct {
+	Signers []testRepeatSigner `tlb:"repeat 8"`
+	Weights []uint32           `tlb:"repeat 4 ## 32"`
+	Refs    []*cell.Cell       `tlb:"repeat 2 ^"`
+}
+
+func TestLoadFromCellRepeat(t *testing.T) {
+	ref := cell.BeginCell().MustStoreUInt(0xCAFE, 16).EndCell()
+	a := cell.BeginCell().
+		MustStoreUInt(2, 8).
+		MustStoreUInt(0xAAAA, 16).MustStoreBoolBit(true).
+		MustStoreUInt(0xBBBB, 16).MustStoreBoolBit(false).
+		MustStoreUInt(1, 4).MustStoreUInt(77, 32).
+		MustStoreUInt(1, 2).MustStoreRef(ref).
+		EndCell()
+
+	var x testRepeat
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.Signers) != 2 || !bytes.Equal(x.Signers[1].Key, []byte{0xBB, 0xBB}) || !x.Signers[0].Flag || x.Signers[1].Flag {
+		t.Fatal("signers not eq", x.Signers)
+	}
+
+	if len(x.Weights) != 1 || x.Weights[0] != 77 {
+		t.Fatal("weights not eq", x.Weights)
+	}
+
+	if len(x.Refs) != 1 || !bytes.Equal(x.Refs[0].Hash(), ref.Hash()) {
+		t.Fatal("refs not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	if _, err = ToCell(testRepeat{Refs: make([]*cell.Cell, 4)}); err == nil {
+		t.Fatal("should fail when count not fits")
+	}
+}
+
+type testCondition struct {
+	Version  uint8  `tlb:"## 8"`
+	HasExtra bool   `tlb:"bool"`
+	Extra    uint32 `tlb:"if:HasExtra ## 32"`
+	V2Field  uint16 `tlb:"if:Version=2 ## 16"`
+}
+
+func TestLoadFromCellCondition(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(2, 8).MustStoreBoolBit(false).MustStoreUInt(0xBEEF, 16).EndCell()
+
+	x := testCondition{Extra: 5}
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Extra != 0 || x.V2Field != 0xBEEF {
+		t.Fatal("conditional fields not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	a = cell.BeginCell().MustStoreUInt(1, 8).MustStoreBoolBit(true).MustStoreUInt(777, 32).EndCell()
+	if err = LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Extra != 777 || x.V2Field != 0 {
+		t.Fatal("conditional fields not eq")
+	}
+
+	c, err = ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testConditionOps struct {
+	Version uint8  `tlb:"## 8"`
+	HasData bool   `tlb:"bool"`
+	Legacy  uint8  `tlb:"if:!HasData ## 8"`
+	New     uint16 `tlb:"if:Version>=3 ## 16"`
+	Old     uint16 `tlb:"if:Version<3 ## 16"`
+	NotTwo  uint8  `tlb:"if:Version!=2 ## 8"`
+}
+
+func TestLoadFromCellConditionOps(t *testing.T) {
+	var x testConditionOps
+	a := cell.BeginCell().MustStoreUInt(3, 8).MustStoreBoolBit(true).MustStoreUInt(0xAAAA, 16).MustStoreUInt(7, 8).EndCell()
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Legacy != 0 || x.New != 0xAAAA || x.Old != 0 || x.NotTwo != 7 {
+		t.Fatal("conditional fields not eq", x)
+	}
+
+	a = cell.BeginCell().MustStoreUInt(2, 8).MustStoreBoolBit(false).MustStoreUInt(5, 8).MustStoreUInt(0xBBBB, 16).EndCell()
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Legacy != 5 || x.New != 0 || x.Old != 0xBBBB || x.NotTwo != 0 {
+		t.Fatal("conditional fields not eq", x)
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testAssert struct {
+	_       Magic  `tlb:"#aa"`
+	Version uint8  `tlb:"## 8 assert:2"`
+	Val     uint32 `tlb:"## 32"`
+}
+
+func TestLoadFromCellAssert(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(0xAA, 8).MustStoreUInt(2, 8).MustStoreUInt(7, 32).EndCell()
+
+	var x testAssert
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Version != 2 || x.Val != 7 {
+		t.Fatal("values not eq")
+	}
+
+	bad := cell.BeginCell().MustStoreUInt(0xAA, 8).MustStoreUInt(3, 8).MustStoreUInt(7, 32).EndCell()
+	if err := LoadFromCell(&x, bad.BeginParse()); err == nil {
+		t.Fatal("assertion should fail")
+	}
+
+	c, err := ToCell(testAssert{Val: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("asserted value should be written on store")
+	}
+}
+
+type testUnionAny interface {
+	Amount() uint64
+}
+
+type testUnionA struct {
+	_   Magic  `tlb:"#01"`
+	Val uint64 `tlb:"## 32"`
+}
+
+type testUnionB struct {
+	_   Magic  `tlb:"#02"`
+	Val uint64 `tlb:"## 64"`
+}
+
+func (t *testUnionA) Amount() uint64 { return t.Val }
+func (t *testUnionB) Amount() uint64 { return t.Val }
+
+type testUnion struct {
+	Inline testUnionAny `tlb:"union TestUnionA TestUnionB"`
+	InRef  testUnionAny `tlb:"^ union TestUnionA TestUnionB"`
+}
+
+func TestLoadFromCellUnion(t *testing.T) {
+	Register("TestUnionA", testUnionA{})
+	Register("TestUnionB", testUnionB{})
+
+	a := cell.BeginCell().MustStoreUInt(0x02, 8).MustStoreUInt(123456789012, 64).
+		MustStoreRef(cell.BeginCell().MustStoreUInt(0x01, 8).MustStoreUInt(7, 32).EndCell()).EndCell()
+
+	var x testUnion
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := x.Inline.(*testUnionB); !ok || x.Inline.Amount() != 123456789012 {
+		t.Fatal("inline union not eq")
+	}
+
+	if _, ok := x.InRef.(*testUnionA); !ok || x.InRef.Amount() != 7 {
+		t.Fatal("ref union not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	err = LoadFromCell(&x, cell.BeginCell().MustStoreUInt(0x03, 8).EndCell().BeginParse())
+	if !errors.Is(err, ErrNoMatchingType) {
+		t.Fatal("should be no matching type error, got", err)
+	}
+}
+
+type testMarks struct {
+	Signature []byte     `tlb:"bits 512 mark:sig"`
+	Seqno     uint32     `tlb:"## 32"`
+	Body      *cell.Cell `tlb:"^ mark:body"`
+	Regions   Marks      `tlb:"-"`
+}
+
+func TestLoadFromCellMarks(t *testing.T) {
+	a := cell.BeginCell().MustStoreSlice(make([]byte, 64), 512).MustStoreUInt(3, 32).
+		MustStoreRef(cell.BeginCell().EndCell()).EndCell()
+
+	var x testMarks
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Regions["sig"] != (Region{BitsOffset: 0, Bits: 512}) {
+		t.Fatal("sig region not eq", x.Regions["sig"])
+	}
+
+	if x.Regions["body"] != (Region{BitsOffset: 544, Bits: 0, RefsOffset: 0, Refs: 1}) {
+		t.Fatal("body region not eq", x.Regions["body"])
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testEnumKind uint8
+
+func (k testEnumKind) EnumValues() []int64 {
+	return []int64{1, 2}
+}
+
+type testEnum struct {
+	Mode uint8        `tlb:"## 4 enum:0,1,3"`
+	Kind testEnumKind `tlb:"## 4 enum"`
+}
+
+func TestLoadFromCellEnum(t *testing.T) {
+	var x testEnum
+	if err := LoadFromCell(&x, cell.BeginCell().MustStoreUInt(3, 4).MustStoreUInt(2, 4).EndCell().BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Mode != 3 || x.Kind != 2 {
+		t.Fatal("enum values not eq")
+	}
+
+	if err := LoadFromCell(&x, cell.BeginCell().MustStoreUInt(2, 4).MustStoreUInt(2, 4).EndCell().BeginParse()); err == nil {
+		t.Fatal("should fail on unknown tag enum value")
+	}
+
+	if err := LoadFromCell(&x, cell.BeginCell().MustStoreUInt(1, 4).MustStoreUInt(5, 4).EndCell().BeginParse()); err == nil {
+		t.Fatal("should fail on unknown method enum value")
+	}
+
+	if _, err := ToCell(testEnum{Mode: 1, Kind: 7}); err == nil {
+		t.Fatal("should fail to store unknown enum value")
+	}
+}
+
+type testUnary struct {
+	N    uint32 `tlb:"unary"`
+	Zero uint8  `tlb:"unary"`
+	Tail uint8  `tlb:"## 4"`
+}
+
+func TestLoadFromCellUnary(t *testing.T) {
+	c := cell.BeginCell().MustStoreUInt(0b1110, 4).MustStoreUInt(0, 1).MustStoreUInt(9, 4).EndCell()
+
+	var x testUnary
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.N != 3 || x.Zero != 0 || x.Tail != 9 {
+		t.Fatal("unary values not eq")
+	}
+
+	a, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	if err = LoadFromCell(&x, cell.BeginCell().MustStoreUInt(0b111, 3).EndCell().BeginParse()); err == nil {
+		t.Fatal("should fail on unterminated unary")
+	}
+}
+
+type testAddrString struct {
+	Dst   string `tlb:"addr"`
+	Src   string `tlb:"addr nobounce testnet"`
+	Empty string `tlb:"addr"`
+}
+
+func TestLoadFromCellAddrString(t *testing.T) {
+	addr := address.MustParseAddr("EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N")
+	c := cell.BeginCell().MustStoreAddr(addr).MustStoreAddr(addr).MustStoreAddr(nil).EndCell()
+
+	var x testAddrString
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Dst != addr.String() || x.Empty != "" {
+		t.Fatal("addr not eq")
+	}
+
+	src := address.MustParseAddr(x.Src)
+	if src.IsBounceable() || !src.IsTestnetOnly() || !bytes.Equal(src.Data(), addr.Data()) {
+		t.Fatal("non bounceable testnet addr not eq")
+	}
+
+	a, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	if _, err = ToCell(testAddrString{Dst: "bad"}); err == nil {
+		t.Fatal("should fail on invalid address")
+	}
+}
+
+type testTimestamp struct {
+	CreatedAt  time.Time `tlb:"timestamp 32"`
+	ValidUntil time.Time `tlb:"timestamp 64"`
+	Empty      time.Time `tlb:"timestamp 32"`
+}
+
+func TestLoadFromCellTimestamp(t *testing.T) {
+	c := cell.BeginCell().MustStoreUInt(1660000000, 32).MustStoreUInt(1760000000, 64).MustStoreUInt(0, 32).EndCell()
+
+	var x testTimestamp
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.CreatedAt.Unix() != 1660000000 || x.ValidUntil.Unix() != 1760000000 || !x.Empty.IsZero() {
+		t.Fatal("timestamps not eq")
+	}
+
+	a, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	if _, err = ToCell(testTimestamp{CreatedAt: time.Unix(1<<33, 0)}); err == nil {
+		t.Fatal("should fail on too big timestamp")
+	}
+}
+
+type testFlags struct {
+	Flags struct {
+		Bounce   bool
+		Bounced  bool
+		Disabled bool
+	} `tlb:"flags 4"`
+	Tail uint8 `tlb:"## 4"`
+}
+
+func TestLoadFromCellFlags(t *testing.T) {
+	c := cell.BeginCell().MustStoreUInt(0b1010, 4).MustStoreUInt(5, 4).EndCell()
+
+	var x testFlags
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !x.Flags.Bounce || x.Flags.Bounced || !x.Flags.Disabled || x.Tail != 5 {
+		t.Fatal("flags not eq")
+	}
+
+	a, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testDictMap struct {
+	ByID   map[uint32]manualLoad   `tlb:"dict 32 -> map"`
+	ByHash map[string]*cell.Cell   `tlb:"dict 256 -> map ^"`
+	ByBig  map[*big.Int]manualLoad `tlb:"dict 128 -> map"`
+}
+
+func TestLoadFromCellDictMap(t *testing.T) {
+	ids := cell.NewDict(32)
+	for i := 0; i < 5; i++ {
+		_ = ids.SetIntKey(big.NewInt(int64(i*10)), cell.BeginCell().MustStoreUInt(uint64('a'+i), 8).EndCell())
+	}
+
+	val := cell.BeginCell().MustStoreUInt(777, 16).EndCell()
+	key := make([]byte, 32)
+	key[31] = 0xAB
+	hashes := cell.NewDict(256)
+	_ = hashes.Set(cell.BeginCell().MustStoreSlice(key, 256).EndCell(), cell.BeginCell().MustStoreRef(val).EndCell())
+
+	bigs := cell.NewDict(128)
+	_ = bigs.SetIntKey(new(big.Int).Lsh(big.NewInt(1), 100), cell.BeginCell().MustStoreUInt('z', 8).EndCell())
+
+	c := cell.BeginCell().MustStoreDict(ids).MustStoreDict(hashes).MustStoreDict(bigs).EndCell()
+
+	var x testDictMap
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.ByID) != 5 || x.ByID[20].Val != "c" {
+		t.Fatal("uint map not eq")
+	}
+
+	if v := x.ByHash[hex.EncodeToString(key)]; v == nil || !bytes.Equal(v.Hash(), val.Hash()) {
+		t.Fatal("string map not eq")
+	}
+
+	for k, v := range x.ByBig {
+		if k.Cmp(new(big.Int).Lsh(big.NewInt(1), 100)) != 0 || v.Val != "z" {
+			t.Fatal("big map not eq")
+		}
+	}
+
+	a, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testDictEntries struct {
+	Entries []DictEntry[uint16, manualLoad] `tlb:"dict 16 -> array"`
+}
+
+func TestToCellDictEntries(t *testing.T) {
+	x := testDictEntries{
+		Entries: []DictEntry[uint16, manualLoad]{{Key: 5, Value: manualLoad{Val: "x"}}, {Key: 700, Value: manualLoad{Val: "y"}}},
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var y testDictEntries
+	if err = LoadFromCell(&y, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(y.Entries) != 2 {
+		t.Fatal("entries len not eq")
+	}
+
+	for _, e := range y.Entries {
+		if (e.Key == 5 && e.Value.Val != "x") || (e.Key == 700 && e.Value.Val != "y") || (e.Key != 5 && e.Key != 700) {
+			t.Fatal("entries not eq", e)
+		}
+	}
+
+	if _, err = ToCell(testDictMap{ByHash: map[string]*cell.Cell{"zz": cell.BeginCell().EndCell()}}); err == nil {
+		t.Fatal("should fail on not hex key")
+	}
+}
+
+type testDictSigned struct {
+	Vals map[int16]*cell.Cell `tlb:"dict 8 -> map"`
+}
+
+func TestToCellDictSignedKeyRange(t *testing.T) {
+	val := cell.BeginCell().MustStoreUInt(1, 8).EndCell()
+
+	c, err := ToCell(testDictSigned{Vals: map[int16]*cell.Cell{-128: val, 127: val}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var x testDictSigned
+	if err = LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.Vals) != 2 || x.Vals[-128] == nil || x.Vals[127] == nil {
+		t.Fatal("boundary keys not eq", x.Vals)
+	}
+
+	for _, key := range []int16{128, -129} {
+		if _, err = ToCell(testDictSigned{Vals: map[int16]*cell.Cell{key: val}}); err == nil {
+			t.Fatal("should fail on key out of range", key)
+		}
+	}
+}
+
+type testPfxDict struct {
+	Routes *cell.PrefixDictionary `tlb:"pfxdict 32"`
+	Tail   uint8                  `tlb:"## 8"`
+}
+
+func TestLoadFromCellPfxDict(t *testing.T) {
+	d := cell.NewPrefixDict(32)
+	_ = d.Set(cell.BeginCell().MustStoreUInt(0b01, 2).EndCell(), cell.BeginCell().MustStoreUInt(1, 8).EndCell())
+	_ = d.Set(cell.BeginCell().MustStoreUInt(0b1, 1).EndCell(), cell.BeginCell().MustStoreUInt(2, 8).EndCell())
+
+	c := cell.BeginCell().MustStorePrefixDict(d).MustStoreUInt(9, 8).EndCell()
+
+	var x testPfxDict
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.Routes.All()) != 2 || x.Tail != 9 {
+		t.Fatal("prefix dict not eq")
+	}
+
+	a, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testAddrDict struct {
+	Holders  map[string]Coins           `tlb:"dict 267 -> map addr"`
+	Balances map[*address.Address]Coins `tlb:"dict 267 -> map"`
+}
+
+func TestLoadFromCellAddrDict(t *testing.T) {
+	addr := address.MustParseAddr("EQCD39VS5jcptHL8vMjEXrzGaRcCVYto7HUn4bpAOg8xqB2N")
+
+	d := cell.NewDict(267)
+	_ = d.Set(cell.BeginCell().MustStoreAddr(addr).EndCell(), cell.BeginCell().MustStoreBigCoins(big.NewInt(500)).EndCell())
+
+	c := cell.BeginCell().MustStoreDict(d).MustStoreDict(d).EndCell()
+
+	var x testAddrDict
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := x.Holders[addr.String()]; !ok || v.NanoTON().Uint64() != 500 {
+		t.Fatal("string address map not eq")
+	}
+
+	for k, v := range x.Balances {
+		if !bytes.Equal(k.Data(), addr.Data()) || v.NanoTON().Uint64() != 500 {
+			t.Fatal("address map not eq")
+		}
+	}
+
+	a, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testSha256Dict struct {
+	Records map[string]*cell.Cell `tlb:"dict 256 -> map sha256:wallet,site ^"`
+}
+
+func TestLoadFromCellSha256Dict(t *testing.T) {
+	wallet := sha256.Sum256([]byte("wallet"))
+	unknown := sha256.Sum256([]byte("storage"))
+
+	d := cell.NewDict(256)
+	_ = d.Set(cell.BeginCell().MustStoreSlice(wallet[:], 256).EndCell(), cell.BeginCell().MustStoreRef(cell.BeginCell().MustStoreUInt(1, 8).EndCell()).EndCell())
+	_ = d.Set(cell.BeginCell().MustStoreSlice(unknown[:], 256).EndCell(), cell.BeginCell().MustStoreRef(cell.BeginCell().MustStoreUInt(2, 8).EndCell()).EndCell())
+
+	c := cell.BeginCell().MustStoreDict(d).EndCell()
+
+	var x testSha256Dict
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.Records) != 2 || x.Records["wallet"] == nil || x.Records["#"+hex.EncodeToString(unknown[:])] == nil {
+		t.Fatal("records not eq", x.Records)
+	}
+
+	a, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testChainedInner struct {
+	Val   uint64 `tlb:"## 64"`
+	Flags uint8  `tlb:"## 2"`
+}
+
+type testChainedModifiers struct {
+	Nested  *cell.Cell        `tlb:"maybe maybe ^"`
+	Grouped uint32            `tlb:"either (## 32) (^ ## 32)"`
+	Inner   *testChainedInner `tlb:"maybe either (maybe .) ^"`
+}
+
+func TestLoadFromCellChainedModifiers(t *testing.T) {
+	ref := cell.BeginCell().MustStoreUInt(1, 8).EndCell()
+	inner := cell.BeginCell().MustStoreUInt(5, 64).MustStoreUInt(3, 2).EndCell()
+
+	a := cell.BeginCell().
+		MustStoreBoolBit(true).MustStoreMaybeRef(ref).
+		MustStoreBoolBit(true).MustStoreRef(cell.BeginCell().MustStoreUInt(777, 32).EndCell()).
+		MustStoreBoolBit(true).MustStoreBoolBit(true).MustStoreRef(inner).
+		EndCell()
+
+	var x testChainedModifiers
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Nested == nil || !bytes.Equal(x.Nested.Hash(), ref.Hash()) || x.Grouped != 777 || x.Inner == nil || x.Inner.Val != 5 {
+		t.Fatal("not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	b := cell.BeginCell().
+		MustStoreBoolBit(false).
+		MustStoreBoolBit(false).MustStoreUInt(5, 32).
+		MustStoreBoolBit(true).MustStoreBoolBit(false).MustStoreBoolBit(false).
+		EndCell()
+
+	if err = LoadFromCell(&x, b.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Nested != nil || x.Grouped != 5 || x.Inner != nil {
+		t.Fatal("not eq inline")
+	}
+}
+
+type testCellSnapshot struct {
+	Op      uint32     `tlb:"## 32"`
+	Payload *cell.Cell `tlb:"cell"`
+	QueryID uint64     `tlb:"## 64"`
+	Body    *cell.Cell `tlb:"^"`
+}
+
+func TestLoadFromCellSnapshot(t *testing.T) {
+	body := cell.BeginCell().MustStoreUInt(1, 8).EndCell()
+	payload := cell.BeginCell().MustStoreUInt(55, 64).MustStoreRef(body).EndCell()
+	a := cell.BeginCell().MustStoreUInt(0xaa, 32).MustStoreBuilder(payload.ToBuilder()).EndCell()
+
+	var x testCellSnapshot
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Op != 0xaa || x.QueryID != 55 || !bytes.Equal(x.Payload.Hash(), payload.Hash()) || !bytes.Equal(x.Body.Hash(), body.Hash()) {
+		t.Fatal("not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testMagicAlternatives struct {
+	_       Magic  `tlb:"#aabbccdd|#11223344 variant:Version"`
+	Version int    `tlb:"-"`
+	QueryID uint64 `tlb:"## 64"`
+}
+
+func TestLoadFromCellMagicAlternatives(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(0x11223344, 32).MustStoreUInt(9, 64).EndCell()
+
+	var x testMagicAlternatives
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Version != 1 || x.QueryID != 9 {
+		t.Fatal("not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	x.Version = 0
+	c, err = ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.BeginParse().MustLoadUInt(32) != 0xaabbccdd {
+		t.Fatal("incorrect first variant")
+	}
+
+	x.Version = 2
+	if _, err = ToCell(x); err == nil {
+		t.Fatal("out of range variant should fail")
+	}
+
+	if err = LoadFromCell(&x, cell.BeginCell().MustStoreUInt(0x55, 32).MustStoreUInt(9, 64).EndCell().BeginParse()); err == nil {
+		t.Fatal("unknown magic should fail")
+	}
+
+	desc, err := DescribeType(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if desc.Constructor.Value != 0xaabbccdd || len(desc.Constructor.Alternatives) != 1 || desc.Constructor.Alternatives[0].Value != 0x11223344 {
+		t.Fatal("incorrect description")
+	}
+}
+
+type testMagicValue struct {
+	Op      Magic  `tlb:"#aabbccdd|#11223344"`
+	QueryID uint64 `tlb:"## 64"`
+}
+
+func TestLoadFromCellMagicValue(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(0x11223344, 32).MustStoreUInt(9, 64).EndCell()
+
+	var x testMagicValue
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := x.Op.Value(); !ok || v != 0x11223344 || x.Op.Bits() != 32 {
+		t.Fatal("magic value not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	c, err = ToCell(testMagicValue{QueryID: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.BeginParse().MustLoadUInt(32) != 0xaabbccdd {
+		t.Fatal("zero magic should be stored as first in tag")
+	}
+
+	if _, err = ToCell(testMagicValue{Op: NewMagic(0x55, 32)}); err == nil {
+		t.Fatal("not accepted magic should fail")
+	}
+}
+
+type testMaybeInline struct {
+	Before uint8             `tlb:"## 8"`
+	Inner  *testChainedInner `tlb:"maybe ."`
+	After  uint8             `tlb:"## 8"`
+}
+
+func TestLoadFromCellMaybeInlinePointer(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(1, 8).
+		MustStoreBoolBit(true).MustStoreUInt(5, 64).MustStoreUInt(2, 2).
+		MustStoreUInt(3, 8).EndCell()
+
+	var x testMaybeInline
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Inner == nil || x.Inner.Val != 5 || x.Inner.Flags != 2 || x.Before != 1 || x.After != 3 {
+		t.Fatal("not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	b := cell.BeginCell().MustStoreUInt(1, 8).MustStoreBoolBit(false).MustStoreUInt(3, 8).EndCell()
+
+	// reused struct should not keep previous inner value
+	if err = LoadFromCell(&x, b.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Inner != nil || x.After != 3 {
+		t.Fatal("not eq without inner")
+	}
+
+	c, err = ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), b.Hash()) {
+		t.Fatal("cell hashes not same after From to without inner")
+	}
+
+	if _, err = ToCell(struct {
+		Inner *testChainedInner `tlb:"."`
+	}{}); err == nil {
+		t.Fatal("nil inline pointer without maybe should fail")
+	}
+}
+
+type testAliases struct {
+	Op      uint32 `tlb:"u32"`
+	Delta   int64  `tlb:"i64"`
+	Hash    []byte `tlb:"b256"`
+	Small   uint8  `tlb:"maybe u8"`
+	InRef   uint16 `tlb:"^ u16"`
+	Options uint32 `tlb:"either u8 (^ u32)"`
+}
+
+func TestLoadFromCellAliases(t *testing.T) {
+	h := bytes.Repeat([]byte{0xAB}, 32)
+	a := cell.BeginCell().MustStoreUInt(7, 32).MustStoreInt(-5, 64).MustStoreSlice(h, 256).
+		MustStoreBoolBit(true).MustStoreUInt(3, 8).
+		MustStoreRef(cell.BeginCell().MustStoreUInt(99, 16).EndCell()).
+		MustStoreBoolBit(true).MustStoreRef(cell.BeginCell().MustStoreUInt(1000, 32).EndCell()).
+		EndCell()
+
+	var x testAliases
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Op != 7 || x.Delta != -5 || !bytes.Equal(x.Hash, h) || x.Small != 3 || x.InRef != 99 || x.Options != 1000 {
+		t.Fatal("not eq", x)
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	desc, err := DescribeType(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if desc.Fields[0].Bits != 32 || desc.Fields[2].Bits != 256 {
+		t.Fatal("incorrect description")
+	}
+}
+
+type testTryLayouts struct {
+	Body   *testChainedInner `tlb:"try(#aabbccdd .; #ddccbbaa ^) variant:Layout"`
+	Layout int               `tlb:"-"`
+	Tail   uint8             `tlb:"## 8"`
+}
+
+func TestLoadFromCellTry(t *testing.T) {
+	inner := cell.BeginCell().MustStoreUInt(5, 64).MustStoreUInt(1, 2).EndCell()
+	a := cell.BeginCell().MustStoreUInt(0xddccbbaa, 32).MustStoreRef(inner).MustStoreUInt(7, 8).EndCell()
+
+	var x testTryLayouts
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Layout != 1 || x.Body == nil || x.Body.Val != 5 || x.Tail != 7 {
+		t.Fatal("not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	b := cell.BeginCell().MustStoreUInt(0xaabbccdd, 32).MustStoreBuilder(inner.ToBuilder()).MustStoreUInt(7, 8).EndCell()
+	if err = LoadFromCell(&x, b.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Layout != 0 || x.Body == nil || x.Body.Val != 5 || x.Tail != 7 {
+		t.Fatal("not eq inline")
+	}
+
+	if err = LoadFromCell(&x, cell.BeginCell().MustStoreUInt(0x11, 32).EndCell().BeginParse()); err == nil {
+		t.Fatal("unknown layout should fail")
+	}
+}
+
+type testPadding struct {
+	Flag  bool     `tlb:"bool"`
+	_     struct{} `tlb:"align 8"`
+	Value uint16   `tlb:"## 16"`
+	_     struct{} `tlb:"pad 4"`
+	Tail  uint8    `tlb:"## 4"`
+}
+
+func TestLoadFromCellPadding(t *testing.T) {
+	a := cell.BeginCell().MustStoreBoolBit(true).MustStoreUInt(0, 7).MustStoreUInt(500, 16).MustStoreUInt(0, 4).MustStoreUInt(5, 4).EndCell()
+
+	var x testPadding
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !x.Flag || x.Value != 500 || x.Tail != 5 {
+		t.Fatal("not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testFixedString struct {
+	Ticker string `tlb:"str 8"`
+	Amount uint32 `tlb:"## 32"`
+}
+
+func TestLoadFromCellFixedString(t *testing.T) {
+	a := cell.BeginCell().MustStoreSlice([]byte{'U', 'S', 'D', 'T', 0, 0, 0, 0}, 64).MustStoreUInt(10, 32).EndCell()
+
+	var x testFixedString
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Ticker != "USDT" || x.Amount != 10 {
+		t.Fatal("not eq", x.Ticker)
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	x.Ticker = "TOO_LONG_TICKER"
+	if _, err = ToCell(x); err == nil {
+		t.Fatal("long string should fail")
+	}
+
+	bad := cell.BeginCell().MustStoreSlice([]byte{0xff, 0xfe, 0, 0, 0, 0, 0, 0}, 64).MustStoreUInt(10, 32).EndCell()
+	if err = LoadFromCell(&x, bad.BeginParse()); err == nil {
+		t.Fatal("invalid utf-8 should fail")
+	}
+}
+
+type testWideBool struct {
+	Active bool  `tlb:"bool 8"`
+	Flag   bool  `tlb:"bool"`
+	Tail   uint8 `tlb:"## 8"`
+}
+
+func TestLoadFromCellWideBool(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(1, 8).MustStoreBoolBit(false).MustStoreUInt(4, 8).EndCell()
+
+	var x testWideBool
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !x.Active || x.Flag || x.Tail != 4 {
+		t.Fatal("not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	bad := cell.BeginCell().MustStoreUInt(2, 8).MustStoreBoolBit(false).MustStoreUInt(4, 8).EndCell()
+	if err = LoadFromCell(&x, bad.BeginParse()); err == nil {
+		t.Fatal("value 2 should fail")
+	}
+}
+
+type testGroups struct {
+	Seqno     uint32     `tlb:"## 32 group:state"`
+	PublicKey []byte     `tlb:"bits 256 group:state,keys"`
+	Code      *cell.Cell `tlb:"^"`
+}
+
+func TestToCellGroups(t *testing.T) {
+	key := bytes.Repeat([]byte{0x01}, 32)
+	code := cell.BeginCell().MustStoreUInt(1, 8).EndCell()
+	x := testGroups{Seqno: 3, PublicKey: key, Code: code}
+
+	full, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := cell.BeginCell().MustStoreUInt(3, 32).MustStoreSlice(key, 256).MustStoreRef(code).EndCell()
+	if !bytes.Equal(full.Hash(), exp.Hash()) {
+		t.Fatal("full cell not eq")
+	}
+
+	var y testGroups
+	if err = LoadFromCell(&y, full.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if y.Seqno != 3 {
+		t.Fatal("not eq after load")
+	}
+
+	state, err := ToCellGroups(x, "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp = cell.BeginCell().MustStoreUInt(3, 32).MustStoreSlice(key, 256).EndCell()
+	if !bytes.Equal(state.Hash(), exp.Hash()) {
+		t.Fatal("state cell not eq")
+	}
+
+	keys, err := ToCellGroups(x, "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp = cell.BeginCell().MustStoreSlice(key, 256).EndCell()
+	if !bytes.Equal(keys.Hash(), exp.Hash()) {
+		t.Fatal("keys cell not eq")
+	}
+}
+
+type testSkipReserved struct {
+	Mode  uint8    `tlb:"skip 3 ## 5"`
+	_     struct{} `tlb:"skip 8"`
+	Value uint16   `tlb:"## 16"`
+}
+
+func TestLoadFromCellSkip(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(0, 3).MustStoreUInt(17, 5).MustStoreUInt(0, 8).MustStoreUInt(300, 16).EndCell()
+
+	var x testSkipReserved
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Mode != 17 || x.Value != 300 {
+		t.Fatal("not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testCrcTransfer struct {
+	_       Magic  `tlb:"crc transfer#_ query_id:uint64 amount:(VarUInteger 16) destination:MsgAddress response_destination:MsgAddress custom_payload:(Maybe ^Cell) forward_ton_amount:(VarUInteger 16) forward_payload:(Either Cell ^Cell) = InternalMsgBody;"`
+	QueryID uint64 `tlb:"## 64"`
+}
+
+func TestLoadFromCellCrcMagic(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(0x0f8a7ea5, 32).MustStoreUInt(7, 64).EndCell()
+
+	var x testCrcTransfer
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.QueryID != 7 {
+		t.Fatal("query id not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	if crcMagic("transfer query_id:uint64 amount:VarUInteger 16 destination:MsgAddress response_destination:MsgAddress custom_payload:Maybe ^Cell forward_ton_amount:VarUInteger 16 forward_payload:Either Cell ^Cell = InternalMsgBody") != 0x0f8a7ea5 {
+		t.Fatal("incorrect crc of normalized declaration")
+	}
+}
+
+type testBitsBigInt struct {
+	Hash *big.Int `tlb:"bits 256"`
+}
+
+func TestLoadFromCellBitsBigInt(t *testing.T) {
+	h := bytes.Repeat([]byte{0xFF}, 32)
+	a := cell.BeginCell().MustStoreSlice(h, 256).EndCell()
+
+	var x testBitsBigInt
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Hash.Sign() <= 0 || x.Hash.Cmp(new(big.Int).SetBytes(h)) != 0 {
+		t.Fatal("hash not eq", x.Hash)
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+type testHexBits struct {
+	Hash    string `tlb:"bits 256"`
+	BigHash string `tlb:"## 128"`
+}
+
+func TestLoadFromCellHexBits(t *testing.T) {
+	h := bytes.Repeat([]byte{0xAB}, 32)
+	a := cell.BeginCell().MustStoreSlice(h, 256).MustStoreSlice(h[:16], 128).EndCell()
+
+	var x testHexBits
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Hash != hex.EncodeToString(h) || x.BigHash != hex.EncodeToString(h[:16]) {
+		t.Fatal("hex not eq", x.Hash, x.BigHash)
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	x.Hash = "ab"
+	if _, err = ToCell(x); err == nil {
+		t.Fatal("short hex should fail")
+	}
+}
+
+type testHash struct {
+	Hash Bits256 `tlb:"hash"`
+	Seq  uint32  `tlb:"## 32"`
+}
+
+func TestLoadFromCellHash(t *testing.T) {
+	h := bytes.Repeat([]byte{0xAB}, 32)
+	a := cell.BeginCell().MustStoreSlice(h, 256).MustStoreUInt(5, 32).EndCell()
+
+	var x testHash
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(x.Hash[:], h) || x.Seq != 5 {
+		t.Fatal("hash not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	data, err := json.Marshal(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != `{"Hash":"`+hex.EncodeToString(h)+`","Seq":5}` {
+		t.Fatal("incorrect json", string(data))
+	}
+
+	var y testHash
+	if err = json.Unmarshal(data, &y); err != nil {
+		t.Fatal(err)
+	}
+
+	if !y.Hash.Equal(x.Hash) || y.Hash.Equal(Bits256{}) {
+		t.Fatal("hash not eq after json")
+	}
+}
+
+type testLongMagic struct {
+	_   Magic  `tlb:"#0123456789abcdef0011223344556677f"`
+	Val uint16 `tlb:"## 16"`
+}
+
+func TestLoadFromCellLongMagic(t *testing.T) {
+	a := cell.BeginCell().
+		MustStoreUInt(0x0123456789abcdef, 64).MustStoreUInt(0x0011223344556677, 64).MustStoreUInt(0xf, 4).
+		MustStoreUInt(0xBEEF, 16).EndCell()
+
+	var x testLongMagic
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Val != 0xBEEF {
+		t.Fatal("val not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	Register("TestLongMagic", testLongMagic{})
+	v, err := LoadAny(a.BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := v.(*testLongMagic); !ok {
+		t.Fatal("long magic type should be chosen")
+	}
+
+	b := cell.BeginCell().
+		MustStoreUInt(0x0123456789abcdef, 64).MustStoreUInt(0x0011223344556677, 64).MustStoreUInt(0xe, 4).
+		MustStoreUInt(0xBEEF, 16).EndCell()
+	if err = LoadFromCell(&x, b.BeginParse()); err == nil {
+		t.Fatal("should fail on different tail of magic")
+	}
+}
+
+type testQueryHeader struct {
+	_       Magic  `tlb:"#5fcc3d14"`
+	QueryID uint64 `tlb:"## 64"`
+}
+
+type TestQueryBody struct {
+	Amount uint32 `tlb:"## 32"`
+}
+
+type testEmbedded struct {
+	testQueryHeader
+	*TestQueryBody
+	Flag bool `tlb:"bool"`
+}
+
+type TestExportedHeader struct {
+	Seqno uint32 `tlb:"## 32"`
+}
+
+type testEmbeddedExported struct {
+	TestExportedHeader
+	Flag bool `tlb:"bool"`
+}
+
+func TestLoadFromCellEmbedded(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(0x5fcc3d14, 32).MustStoreUInt(99, 64).MustStoreUInt(5, 32).MustStoreBoolBit(true).EndCell()
+
+	var x testEmbedded
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.QueryID != 99 || x.TestQueryBody == nil || x.Amount != 5 || !x.Flag {
+		t.Fatal("embedded fields not eq", x)
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	b := cell.BeginCell().MustStoreUInt(7, 32).MustStoreBoolBit(true).EndCell()
+
+	var y testEmbeddedExported
+	if err = LoadFromCell(&y, b.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if y.Seqno != 7 || !y.Flag {
+		t.Fatal("exported embedded fields not eq", y)
+	}
+
+	c, err = ToCell(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), b.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+func TestLoadFromCellContext(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(2, 8).
+		MustStoreUInt(0xAAAA, 16).MustStoreBoolBit(true).
+		MustStoreUInt(0xBBBB, 16).MustStoreBoolBit(false).
+		MustStoreUInt(0, 4).MustStoreUInt(0, 2).EndCell()
+
+	var x testRepeat
+	if err := LoadFromCellContext(context.Background(), &x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.Signers) != 2 {
+		t.Fatal("signers not loaded")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err := LoadFromCellContext(ctx, &x, a.BeginParse())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("should fail with deadline, got", err)
+	}
+}
+
+type testChunked struct {
+	Data []byte `tlb:"chunked"`
+}
+
+func TestLoadFromCellChunked(t *testing.T) {
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	c, err := ToCell(testChunked{Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dict := c.BeginParse().MustLoadDict(32)
+	if len(dict.All()) != 3 {
+		t.Fatal("data should be split to 3 chunks")
+	}
+
+	var x testChunked
+	if err = LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(x.Data, data) {
+		t.Fatal("data not eq")
+	}
+
+	c, err = ToCell(testChunked{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.BitsSize() != 1 || c.RefsNum() != 0 {
+		t.Fatal("empty data should be stored as empty dict")
+	}
+}
+
+type testEitherBranch struct {
+	BodyInRef bool       `tlb:"-"`
+	Body      *cell.Cell `tlb:"either:BodyInRef . ^"`
+	HasExtra  bool       `tlb:"-"`
+	ExtraRef  bool       `tlb:"-"`
+	Extra     *cell.Cell `tlb:"maybe:HasExtra either:ExtraRef . ^"`
+}
+
+func TestLoadFromCellEitherBranch(t *testing.T) {
+	small := cell.BeginCell().MustStoreUInt(0xAB, 8).EndCell()
+	a := cell.BeginCell().MustStoreBoolBit(true).MustStoreRef(small).
+		MustStoreBoolBit(true).MustStoreBoolBit(false).MustStoreUInt(9, 32).EndCell()
+	extra := cell.BeginCell().MustStoreUInt(9, 32).EndCell()
+
+	var x testEitherBranch
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !x.BodyInRef || !bytes.Equal(x.Body.Hash(), small.Hash()) || !x.HasExtra || x.ExtraRef || !bytes.Equal(x.Extra.Hash(), extra.Hash()) {
+		t.Fatal("incorrect values", x)
+	}
+
+	// small body would be stored inline by plain either, but chosen branch is kept
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+func TestLoadStoreGeneric(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(77, 64).MustStoreUInt(2, 2).EndCell()
+
+	x, err := Load[testChainedInner](a.BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Val != 77 || x.Flags != 2 {
+		t.Fatal("incorrect values", x)
+	}
+
+	c, err := Store(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	// manual loader and storer are used for types which implement them
+	b := cell.BeginCell().MustStoreBigCoins(big.NewInt(700)).EndCell()
+	coins, err := Load[*Coins](b.BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if coins.NanoTON().Uint64() != 700 {
+		t.Fatal("incorrect coins", coins)
+	}
+
+	if c, err = Store(coins); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), b.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	if _, err = Load[testChainedInner](cell.BeginCell().EndCell().BeginParse()); err == nil {
+		t.Fatal("should fail on empty cell")
+	}
+}
+
+type testMarshaler struct {
+	Val uint16
+}
+
+func (m *testMarshaler) UnmarshalTLB(loader *cell.Slice) error {
+	v, err := loader.LoadUInt(16)
+	if err != nil {
+		return err
+	}
+	m.Val = uint16(v) ^ 0xFFFF
+	return nil
+}
+
+func (m *testMarshaler) MarshalTLB() (*cell.Cell, error) {
+	return cell.BeginCell().MustStoreUInt(uint64(m.Val^0xFFFF), 16).EndCell(), nil
+}
+
+type testMarshalerOuter struct {
+	Inner *testMarshaler           `tlb:"^"`
+	Items map[uint8]*testMarshaler `tlb:"dict 8 -> map ^"`
+}
+
+func TestLoadFromCellMarshaler(t *testing.T) {
+	d := cell.NewDict(8)
+	if err := d.SetIntKey(big.NewInt(3), cell.BeginCell().MustStoreRef(cell.BeginCell().MustStoreUInt(0xFFF0, 16).EndCell()).EndCell()); err != nil {
+		t.Fatal(err)
+	}
+
+	a := cell.BeginCell().MustStoreRef(cell.BeginCell().MustStoreUInt(0xFF00, 16).EndCell()).MustStoreDict(d).EndCell()
+
+	var x testMarshalerOuter
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Inner.Val != 0xFF || len(x.Items) != 1 || x.Items[3].Val != 0xF {
+		t.Fatal("incorrect values", x.Inner, x.Items)
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+}
+
+func TestStoreToBuilder(t *testing.T) {
+	x := testChainedInner{Val: 77, Flags: 2}
+
+	b := cell.BeginCell().MustStoreUInt(0xAB, 8)
+	if err := StoreToBuilder(x, b); err != nil {
+		t.Fatal(err)
+	}
+
+	a := cell.BeginCell().MustStoreUInt(0xAB, 8).MustStoreUInt(77, 64).MustStoreUInt(2, 2).EndCell()
+	if !bytes.Equal(b.EndCell().Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same")
+	}
+
+	// not enough space in builder
+	b = cell.BeginCell().MustStoreSlice(make([]byte, 120), 960)
+	if err := StoreToBuilder(&x, b); err == nil {
+		t.Fatal("should fail on full builder")
+	}
+}
+
+func TestPeekFromCell(t *testing.T) {
+	a := cell.BeginCell().MustStoreUInt(77, 64).MustStoreUInt(2, 2).EndCell()
+	loader := a.BeginParse()
+
+	var x testChainedInner
+	if err := PeekFromCell(&x, loader); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Val != 77 || x.Flags != 2 {
+		t.Fatal("incorrect values", x)
+	}
+
+	if loader.BitsLeft() != 66 {
+		t.Fatal("loader should not be moved", loader.BitsLeft())
+	}
+}
@@ -0,0 +1,232 @@
+package tlb
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// magicBits - magic value of any length, split to 64 bits chunks, last chunk keeps the rest
+type magicBits struct {
+	chunks []uint64
+	sz     uint
+}
+
+// chunkSize - returns size in bits of chunk i
+func (m magicBits) chunkSize(i int) uint {
+	if i == len(m.chunks)-1 && m.sz%64 != 0 {
+		return m.sz % 64
+	}
+	return 64
+}
+
+// match - checks that loader starts with magic, without consuming it
+func (m magicBits) match(loader *cell.Slice) (bool, error) {
+	if loader.BitsLeft() < m.sz {
+		return false, nil
+	}
+
+	ld := loader.Copy()
+	for i, chunk := range m.chunks {
+		v, err := ld.LoadUInt(m.chunkSize(i))
+		if err != nil {
+			return false, fmt.Errorf("failed to peek magic: %w", err)
+		}
+
+		if v != chunk {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (m magicBits) store(builder *cell.Builder) error {
+	for i, chunk := range m.chunks {
+		if err := builder.StoreUInt(chunk, m.chunkSize(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uint64 - returns magic as number, if it fits
+func (m magicBits) uint64() (uint64, bool) {
+	if len(m.chunks) != 1 {
+		return 0, false
+	}
+	return m.chunks[0], true
+}
+
+func (m magicBits) equal(o magicBits) bool {
+	if m.sz != o.sz || len(m.chunks) != len(o.chunks) {
+		return false
+	}
+
+	for i := range m.chunks {
+		if m.chunks[i] != o.chunks[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (m magicBits) String() string {
+	var sb strings.Builder
+	for i, chunk := range m.chunks {
+		sb.WriteString(fmt.Sprintf("%x", chunk))
+		if i < len(m.chunks)-1 {
+			sb.WriteString(":")
+		}
+	}
+	return sb.String()
+}
+
+// parseMagic - parses magic tag in [#]HEX or [$]BIN format of any length, or computes it from TL-B declaration
+// in 'crc ...' format
+func parseMagic(tag string) magicBits {
+	if strings.HasPrefix(tag, "crc ") {
+		return magicBits{chunks: []uint64{uint64(crcMagic(tag[len("crc "):]))}, sz: 32}
+	}
+
+	var bits string
+	if strings.HasPrefix(tag, "#") {
+		var sb strings.Builder
+		for _, c := range tag[1:] {
+			v, err := strconv.ParseUint(string(c), 16, 8)
+			if err != nil {
+				panic("corrupted magic value in tag")
+			}
+			sb.WriteString(fmt.Sprintf("%04b", v))
+		}
+		bits = sb.String()
+	} else if strings.HasPrefix(tag, "$") {
+		bits = tag[1:]
+	} else {
+		panic("unknown magic value type in tag")
+	}
+
+	if len(bits) == 0 {
+		panic("corrupted magic value in tag")
+	}
+
+	m := magicBits{sz: uint(len(bits))}
+	for len(bits) > 0 {
+		n := 64
+		if len(bits) < n {
+			n = len(bits)
+		}
+
+		v, err := strconv.ParseUint(bits[:n], 2, 64)
+		if err != nil {
+			panic("corrupted magic value in tag")
+		}
+		m.chunks = append(m.chunks, v)
+		bits = bits[n:]
+	}
+	return m
+}
+
+// magicAlternatives - splits magic tag to accepted alternatives, like '#aabbccdd|#11223344',
+// and returns name of the field referenced by optional 'variant:Field' suffix
+func magicAlternatives(tag string) ([]string, string) {
+	var variant string
+	if idx := strings.LastIndex(tag, " variant:"); idx >= 0 && !strings.Contains(tag[idx+1:], " ") {
+		variant = tag[idx+len(" variant:"):]
+		tag = tag[:idx]
+	}
+	return strings.Split(tag, "|"), variant
+}
+
+// parseMagics - parses all alternatives of magic tag, first one is used on store by default
+func parseMagics(tag string) []magicBits {
+	alts, _ := magicAlternatives(tag)
+
+	res := make([]magicBits, 0, len(alts))
+	for _, alt := range alts {
+		res = append(res, parseMagic(alt))
+	}
+	return res
+}
+
+// magicVariant - returns alternative of magic tag of field i, chosen by value of Magic field,
+// or by value of its variant field, or the first one
+func magicVariant(rv reflect.Value, i int, tag string) (string, error) {
+	fieldName := rv.Type().Field(i).Name
+	alts, variant := magicAlternatives(tag)
+
+	if f := rv.Field(i); f.CanInterface() {
+		if m := f.Interface().(Magic); m.bits != nil {
+			for _, alt := range alts {
+				if parseMagic(alt).equal(*m.bits) {
+					return alt, nil
+				}
+			}
+			return "", fmt.Errorf("magic %s of %s is not accepted by its tag", m.bits, fieldName)
+		}
+	}
+
+	if variant == "" {
+		return alts[0], nil
+	}
+
+	idx := variantField(rv, fieldName, variant)
+	var n uint64
+	if idx.CanInt() {
+		if idx.Int() < 0 {
+			return "", fmt.Errorf("magic variant %d of %s is out of range", idx.Int(), fieldName)
+		}
+		n = uint64(idx.Int())
+	} else {
+		n = idx.Uint()
+	}
+
+	if n >= uint64(len(alts)) {
+		return "", fmt.Errorf("magic variant %d of %s is out of range", n, fieldName)
+	}
+	return alts[n], nil
+}
+
+// variantField - returns integer field referenced by 'variant:Field' of magic tag
+func variantField(rv reflect.Value, fieldName, name string) reflect.Value {
+	f := rv.FieldByName(name)
+	if !f.IsValid() || !(f.CanInt() || f.CanUint()) {
+		// we panic, because its developer's issue, need to fix tag
+		panic(fmt.Sprintf("field '%s' referenced in tag of '%s' should exist and be integer", name, fieldName))
+	}
+	return f
+}
+
+// NewMagic - returns Magic with constructor of sz bits, it can be assigned to named Magic field to choose
+// which of alternatives of its tag will be stored
+func NewMagic(value uint64, sz uint) Magic {
+	if sz == 0 || sz > 64 || (sz < 64 && value>>sz != 0) {
+		panic("magic value does not fit into size")
+	}
+	return Magic{bits: &magicBits{chunks: []uint64{value}, sz: sz}}
+}
+
+// Value - returns constructor number and true, if it fits into 64 bits and Magic is not zero
+func (m Magic) Value() (uint64, bool) {
+	if m.bits == nil {
+		return 0, false
+	}
+	return m.bits.uint64()
+}
+
+// Bits - returns size of constructor in bits, 0 for zero Magic
+func (m Magic) Bits() uint {
+	if m.bits == nil {
+		return 0
+	}
+	return m.bits.sz
+}
+
+func (m Magic) String() string {
+	if m.bits == nil {
+		return ""
+	}
+	return m.bits.String()
+}
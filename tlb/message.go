@@ -1,6 +1,7 @@
 package tlb
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -66,6 +67,15 @@ type ExternalMessageOut struct {
 	Body      *cell.Cell `tlb:"either . ^"`
 }
 
+// BouncedBody - body of bounced internal message, it starts with 0xffffffff followed by the beginning (up to 256 bits)
+// of the original body, when CapFullBodyInBounced is enabled in the network, the whole original body is also stored to ref.
+// Capabilities should be passed with WithCapabilities to LoadFromCellContext and ToCellContext, to select the layout
+type BouncedBody struct {
+	_            Magic      `tlb:"#ffffffff"`
+	OriginalBody *cell.Cell `tlb:"cap:0x10000 ^"`
+	Truncated    *cell.Cell `tlb:"remaining"`
+}
+
 func (m *InternalMessage) Payload() *cell.Cell {
 	return m.Body
 }
@@ -89,6 +99,29 @@ func (m *InternalMessage) Comment() string {
 	return ""
 }
 
+// Extra - returns parsed extra currencies attached to message
+func (m *InternalMessage) Extra() (ExtraCurrencyCollection, error) {
+	return ExtraCurrenciesFromDict(m.ExtraCurrencies)
+}
+
+// LoadBouncedBody - decodes body of bounced message, capabilities of the network
+// should be passed with WithCapabilities to ctx, to select its layout
+func (m *InternalMessage) LoadBouncedBody(ctx context.Context) (*BouncedBody, error) {
+	if !m.Bounced {
+		return nil, errors.New("message is not bounced")
+	}
+
+	if m.Body == nil {
+		return nil, errors.New("bounced message has no body")
+	}
+
+	var body BouncedBody
+	if err := LoadFromCellContext(ctx, &body, m.Body.BeginParse()); err != nil {
+		return nil, fmt.Errorf("failed to load bounced body: %w", err)
+	}
+	return &body, nil
+}
+
 func (m *ExternalMessage) Payload() *cell.Cell {
 	return m.Body
 }
@@ -187,11 +220,7 @@ func (m *InternalMessage) ToCell() (*cell.Cell, error) {
 	b.MustStoreAddr(m.DstAddr)
 	b.MustStoreBigCoins(m.Amount.NanoTON())
 
-	if m.ExtraCurrencies != nil {
-		return nil, errors.New("extra currencies serialization is not supported yet")
-	}
-
-	b.MustStoreBoolBit(m.ExtraCurrencies != nil)
+	b.MustStoreDict(m.ExtraCurrencies)
 
 	b.MustStoreBigCoins(m.IHRFee.NanoTON())
 	b.MustStoreBigCoins(m.FwdFee.NanoTON())
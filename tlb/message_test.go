@@ -1,6 +1,8 @@
 package tlb
 
 import (
+	"bytes"
+	"math/big"
 	"testing"
 
 	"github.com/xssnick/tonutils-go/address"
@@ -49,3 +51,66 @@ func TestInternalMessage_ToCell(t *testing.T) { // need to deploy contract on te
 		t.Fatal("not eq ton", intMsg.Amount.NanoTON(), intMsg2.Amount.NanoTON())
 	}
 }
+
+func TestInternalMessage_ExtraCurrencies(t *testing.T) {
+	extra, err := ExtraCurrencyCollection{239: big.NewInt(1000)}.ToDict()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intMsg := InternalMessage{
+		Bounce:          true,
+		SrcAddr:         address.MustParseAddr("EQAOp1zuKuX4zY6L9rEdSLam7J3gogIHhfRu_gH70u2MQnmd"),
+		DstAddr:         address.MustParseAddr("EQA_B407fiLIlE5VYZCaI2rki0in6kLyjdhhwitvZNfpe7eY"),
+		Amount:          MustFromTON("0.05"),
+		ExtraCurrencies: extra,
+		Body:            cell.BeginCell().EndCell(),
+	}
+
+	c, err := intMsg.ToCell()
+	if err != nil {
+		t.Fatal("to cell err", err)
+	}
+
+	var intMsg2 InternalMessage
+	if err = LoadFromCell(&intMsg2, c.BeginParse()); err != nil {
+		t.Fatal("from cell err", err)
+	}
+
+	got, err := intMsg2.Extra()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[239].Uint64() != 1000 {
+		t.Fatal("not eq extra currencies", got)
+	}
+
+	if intMsg2.Amount.NanoTON().Uint64() != intMsg.Amount.NanoTON().Uint64() {
+		t.Fatal("not eq ton", intMsg2.Amount.NanoTON())
+	}
+}
+
+func TestExternalMessageOut_BodyLayout(t *testing.T) {
+	body := cell.BeginCell().MustStoreUInt(0xAB, 8).EndCell()
+	msg := ExternalMessageOut{
+		SrcAddr: address.MustParseAddr("EQAOp1zuKuX4zY6L9rEdSLam7J3gogIHhfRu_gH70u2MQnmd"),
+		DstAddr: address.NewAddressNone(),
+		Body:    body,
+	}
+
+	c, err := ToCell(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// body is always stored to ref, even when it fits, so hashes of messages are kept
+	if c.RefsNum() != 1 || !bytes.Equal(c.BeginParse().MustLoadRef().MustToCell().Hash(), body.Hash()) {
+		t.Fatal("body should be stored as ref")
+	}
+
+	msg.Body = nil
+	if _, err = ToCell(msg); err == nil {
+		t.Fatal("should fail on nil body")
+	}
+}
@@ -0,0 +1,353 @@
+package tlb
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// extractModifier - removes 'name:value' setting from the list and returns its value
+func extractModifier(settings []string, name string) ([]string, string, bool) {
+	prefix := name + ":"
+	for i, s := range settings {
+		if strings.HasPrefix(s, prefix) {
+			res := append(append([]string{}, settings[:i]...), settings[i+1:]...)
+			return res, s[len(prefix):], true
+		}
+	}
+	return settings, "", false
+}
+
+// Enum - can be implemented by type of integer field with 'enum' modifier, to declare allowed values
+type Enum interface {
+	EnumValues() []int64
+}
+
+// extractEnum - removes 'enum' or 'enum:A,B' setting from the list and returns listed values
+func extractEnum(settings []string) ([]string, string, bool) {
+	for i, s := range settings {
+		if s == "enum" {
+			return append(append([]string{}, settings[:i]...), settings[i+1:]...), "", true
+		}
+	}
+	return extractModifier(settings, "enum")
+}
+
+// checkEnum - checks that integer value is one of allowed
+func checkEnum(val reflect.Value, fieldName, enum string) error {
+	var allowed []int64
+	if enum != "" {
+		for _, s := range strings.Split(enum, ",") {
+			v, err := strconv.ParseInt(s, 0, 64)
+			if err != nil {
+				panic(fmt.Sprintf("corrupted enum value '%s' in tag of '%s'", s, fieldName))
+			}
+			allowed = append(allowed, v)
+		}
+	} else if e, ok := val.Interface().(Enum); ok {
+		allowed = e.EnumValues()
+	} else {
+		panic(fmt.Sprintf("enum values are not declared in tag of '%s' and its type not implements Enum", fieldName))
+	}
+
+	var v int64
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v = val.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v = int64(val.Uint())
+	default:
+		panic(fmt.Sprintf("enum can be used only with int or uint field, field '%s'", fieldName))
+	}
+
+	for _, a := range allowed {
+		if a == v {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("value %d of %s is not one of allowed enum values %v", v, fieldName, allowed)
+}
+
+// condition - parsed 'Field', '!Field' or 'Field<op>V' condition of 'if' modifier
+type condition struct {
+	name, op, want string
+	negate         bool
+}
+
+// parseCondition - splits condition to name of referenced field, operator and value
+func parseCondition(cond string) condition {
+	var c condition
+	if c.negate = strings.HasPrefix(cond, "!"); c.negate {
+		cond = cond[1:]
+	}
+
+	c.name = cond
+	if idx := strings.IndexAny(cond, "!=<>"); idx >= 0 {
+		c.name, c.op = cond[:idx], cond[idx:idx+1]
+		if len(cond) > idx+1 && cond[idx+1] == '=' && c.op != "=" {
+			c.op += "="
+		}
+		c.want = cond[idx+len(c.op):]
+	}
+	return c
+}
+
+// conditionField - returns type of field referenced in condition, and validates operator and value against it,
+// it does not depend on value of the field, so it is also used by Check
+func conditionField(typ reflect.Type, fieldName string, c condition) reflect.Type {
+	f, ok := typ.FieldByName(c.name)
+	if !ok {
+		// we panic, because its developer's issue, need to fix tag
+		panic(fmt.Sprintf("field '%s' referenced in condition of '%s' is not exists", c.name, fieldName))
+	}
+
+	switch c.op {
+	case "":
+	case "!":
+		panic(fmt.Sprintf("corrupted condition '%s' in tag of '%s'", c.name+c.op+c.want, fieldName))
+	case "=", "!=":
+		parseValue(f.Type, fieldName, c.want)
+	default:
+		if !isOrdered(f.Type) {
+			panic(fmt.Sprintf("ordered condition in tag of '%s' can be used only with int, uint or *big.Int field", fieldName))
+		}
+		parseValue(f.Type, fieldName, c.want)
+	}
+	return f.Type
+}
+
+// checkCondition - checks 'Field', '!Field' or 'Field<op>V' condition against already processed field of the struct,
+// op can be one of =, !=, <, <=, >, >=, nil *big.Int is not ordered, so comparison with it is never satisfied
+func checkCondition(rv reflect.Value, fieldName, cond string) bool {
+	c := parseCondition(cond)
+	typ := conditionField(rv.Type(), fieldName, c)
+	f := rv.FieldByName(c.name)
+
+	var res bool
+	switch c.op {
+	case "":
+		if f.Kind() == reflect.Bool {
+			res = f.Bool()
+		} else {
+			res = !f.IsZero()
+		}
+	case "=":
+		res = valuesEqual(f, parseValue(typ, fieldName, c.want))
+	case "!=":
+		res = !valuesEqual(f, parseValue(typ, fieldName, c.want))
+	default:
+		if f.Kind() == reflect.Pointer && f.IsNil() {
+			// *big.Int was not loaded, for example absent maybe field
+			break
+		}
+
+		cmp := compareValues(f, parseValue(typ, fieldName, c.want))
+		switch c.op {
+		case "<":
+			res = cmp < 0
+		case "<=":
+			res = cmp <= 0
+		case ">":
+			res = cmp > 0
+		case ">=":
+			res = cmp >= 0
+		}
+	}
+
+	return res != c.negate
+}
+
+// isOrdered - reports if values of the type can be compared by ordered condition
+func isOrdered(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return typ == reflect.TypeOf(&big.Int{})
+}
+
+// compareValues - compares non nil values of the same ordered type, returns -1, 0 or 1
+func compareValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(a.Int()).Cmp(big.NewInt(b.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(a.Uint()).Cmp(new(big.Int).SetUint64(b.Uint()))
+	}
+	return a.Interface().(*big.Int).Cmp(b.Interface().(*big.Int))
+}
+
+// boolField - returns bool field referenced by 'maybe:Field' or 'either:Field' modifier
+func boolField(rv reflect.Value, fieldName, name string) reflect.Value {
+	f := rv.FieldByName(name)
+	if !f.IsValid() || f.Kind() != reflect.Bool {
+		// we panic, because its developer's issue, need to fix tag
+		panic(fmt.Sprintf("field '%s' referenced in tag of '%s' should exist and be bool", name, fieldName))
+	}
+	return f
+}
+
+// eitherBranch - returns settings of the chosen branch of 'either:Field X Y'
+func eitherBranch(settings []string, fieldName string, second bool) []string {
+	if len(settings) == 0 || settings[0] == "maybe" {
+		// we panic, because its developer's issue, need to fix tag
+		panic(fmt.Sprintf("either:Field tag of '%s' should have 2 args and can be combined only with maybe:Field", fieldName))
+	}
+
+	a, b := splitEither(settings, fieldName)
+	if second {
+		return b
+	}
+	return a
+}
+
+// eitherFit - removes trailing fit option from args of either, reports if it was set
+func eitherFit(settings []string) ([]string, bool) {
+	if len(settings) > 2 && settings[len(settings)-1] == "fit" {
+		return settings[:len(settings)-1], true
+	}
+	return settings, false
+}
+
+// splitEither - splits 2 args of either, each of them is single token or group in parentheses
+func splitEither(settings []string, fieldName string) ([]string, []string) {
+	first, rest := nextGroup(settings, fieldName)
+	second, rest := nextGroup(rest, fieldName)
+	if len(rest) > 0 {
+		// we panic, because its developer's issue, need to fix tag
+		panic(fmt.Sprintf("either tag of '%s' should have 2 args, use parentheses to group them", fieldName))
+	}
+	return first, second
+}
+
+// nextGroup - splits first arg from settings, it is single token or tokens in parentheses, like '(maybe ## 32)'
+func nextGroup(settings []string, fieldName string) ([]string, []string) {
+	if len(settings) == 0 {
+		// we panic, because its developer's issue, need to fix tag
+		panic(fmt.Sprintf("either tag of '%s' should have 2 args", fieldName))
+	}
+
+	if !strings.HasPrefix(settings[0], "(") {
+		return settings[:1], settings[1:]
+	}
+
+	depth := 0
+	for j, s := range settings {
+		depth += strings.Count(s, "(") - strings.Count(s, ")")
+		if depth > 0 {
+			continue
+		}
+
+		group := append([]string{}, settings[:j+1]...)
+		group[0] = group[0][1:]
+		group[j] = group[j][:len(group[j])-1]
+
+		var res []string
+		for _, g := range group {
+			if g != "" {
+				res = append(res, g)
+			}
+		}
+
+		if len(res) == 0 {
+			panic(fmt.Sprintf("empty group in tag of '%s'", fieldName))
+		}
+		return res, settings[j+1:]
+	}
+
+	panic(fmt.Sprintf("unbalanced parentheses in tag of '%s'", fieldName))
+}
+
+// parseValue - parses value from tag to the type of field, supports bool, ints, uints and *big.Int
+func parseValue(typ reflect.Type, fieldName, val string) reflect.Value {
+	switch typ.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			panic(fmt.Sprintf("corrupted bool value '%s' in tag of '%s'", val, fieldName))
+		}
+		return reflect.ValueOf(b).Convert(typ)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(val, 0, typ.Bits())
+		if err != nil {
+			panic(fmt.Sprintf("corrupted int value '%s' in tag of '%s'", val, fieldName))
+		}
+		return reflect.ValueOf(v).Convert(typ)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(val, 0, typ.Bits())
+		if err != nil {
+			panic(fmt.Sprintf("corrupted uint value '%s' in tag of '%s'", val, fieldName))
+		}
+		return reflect.ValueOf(v).Convert(typ)
+	}
+
+	if typ == reflect.TypeOf(&big.Int{}) {
+		v, ok := new(big.Int).SetString(val, 0)
+		if !ok {
+			panic(fmt.Sprintf("corrupted bigint value '%s' in tag of '%s'", val, fieldName))
+		}
+		return reflect.ValueOf(v)
+	}
+
+	panic(fmt.Sprintf("value in tag of '%s' can be used only with bool, int, uint or *big.Int field", fieldName))
+}
+
+func valuesEqual(a, b reflect.Value) bool {
+	if a.Type() == reflect.TypeOf(&big.Int{}) {
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return a.Interface().(*big.Int).Cmp(b.Interface().(*big.Int)) == 0
+	}
+	return a.Interface() == b.Interface()
+}
+
+// inGroups - checks that one of comma separated groups of the field is in the list
+func inGroups(fieldGroups string, groups []string) bool {
+	if fieldGroups == "" {
+		return false
+	}
+
+	for _, g := range strings.Split(fieldGroups, ",") {
+		for _, want := range groups {
+			if g == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tryCandidates - parses 'try(A; B)' tag to candidates and name of the field referenced by 'variant:Field',
+// each candidate can start with magic prefix, for example 'try(#aabbccdd .; #ddccbbaa ^)'
+func tryCandidates(settings []string, fieldName string) ([][]string, string) {
+	settings, variant, _ := extractModifier(settings, "variant")
+
+	tag := strings.Join(settings, " ")
+	if !strings.HasPrefix(tag, "try(") || !strings.HasSuffix(tag, ")") {
+		// we panic, because its developer's issue, need to fix tag
+		panic(fmt.Sprintf("corrupted try tag of '%s', should be like 'try(A; B)'", fieldName))
+	}
+
+	var res [][]string
+	for _, c := range strings.Split(tag[len("try("):len(tag)-1], ";") {
+		candidate := strings.Fields(c)
+		if len(candidate) == 0 {
+			panic(fmt.Sprintf("empty candidate in try tag of '%s'", fieldName))
+		}
+		res = append(res, candidate)
+	}
+	return res, variant
+}
+
+// candidateMagic - splits magic prefix of try candidate, if it has one
+func candidateMagic(candidate []string) (magicBits, []string, bool) {
+	if strings.HasPrefix(candidate[0], "#") || strings.HasPrefix(candidate[0], "$") {
+		return parseMagic(candidate[0]), candidate[1:], true
+	}
+	return magicBits{}, candidate, false
+}
@@ -0,0 +1,115 @@
+package tlb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// OpcodeStat - statistics of one opcode which was failed to dispatch
+type OpcodeStat struct {
+	Opcode uint32
+	// Unmatched - number of bodies with no registered type for opcode
+	Unmatched uint64
+	// Failed - number of bodies which matched registered type, but failed to decode
+	Failed uint64
+	// Examples - hashes of example bodies, up to limit passed to NewOpcodeStats
+	Examples [][]byte
+}
+
+// OpcodeStats - collects statistics of opcodes which failed registry dispatch,
+// to know which schemas should be registered next. Safe for concurrent use.
+type OpcodeStats struct {
+	mx          sync.Mutex
+	maxExamples int
+	stats       map[uint32]*OpcodeStat
+}
+
+func NewOpcodeStats(maxExamples int) *OpcodeStats {
+	return &OpcodeStats{
+		maxExamples: maxExamples,
+		stats:       map[uint32]*OpcodeStat{},
+	}
+}
+
+// LoadAny - the same as LoadAny, but records body to stats if it was not decoded
+func (s *OpcodeStats) LoadAny(body *cell.Cell) (any, error) {
+	return s.LoadAnyContext(context.Background(), body)
+}
+
+// LoadAnyContext - the same as LoadAny, but options set using WithOptions in ctx are applied to decoding
+func (s *OpcodeStats) LoadAnyContext(ctx context.Context, body *cell.Cell) (any, error) {
+	v, err := LoadAnyContext(ctx, body.BeginParse())
+	if err != nil {
+		s.Record(body, err)
+	}
+	return v, err
+}
+
+// Record - records body which was failed to dispatch with err,
+// bodies shorter than 32 bits are ignored because they have no opcode
+func (s *OpcodeStats) Record(body *cell.Cell, err error) {
+	if body == nil || body.BitsSize() < 32 {
+		return
+	}
+	op := uint32(body.BeginParse().MustLoadUInt(32))
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	st := s.stats[op]
+	if st == nil {
+		st = &OpcodeStat{Opcode: op}
+		s.stats[op] = st
+	}
+
+	if errors.Is(err, ErrNoMatchingType) {
+		st.Unmatched++
+	} else {
+		st.Failed++
+	}
+
+	if len(st.Examples) < s.maxExamples {
+		hash := body.Hash()
+		for _, e := range st.Examples {
+			if bytes.Equal(e, hash) {
+				return
+			}
+		}
+		st.Examples = append(st.Examples, hash)
+	}
+}
+
+// Report - returns copy of collected statistics, sorted by total count, most frequent first
+func (s *OpcodeStats) Report() []OpcodeStat {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	res := make([]OpcodeStat, 0, len(s.stats))
+	for _, st := range s.stats {
+		cp := *st
+		cp.Examples = append([][]byte{}, st.Examples...)
+		res = append(res, cp)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		ci, cj := res[i].Unmatched+res[i].Failed, res[j].Unmatched+res[j].Failed
+		if ci != cj {
+			return ci > cj
+		}
+		return res[i].Opcode < res[j].Opcode
+	})
+	return res
+}
+
+// Reset - clears collected statistics
+func (s *OpcodeStats) Reset() {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.stats = map[uint32]*OpcodeStat{}
+}
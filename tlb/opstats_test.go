@@ -0,0 +1,47 @@
+package tlb
+
+import (
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testStatsKnown struct {
+	_   Magic  `tlb:"#5ca1ab1e"`
+	Val uint32 `tlb:"## 32"`
+}
+
+func TestOpcodeStats(t *testing.T) {
+	Register("TestStatsKnown", testStatsKnown{})
+
+	s := NewOpcodeStats(2)
+
+	for i := 0; i < 3; i++ {
+		_, _ = s.LoadAny(cell.BeginCell().MustStoreUInt(0xdeadbeef, 32).MustStoreUInt(uint64(i), 8).EndCell())
+	}
+	_, _ = s.LoadAny(cell.BeginCell().MustStoreUInt(0xdeadbeef, 32).MustStoreUInt(0, 8).EndCell())
+	_, _ = s.LoadAny(cell.BeginCell().MustStoreUInt(0x5ca1ab1e, 32).MustStoreUInt(1, 8).EndCell())
+	_, _ = s.LoadAny(cell.BeginCell().MustStoreUInt(1, 8).EndCell())
+
+	if _, err := s.LoadAny(cell.BeginCell().MustStoreUInt(0x5ca1ab1e, 32).MustStoreUInt(1, 32).EndCell()); err != nil {
+		t.Fatal(err)
+	}
+
+	r := s.Report()
+	if len(r) != 2 {
+		t.Fatal("report len not eq", len(r))
+	}
+
+	if r[0].Opcode != 0xdeadbeef || r[0].Unmatched != 4 || r[0].Failed != 0 || len(r[0].Examples) != 2 {
+		t.Fatal("unmatched stat not eq", r[0])
+	}
+
+	if r[1].Opcode != 0x5ca1ab1e || r[1].Failed != 1 || r[1].Unmatched != 0 {
+		t.Fatal("failed stat not eq", r[1])
+	}
+
+	s.Reset()
+	if len(s.Report()) != 0 {
+		t.Fatal("should be empty after reset")
+	}
+}
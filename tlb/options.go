@@ -0,0 +1,148 @@
+package tlb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// DefaultMaxDepth - default limit of inner structs nesting, the same as max depth of cells tree
+const DefaultMaxDepth = 1024
+
+// ErrMaxDepth - returned when nesting of inner structs exceeds the limit, see Options.MaxDepth
+var ErrMaxDepth = errors.New("max depth of inner structs is reached")
+
+// ErrInvalidDefinition - wraps panics on incorrect tags and type mismatches, when they are returned as errors, see Options.NoPanic
+var ErrInvalidDefinition = errors.New("invalid definition")
+
+// ErrLoaderPanic - wraps panics of manual loaders (Unmarshaler or custom LoadFromCell), when they are returned as errors,
+// see Options.NoPanic, usually they are caused by malformed data, like MustLoad* on short input
+var ErrLoaderPanic = errors.New("manual loader panicked")
+
+// ErrNotFullyConsumed - returned in strict mode when data is left in the cell after all fields are loaded
+var ErrNotFullyConsumed = errors.New("cell is not fully consumed")
+
+// Options - options of decoding, passed with WithOptions to LoadFromCellContext
+type Options struct {
+	// UnwrapMerkleProofs - when ref to inner struct is merkle proof, its content is loaded instead of proof cell itself
+	UnwrapMerkleProofs bool
+	// Strict - fails when bits or refs are left in the cell after all fields of struct are loaded from it,
+	// it is checked for the root cell and cells of inner structs loaded from refs and dict values,
+	// useful to catch outdated or truncated definitions
+	Strict bool
+	// MaxDepth - limit of inner structs nesting, DefaultMaxDepth is used when it is 0
+	MaxDepth int
+	// NoPanic - incorrect tags and type mismatches are returned as errors wrapping ErrInvalidDefinition,
+	// and panics of manual loaders as errors wrapping ErrLoaderPanic, instead of panic, useful for servers decoding untrusted data
+	NoPanic bool
+	// ForwardCompatible - bits and refs left in the cell of struct after all its fields are loaded are captured
+	// to its field with 'unknown' tag, and written back on store, structs without such field just ignore them,
+	// ErrNotFullyConsumed of Strict is not returned in this mode. It is checked for the same cells as Strict,
+	// so data of newer versions of contracts can be decoded and passed further without loss
+	ForwardCompatible bool
+	// Trace - when not nil, called after each field is loaded, including fields of inner structs,
+	// gives debugger for tags without patching the library, see FieldTrace
+	Trace func(FieldTrace)
+}
+
+type optionsKey struct{}
+
+type depthKey struct{}
+
+// WithOptions - returns ctx with decoding options
+func WithOptions(ctx context.Context, opts Options) context.Context {
+	return context.WithValue(ctx, optionsKey{}, opts)
+}
+
+func optionsOf(ctx context.Context) Options {
+	opts, _ := ctx.Value(optionsKey{}).(Options)
+	return opts
+}
+
+// storeOptions - options of serialization, passed to stores of all fields, including inner structs and dict values,
+// auditor only records decisions, so options do not depend on whether serialization is audited
+type storeOptions struct {
+	// caps - capabilities passed with WithCapabilities, nil when they are unknown
+	caps *uint64
+}
+
+func storeOptionsOf(ctx context.Context) storeOptions {
+	return storeOptions{caps: capabilitiesOf(ctx)}
+}
+
+// checkConsumed - called when struct val is loaded from the whole cell, in forward compatible mode captures data
+// left in loader to 'unknown' field of val, in strict mode returns error when loader has bits or refs left
+func checkConsumed(ctx context.Context, val reflect.Value, loader *cell.Slice) error {
+	opts := optionsOf(ctx)
+	if opts.ForwardCompatible {
+		return captureUnknown(val, loader)
+	}
+
+	if !opts.Strict || (loader.BitsLeft() == 0 && loader.RefsNum() == 0) {
+		return nil
+	}
+	return fmt.Errorf("%w: %d bits and %d refs left", ErrNotFullyConsumed, loader.BitsLeft(), loader.RefsNum())
+}
+
+// captureUnknown - loads data left in loader to 'unknown' field of struct val, if it has one
+func captureUnknown(val reflect.Value, loader *cell.Slice) error {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct || loader.BitsLeft() == 0 && loader.RefsNum() == 0 {
+		return nil
+	}
+
+	idx := planOf(val.Type()).unknown
+	if idx < 0 || !val.Field(idx).CanSet() {
+		return nil
+	}
+
+	c, err := loadRemaining(loader)
+	if err != nil {
+		return fmt.Errorf("failed to load unknown data: %w", err)
+	}
+	val.Field(idx).Set(reflect.ValueOf(c))
+	return nil
+}
+
+// enterDepth - returns ctx for loading inner struct, with increased depth,
+// or ErrMaxDepth when the limit is reached
+func enterDepth(ctx context.Context) (context.Context, error) {
+	limit := optionsOf(ctx).MaxDepth
+	if limit <= 0 {
+		limit = DefaultMaxDepth
+	}
+
+	depth, _ := ctx.Value(depthKey{}).(int)
+	if depth >= limit {
+		return nil, fmt.Errorf("%w: %d", ErrMaxDepth, limit)
+	}
+	return context.WithValue(ctx, depthKey{}, depth+1), nil
+}
+
+// recoverDefinition - should be deferred, converts panic to error wrapping ErrInvalidDefinition
+func recoverDefinition(typ reflect.Type, err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%w of %s: %v", ErrInvalidDefinition, typ, r)
+	}
+}
+
+// recoverLoader - wraps manual loader, so its panic is returned as error wrapping ErrLoaderPanic
+func recoverLoader(load func(loader *cell.Slice) error) func(loader *cell.Slice) error {
+	return func(loader *cell.Slice) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%w: %v", ErrLoaderPanic, r)
+			}
+		}()
+		return load(loader)
+	}
+}
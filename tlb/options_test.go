@@ -0,0 +1,198 @@
+package tlb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testStrictInner struct {
+	Val uint32 `tlb:"## 32"`
+}
+
+type testStrictOuter struct {
+	Flag  bool              `tlb:"bool"`
+	Inner *testStrictInner  `tlb:"^"`
+	Items []testStrictInner `tlb:"^"`
+}
+
+func TestLoadFromCellStrict(t *testing.T) {
+	inner := cell.BeginCell().MustStoreUInt(7, 32).EndCell()
+	a := cell.BeginCell().MustStoreBoolBit(true).MustStoreRef(inner).MustStoreRef(inner).EndCell()
+
+	var x testStrictOuter
+	if err := LoadFromCellStrict(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !x.Flag || x.Inner.Val != 7 || len(x.Items) != 1 {
+		t.Fatal("incorrect values", x)
+	}
+
+	// extra bits in root
+	b := cell.BeginCell().MustStoreBoolBit(true).MustStoreRef(inner).MustStoreUInt(1, 3).EndCell()
+	if err := LoadFromCell(&x, b.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadFromCellStrict(&x, b.BeginParse()); !errors.Is(err, ErrNotFullyConsumed) {
+		t.Fatal("should fail with not consumed error", err)
+	}
+
+	// extra bits in inner struct ref
+	wide := cell.BeginCell().MustStoreUInt(7, 40).EndCell()
+	c := cell.BeginCell().MustStoreBoolBit(true).MustStoreRef(wide).EndCell()
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadFromCellStrict(&x, c.BeginParse()); !errors.Is(err, ErrNotFullyConsumed) {
+		t.Fatal("should fail with not consumed error", err)
+	}
+
+	// extra bits in element of ref slice
+	d := cell.BeginCell().MustStoreBoolBit(true).MustStoreRef(inner).MustStoreRef(wide).EndCell()
+	if err := LoadFromCellStrict(&x, d.BeginParse()); !errors.Is(err, ErrNotFullyConsumed) {
+		t.Fatal("should fail with not consumed error", err)
+	}
+}
+
+type testDeep struct {
+	Val  uint8     `tlb:"## 8"`
+	Next *testDeep `tlb:"maybe ^"`
+}
+
+func TestLoadFromCellMaxDepth(t *testing.T) {
+	c := cell.BeginCell().MustStoreUInt(0, 8).MustStoreBoolBit(false).EndCell()
+	for i := 1; i <= 10; i++ {
+		c = cell.BeginCell().MustStoreUInt(uint64(i), 8).MustStoreMaybeRef(c).EndCell()
+	}
+
+	var x testDeep
+	if err := LoadFromCell(&x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Val != 10 || x.Next.Next.Val != 8 {
+		t.Fatal("incorrect values", x)
+	}
+
+	ctx := WithOptions(context.Background(), Options{MaxDepth: 5})
+	if err := LoadFromCellContext(ctx, &x, c.BeginParse()); !errors.Is(err, ErrMaxDepth) {
+		t.Fatal("should fail with max depth error", err)
+	}
+
+	ctx = WithOptions(context.Background(), Options{MaxDepth: 10})
+	if err := LoadFromCellContext(ctx, &x, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type testBadTag struct {
+	Val uint32 `tlb:"## abc"`
+}
+
+func TestLoadFromCellSafe(t *testing.T) {
+	c := cell.BeginCell().MustStoreUInt(7, 32).EndCell()
+
+	var x testBadTag
+	if err := LoadFromCellSafe(&x, c.BeginParse()); !errors.Is(err, ErrInvalidDefinition) {
+		t.Fatal("should fail with definition error", err)
+	}
+
+	// nested struct
+	var y struct {
+		Inner testBadTag `tlb:"^"`
+	}
+	if err := LoadFromCellSafe(&y, cell.BeginCell().MustStoreRef(c).EndCell().BeginParse()); !errors.Is(err, ErrInvalidDefinition) {
+		t.Fatal("should fail with definition error", err)
+	}
+
+	if _, err := ToCellSafe(x); !errors.Is(err, ErrInvalidDefinition) {
+		t.Fatal("should fail with definition error", err)
+	}
+}
+
+type testPanicLoader struct {
+	Val uint32
+}
+
+func (p *testPanicLoader) UnmarshalTLB(loader *cell.Slice) error {
+	p.Val = uint32(loader.MustLoadUInt(32))
+	return nil
+}
+
+func TestLoadFromCellSafeLoaderPanic(t *testing.T) {
+	var x struct {
+		Inner testPanicLoader `tlb:"^"`
+	}
+
+	short := cell.BeginCell().MustStoreRef(cell.BeginCell().MustStoreUInt(7, 8).EndCell()).EndCell()
+	err := LoadFromCellSafe(&x, short.BeginParse())
+	if !errors.Is(err, ErrLoaderPanic) || errors.Is(err, ErrInvalidDefinition) {
+		t.Fatal("should fail with loader panic error", err)
+	}
+
+	full := cell.BeginCell().MustStoreRef(cell.BeginCell().MustStoreUInt(7, 32).EndCell()).EndCell()
+	if err = LoadFromCellSafe(&x, full.BeginParse()); err != nil || x.Inner.Val != 7 {
+		t.Fatal("incorrect load", err, x.Inner.Val)
+	}
+}
+
+type testCompatInner struct {
+	Val     uint32     `tlb:"## 32"`
+	Unknown *cell.Cell `tlb:"unknown"`
+}
+
+type testCompatOuter struct {
+	Val   uint8           `tlb:"## 8"`
+	Inner testCompatInner `tlb:"^"`
+	Plain testStrictInner `tlb:"^"`
+}
+
+func TestLoadFromCellForwardCompatible(t *testing.T) {
+	ext := cell.BeginCell().MustStoreUInt(0xBEEF, 16).EndCell()
+	inner := cell.BeginCell().MustStoreUInt(5, 32).MustStoreUInt(0xAB, 8).MustStoreRef(ext).EndCell()
+	plain := cell.BeginCell().MustStoreUInt(6, 32).MustStoreUInt(1, 1).EndCell()
+	root := cell.BeginCell().MustStoreUInt(7, 8).MustStoreRef(inner).MustStoreRef(plain).MustStoreUInt(3, 2).EndCell()
+
+	ctx := WithOptions(context.Background(), Options{ForwardCompatible: true, Strict: true})
+
+	var v testCompatOuter
+	if err := LoadFromCellContext(ctx, &v, root.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Inner.Val != 5 || v.Plain.Val != 6 {
+		t.Fatal("incorrect values")
+	}
+
+	if v.Inner.Unknown == nil || v.Inner.Unknown.BitsSize() != 8 || v.Inner.Unknown.RefsNum() != 1 {
+		t.Fatal("unknown data should be captured")
+	}
+
+	c, err := ToCell(v.Inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), inner.Hash()) {
+		t.Fatal("unknown data should be stored back")
+	}
+
+	var x testCompatOuter
+	if err = LoadFromCellContext(WithOptions(context.Background(), Options{Strict: true}), &x, root.BeginParse()); !errors.Is(err, ErrNotFullyConsumed) {
+		t.Fatal("should be not fully consumed error without forward compatible mode, got", err)
+	}
+
+	if err = LoadFromCell(&x, root.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Inner.Unknown != nil {
+		t.Fatal("unknown data should be captured only in forward compatible mode")
+	}
+}
@@ -0,0 +1,94 @@
+package tlb
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldError - error of loading or storing of the field, with path to it from the root struct,
+// like 'Transaction.Description.ComputePhase.GasUsed', elements of slices are presented like 'Items[2]'
+type FieldError struct {
+	// Type - name of the root struct type, empty for unnamed types
+	Type string
+	// Path - path to the failed field from the root struct
+	Path string
+	// Refs - indexes of refs to follow from the root cell to reach the cell of the failed field,
+	// on load it is tracked through '^' fields, fields of dict values are located relatively to the value cell
+	Refs []int
+	// BitsOffset - offset of the beginning of the failed field in its cell
+	BitsOffset uint
+	Err        error
+}
+
+func (e *FieldError) Error() string {
+	path := e.Path
+	if e.Type != "" {
+		path = e.Type + "." + path
+	}
+
+	loc := fmt.Sprintf("bit %d", e.BitsOffset)
+	if len(e.Refs) > 0 {
+		refs := make([]string, 0, len(e.Refs))
+		for _, r := range e.Refs {
+			refs = append(refs, strconv.Itoa(r))
+		}
+		loc += " of ref " + strings.Join(refs, ".")
+	}
+	return path + " (at " + loc + "): " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// withPath - prepends field name to the path of err when it is FieldError, or wraps err to FieldError of the field
+// which begins at bitsOffset. FieldError nested deeper in err is not merged, it may come from unrelated decoding,
+// like the one of manual loader, so internal wrappers pass FieldError of inner struct as is, see keepFieldError
+func withPath(err error, typ reflect.Type, fieldName string, bitsOffset uint) error {
+	return prependPath(err, typ.Name(), fieldName, bitsOffset)
+}
+
+// withIndex - prepends index of element to the path of err when it is FieldError, or wraps err to FieldError
+func withIndex(err error, idx int, bitsOffset uint) error {
+	return prependPath(err, "", fmt.Sprintf("[%d]", idx), bitsOffset)
+}
+
+// inRef - prepends index of ref, from which inner struct was loaded, to the location of err when it is FieldError,
+// otherwise err is wrapped to FieldError located at bitsOffset of the ref
+func inRef(err error, idx int, bitsOffset uint) error {
+	fe, ok := err.(*FieldError)
+	if !ok {
+		return &FieldError{Refs: []int{idx}, BitsOffset: bitsOffset, Err: err}
+	}
+
+	res := *fe
+	res.Refs = append([]int{idx}, fe.Refs...)
+	return &res
+}
+
+// keepFieldError - returns err as is when it is FieldError of inner field, so its path is extended by the caller
+// instead of being hidden under wrapped, otherwise returns wrapped
+func keepFieldError(err, wrapped error) error {
+	if _, ok := err.(*FieldError); ok {
+		return err
+	}
+	return wrapped
+}
+
+func prependPath(err error, typ, segment string, bitsOffset uint) error {
+	fe, ok := err.(*FieldError)
+	if !ok {
+		return &FieldError{Type: typ, Path: segment, BitsOffset: bitsOffset, Err: err}
+	}
+
+	res := *fe
+	res.Type = typ
+	if fe.Path == "" || strings.HasPrefix(fe.Path, "[") {
+		res.Path = segment + fe.Path
+	} else {
+		res.Path = segment + "." + fe.Path
+	}
+	return &res
+}
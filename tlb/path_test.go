@@ -0,0 +1,109 @@
+package tlb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testPathLeaf struct {
+	Val  uint32 `tlb:"## 32"`
+	Kind uint8  `tlb:"## 4 enum:1,2"`
+}
+
+type testPathMid struct {
+	Items []testPathLeaf `tlb:"^"`
+}
+
+type testPathOuter struct {
+	Flag  bool         `tlb:"bool"`
+	Inner *testPathMid `tlb:"^"`
+}
+
+func TestFieldErrorPath(t *testing.T) {
+	good := cell.BeginCell().MustStoreUInt(7, 32).MustStoreUInt(1, 4).EndCell()
+	short := cell.BeginCell().MustStoreUInt(7, 16).EndCell()
+	mid := cell.BeginCell().MustStoreRef(good).MustStoreRef(short).EndCell()
+	a := cell.BeginCell().MustStoreBoolBit(true).MustStoreRef(mid).EndCell()
+
+	var x testPathOuter
+	err := LoadFromCell(&x, a.BeginParse())
+
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		t.Fatal("should fail with field error", err)
+	}
+
+	if fe.Type != "testPathOuter" || fe.Path != "Inner.Items[1].Val" {
+		t.Fatal("incorrect path", fe.Type, fe.Path)
+	}
+
+	// second ref of the first ref of root
+	if len(fe.Refs) != 2 || fe.Refs[0] != 0 || fe.Refs[1] != 1 || fe.BitsOffset != 0 {
+		t.Fatal("incorrect location", fe.Refs, fe.BitsOffset)
+	}
+
+	const prefix = "testPathOuter.Inner.Items[1].Val (at bit 0 of ref 0.1): "
+	if msg := err.Error(); len(msg) < len(prefix) || msg[:len(prefix)] != prefix {
+		t.Fatal("incorrect message", msg)
+	}
+
+	// failure in the middle of the root cell
+	b := cell.BeginCell().MustStoreBoolBit(true).MustStoreUInt(7, 32).MustStoreUInt(1, 2).EndCell()
+	var leaf struct {
+		Flag bool `tlb:"bool"`
+		testPathLeaf
+	}
+	if err = LoadFromCell(&leaf, b.BeginParse()); !errors.As(err, &fe) || fe.Path != "testPathLeaf.Kind" || fe.BitsOffset != 33 || len(fe.Refs) != 0 {
+		t.Fatal("incorrect location", err)
+	}
+
+	x = testPathOuter{Inner: &testPathMid{Items: []testPathLeaf{{Val: 1, Kind: 1}, {Val: 2, Kind: 3}}}}
+	if _, err = ToCell(x); !errors.As(err, &fe) || fe.Path != "Inner.Items[1].Kind" {
+		t.Fatal("incorrect path of store error", err)
+	}
+
+	// leaf error is kept
+	if _, err = ToCell(testPathLeaf{Kind: 5}); !errors.As(err, &fe) || fe.Path != "Kind" || fe.Err == nil {
+		t.Fatal("incorrect path of store error", err)
+	}
+}
+
+type testPathManual struct {
+	Leaf testPathLeaf
+}
+
+func (m *testPathManual) LoadFromCell(loader *cell.Slice) error {
+	if err := LoadFromCell(&m.Leaf, loader); err != nil {
+		return fmt.Errorf("manual leaf: %w", err)
+	}
+	return nil
+}
+
+func TestFieldErrorNestedInManualLoader(t *testing.T) {
+	var x struct {
+		Flag   bool            `tlb:"bool"`
+		Manual *testPathManual `tlb:"^"`
+	}
+
+	short := cell.BeginCell().MustStoreUInt(7, 16).EndCell()
+	err := LoadFromCell(&x, cell.BeginCell().MustStoreBoolBit(true).MustStoreRef(short).EndCell().BeginParse())
+
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "Manual" || len(fe.Refs) != 1 {
+		t.Fatal("field error of manual loader field expected", err)
+	}
+
+	// error of manual loader is kept untouched, with its wrappers
+	if !strings.Contains(err.Error(), "manual leaf: testPathLeaf.Val (at bit 0): ") {
+		t.Fatal("incorrect message", err)
+	}
+
+	var inner *FieldError
+	if !errors.As(fe.Err, &inner) || inner.Type != "testPathLeaf" || inner.Path != "Val" || len(inner.Refs) != 0 {
+		t.Fatal("inner field error should not be changed", fe.Err)
+	}
+}
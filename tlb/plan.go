@@ -0,0 +1,312 @@
+package tlb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// fieldPlan - parsed tag of the struct field, modifiers are extracted from settings
+type fieldPlan struct {
+	field reflect.StructField
+	skip  bool
+	// invalid - panic value raised on parsing of the tag, it is raised again when field is processed
+	invalid any
+
+	settings []string
+
+	group                 string
+	cond, capMask         string
+	want, markName, enum  string
+	presence, def, branch string
+	hasCond, hasCap       bool
+	hasAssert, hasMark    bool
+	hasEnum, hasPresence  bool
+	hasDefault, hasBranch bool
+
+	// load, store - handlers compiled for simple tags, like '## N', 'bool', 'addr' and '^' of cell,
+	// nil when generic loadField and storeField should be used
+	load  fieldLoader
+	store fieldStorer
+}
+
+type fieldLoader func(fieldVal reflect.Value, loader *cell.Slice) error
+
+type fieldStorer func(fieldVal reflect.Value, builder *cell.Builder) error
+
+// typePlan - parsed tags of all fields of the struct type, built once per type
+type typePlan struct {
+	fields []fieldPlan
+	// marks - index of Marks field, -1 if there is no such field
+	marks int
+	// unknown - index of field with 'unknown' tag, -1 if there is no such field
+	unknown int
+}
+
+var plans sync.Map
+
+// planOf - returns cached plan of struct type, builds it on first use
+func planOf(typ reflect.Type) *typePlan {
+	if p, ok := plans.Load(typ); ok {
+		return p.(*typePlan)
+	}
+
+	p := &typePlan{fields: make([]fieldPlan, typ.NumField()), marks: -1, unknown: -1}
+	for i := range p.fields {
+		p.fields[i] = planField(typ.Field(i))
+		if p.marks < 0 && p.fields[i].field.Type == reflect.TypeOf(Marks{}) {
+			p.marks = i
+		}
+		if fp := &p.fields[i]; p.unknown < 0 && len(fp.settings) == 1 && fp.settings[0] == "unknown" {
+			p.unknown = i
+		}
+	}
+
+	plans.Store(typ, p)
+	return p
+}
+
+func planField(field reflect.StructField) (fp fieldPlan) {
+	fp.field = field
+	defer func() {
+		if r := recover(); r != nil {
+			fp.invalid = r
+		}
+	}()
+
+	tag := fieldTag(field)
+	if tag == "-" {
+		fp.skip = true
+		return fp
+	}
+	settings := splitTag(field, tag)
+
+	settings, fp.group, _ = extractModifier(settings, "group")
+	settings, fp.cond, fp.hasCond = extractModifier(settings, "if")
+	settings, fp.capMask, fp.hasCap = extractModifier(settings, "cap")
+	settings, fp.want, fp.hasAssert = extractModifier(settings, "assert")
+	settings, fp.markName, fp.hasMark = extractModifier(settings, "mark")
+	settings, fp.enum, fp.hasEnum = extractEnum(settings)
+	settings, fp.presence, fp.hasPresence = extractModifier(settings, "maybe")
+	settings, fp.def, fp.hasDefault = extractModifier(settings, "default")
+	settings, fp.branch, fp.hasBranch = extractModifier(settings, "either")
+	fp.settings = settings
+
+	if !fp.hasDefault && !fp.hasBranch && field.Type != reflect.TypeOf(Magic{}) {
+		// settings are changed on load and store when default or either is used
+		fp.load, fp.store = compileField(field, expandAlias(settings))
+	}
+	return fp
+}
+
+// compileField - returns handlers of the field, when its tag is simple enough to resolve sizes and types once,
+// they should behave the same as the generic loadField and storeField, nil handlers are returned for other tags
+func compileField(field reflect.StructField, settings []string) (fieldLoader, fieldStorer) {
+	switch {
+	case len(settings) == 2 && settings[0] == "##":
+		num, err := strconv.ParseUint(settings[1], 10, 64)
+		if err != nil || num > 64 {
+			return nil, nil
+		}
+		return compileInt(field.Type, uint(num))
+	case len(settings) == 1 && settings[0] == "bool" && field.Type == reflect.TypeOf(false):
+		return func(fieldVal reflect.Value, loader *cell.Slice) error {
+				x, err := loader.LoadBoolBit()
+				if err != nil {
+					return fmt.Errorf("failed to load bool, err: %w", err)
+				}
+				fieldVal.SetBool(x)
+				return nil
+			}, func(fieldVal reflect.Value, builder *cell.Builder) error {
+				if err := builder.StoreBoolBit(fieldVal.Bool()); err != nil {
+					return fmt.Errorf("failed to store bool, err: %w", err)
+				}
+				return nil
+			}
+	case len(settings) == 1 && settings[0] == "addr" && field.Type == reflect.TypeOf(&address.Address{}):
+		return func(fieldVal reflect.Value, loader *cell.Slice) error {
+				x, err := loader.LoadAddr()
+				if err != nil {
+					return fmt.Errorf("failed to load address, err: %w", err)
+				}
+				fieldVal.Set(reflect.ValueOf(x))
+				return nil
+			}, func(fieldVal reflect.Value, builder *cell.Builder) error {
+				if err := builder.StoreAddr(fieldVal.Interface().(*address.Address)); err != nil {
+					return fmt.Errorf("failed to store address, err: %w", err)
+				}
+				return nil
+			}
+	case len(settings) == 1 && settings[0] == "^" && field.Type == reflect.TypeOf(&cell.Cell{}):
+		return func(fieldVal reflect.Value, loader *cell.Slice) error {
+				ref, err := loader.LoadRef()
+				if err != nil {
+					return fmt.Errorf("failed to load ref for %s, err: %w", field.Name, err)
+				}
+
+				c, err := ref.ToCell()
+				if err != nil {
+					return fmt.Errorf("failed to convert ref to cell for %s, err: %w", field.Name, err)
+				}
+				fieldVal.Set(reflect.ValueOf(c))
+				return nil
+			}, func(fieldVal reflect.Value, builder *cell.Builder) error {
+				if fieldVal.IsNil() {
+					return fmt.Errorf("value of %s is nil, use maybe if it is optional", field.Name)
+				}
+
+				if err := builder.StoreRef(fieldVal.Interface().(*cell.Cell)); err != nil {
+					return fmt.Errorf("failed to store cell to ref for %s, err: %w", field.Name, err)
+				}
+				return nil
+			}
+	case len(settings) == 2 && settings[0] == "maybe" && settings[1] == "^" && field.Type == reflect.TypeOf(&cell.Cell{}):
+		// only load is compiled, store records omitted value to audit
+		return func(fieldVal reflect.Value, loader *cell.Slice) error {
+			ref, err := loader.LoadMaybeRef()
+			if err != nil {
+				return fmt.Errorf("failed to load maybe ref for %s, err: %w", field.Name, err)
+			}
+
+			var c *cell.Cell
+			if ref != nil {
+				if c, err = ref.ToCell(); err != nil {
+					return fmt.Errorf("failed to convert ref to cell for %s, err: %w", field.Name, err)
+				}
+			}
+			fieldVal.Set(reflect.ValueOf(c))
+			return nil
+		}, nil
+	}
+	return nil, nil
+}
+
+// compileInt - returns handlers of '## N' tag with N <= 64 for integer kinds
+func compileInt(typ reflect.Type, num uint) (fieldLoader, fieldStorer) {
+	switch typ.Kind() {
+	case reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
+		return func(fieldVal reflect.Value, loader *cell.Slice) error {
+				x, err := loader.LoadInt(num)
+				if err != nil {
+					return fmt.Errorf("failed to load int %d, err: %w", num, err)
+				}
+				fieldVal.SetInt(x)
+				return nil
+			}, func(fieldVal reflect.Value, builder *cell.Builder) error {
+				if err := builder.StoreInt(fieldVal.Int(), num); err != nil {
+					return fmt.Errorf("failed to store int %d, err: %w", num, err)
+				}
+				return nil
+			}
+	case reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.Uint:
+		return func(fieldVal reflect.Value, loader *cell.Slice) error {
+				x, err := loader.LoadUInt(num)
+				if err != nil {
+					return fmt.Errorf("failed to load uint %d, err: %w", num, err)
+				}
+				fieldVal.SetUint(x)
+				return nil
+			}, func(fieldVal reflect.Value, builder *cell.Builder) error {
+				if err := builder.StoreUInt(fieldVal.Uint(), num); err != nil {
+					return fmt.Errorf("failed to store uint %d, err: %w", num, err)
+				}
+				return nil
+			}
+	}
+	return nil, nil
+}
+
+// resetPlans - drops cached plans, should be called when tags can be parsed differently, for example on new fragment
+func resetPlans() {
+	plans.Range(func(key, _ any) bool {
+		plans.Delete(key)
+		return true
+	})
+}
+
+// Codec - codec of type T, returned by Compile, it keeps plan of T resolved at compile time
+// and decodes and encodes values through it directly
+type Codec[T any] struct {
+	typ  reflect.Type
+	ptr  bool
+	plan *typePlan // nil when T implements Unmarshaler or Marshaler
+}
+
+// Compile - validates definition of type T using Check and builds plans of it and of its inner structs,
+// with tags parsed and handlers of simple tags (like '## N', 'bool', 'addr' and '^' of cell) compiled once,
+// so sizes are not parsed and tags are not dispatched on each call, returns Codec to decode and encode values of T.
+// T can be struct or pointer to struct. Codec keeps the plan of T, so fragments registered after Compile
+// are not applied to fields of T, plans of inner structs are shared with LoadFromCell and ToCell.
+func Compile[T any]() (Codec[T], error) {
+	orig := reflect.TypeOf((*T)(nil)).Elem()
+	typ, ptr := orig, orig.Kind() == reflect.Pointer
+	if ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return Codec[T]{}, errors.New("type should be a struct or pointer to struct")
+	}
+
+	if err := Check(reflect.New(typ).Interface()); err != nil {
+		return Codec[T]{}, err
+	}
+
+	codec := Codec[T]{typ: typ, ptr: ptr}
+	_, manualLoad := asUnmarshaler(reflect.New(typ).Interface())
+	_, manualStore := asMarshaler(reflect.Zero(orig).Interface())
+	if !manualLoad && !manualStore {
+		codec.plan = planOf(typ)
+	}
+	return codec, nil
+}
+
+// Decode - loads value of T from loader, the same as Load
+func (c Codec[T]) Decode(loader *cell.Slice) (T, error) {
+	if c.plan == nil {
+		return Load[T](loader)
+	}
+
+	var v T
+	ctx, err := enterDepth(context.Background())
+	if err != nil {
+		return v, err
+	}
+
+	nVal := reflect.New(c.typ)
+	if err = loadPlan(ctx, nVal.Elem(), c.plan, loader, nil); err != nil {
+		return v, fmt.Errorf("failed to load from cell for %s, err: %w", c.typ.Name(), err)
+	}
+
+	if !c.ptr {
+		return nVal.Elem().Interface().(T), nil
+	}
+	return nVal.Interface().(T), nil
+}
+
+// Encode - stores value of T to cell, the same as Store
+func (c Codec[T]) Encode(v T) (*cell.Cell, error) {
+	if c.plan == nil {
+		return Store(v)
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	if c.ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("failed to store to cell for %s, err: v should not be nil", rv.Type().String())
+		}
+		rv = rv.Elem()
+	}
+
+	builder := cell.BeginCell()
+	if err := storePlan(rv, c.plan, builder, storeOptions{}, nil, nil); err != nil {
+		return nil, fmt.Errorf("failed to store to cell for %s, err: %w", reflect.TypeOf(&v).Elem().String(), err)
+	}
+	return builder.EndCell(), nil
+}
@@ -0,0 +1,243 @@
+package tlb
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+func TestCompile(t *testing.T) {
+	codec, err := Compile[*testStrictOuter]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inner := cell.BeginCell().MustStoreUInt(7, 32).EndCell()
+	a := cell.BeginCell().MustStoreBoolBit(true).MustStoreRef(inner).MustStoreRef(inner).EndCell()
+
+	x, err := codec.Decode(a.BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !x.Flag || x.Inner.Val != 7 || len(x.Items) != 1 || x.Items[0].Val != 7 {
+		t.Fatal("incorrect values", x)
+	}
+
+	c, err := codec.Encode(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	if _, err = Compile[testCheckBad](); !errors.Is(err, ErrInvalidDefinition) {
+		t.Fatal("should fail with definition error", err)
+	}
+
+	if _, err = Compile[int](); err == nil {
+		t.Fatal("should fail on not struct")
+	}
+
+	if _, err = Compile[testCheckBigCond](); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompileManual(t *testing.T) {
+	codec, err := Compile[manualLoad]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if codec.plan != nil {
+		t.Fatal("manual loader should be used instead of plan")
+	}
+
+	c, err := codec.Encode(manualLoad{Val: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := codec.Decode(c.BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Val != "x" {
+		t.Fatal("incorrect value", x.Val)
+	}
+}
+
+func TestCompileKeepsPlan(t *testing.T) {
+	type testCodecFragment struct {
+		Val uint64 `tlb:"use:testCodecSize"`
+	}
+
+	RegisterFragment("testCodecSize", "## 8")
+	codec, err := Compile[*testCodecFragment]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// codec decodes through its own plan, which is not rebuilt with new fragment
+	RegisterFragment("testCodecSize", "## 16")
+	x, err := codec.Decode(cell.BeginCell().MustStoreUInt(0xAABB, 16).EndCell().BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Val != 0xAA {
+		t.Fatal("incorrect value", x.Val)
+	}
+
+	if _, err = codec.Encode(nil); err == nil {
+		t.Fatal("should fail on nil")
+	}
+}
+
+func TestPlanFragmentReset(t *testing.T) {
+	type testPlanFragment struct {
+		Val uint64 `tlb:"use:testPlanSize"`
+	}
+
+	RegisterFragment("testPlanSize", "## 8")
+	var x testPlanFragment
+	if err := LoadFromCell(&x, cell.BeginCell().MustStoreUInt(0xAABB, 16).EndCell().BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Val != 0xAA {
+		t.Fatal("incorrect value", x.Val)
+	}
+
+	// cached tags should be parsed again with new fragment
+	RegisterFragment("testPlanSize", "## 16")
+	if err := LoadFromCell(&x, cell.BeginCell().MustStoreUInt(0xAABB, 16).EndCell().BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if x.Val != 0xAABB {
+		t.Fatal("incorrect value", x.Val)
+	}
+}
+
+type testCodecRecord struct {
+	Seqno   uint32           `tlb:"## 32"`
+	LT      uint64           `tlb:"## 64"`
+	Delta   int16            `tlb:"## 16"`
+	Bounce  bool             `tlb:"bool"`
+	Src     *address.Address `tlb:"addr"`
+	Dst     *address.Address `tlb:"addr"`
+	Body    *cell.Cell       `tlb:"^"`
+	Payload *cell.Cell       `tlb:"maybe ^"`
+}
+
+func TestCompiledHandlers(t *testing.T) {
+	plan := planOf(reflect.TypeOf(testCodecRecord{}))
+	for _, fp := range plan.fields {
+		if fp.load == nil {
+			t.Fatal("load should be compiled for", fp.field.Name)
+		}
+	}
+
+	x := testCodecRecord{
+		Seqno:  7,
+		LT:     1 << 60,
+		Delta:  -5,
+		Bounce: true,
+		Src:    address.MustParseAddr("EQC6KV4zs8TJtSZapOrRFmqSkxzpq-oSCoxekQRKElf4nC1I"),
+		Dst:    address.NewAddressNone(),
+		Body:   cell.BeginCell().MustStoreUInt(0xCAFE, 16).EndCell(),
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same layout should be produced by generic handlers
+	generic, err := withGenericPlan(reflect.TypeOf(x), func() (*cell.Cell, error) {
+		return ToCell(x)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), generic.Hash()) {
+		t.Fatal("compiled and generic layouts are different")
+	}
+
+	var y testCodecRecord
+	if err = LoadFromCell(&y, c.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := Diff(x, y); d != "" {
+		t.Fatal("not same after load:", d)
+	}
+
+	x.Body = nil
+	if _, err = ToCell(x); err == nil {
+		t.Fatal("should fail on nil ref")
+	}
+}
+
+// withGenericPlan - calls f while plan of typ has no compiled handlers, so tags are dispatched as without plans
+func withGenericPlan(typ reflect.Type, f func() (*cell.Cell, error)) (*cell.Cell, error) {
+	compiled := planOf(typ)
+
+	generic := *compiled
+	generic.fields = append([]fieldPlan{}, compiled.fields...)
+	for i := range generic.fields {
+		generic.fields[i].load, generic.fields[i].store = nil, nil
+	}
+
+	plans.Store(typ, &generic)
+	defer plans.Store(typ, compiled)
+	return f()
+}
+
+func BenchmarkCodecDecode(b *testing.B) {
+	codec, err := Compile[testCodecRecord]()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	c, err := codec.Encode(testCodecRecord{
+		Seqno: 7,
+		LT:    1 << 60,
+		Src:   address.MustParseAddr("EQC6KV4zs8TJtSZapOrRFmqSkxzpq-oSCoxekQRKElf4nC1I"),
+		Dst:   address.MustParseAddr("EQC6KV4zs8TJtSZapOrRFmqSkxzpq-oSCoxekQRKElf4nC1I"),
+		Body:  cell.BeginCell().EndCell(),
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("compiled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := codec.Decode(c.BeginParse()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		_, _ = withGenericPlan(reflect.TypeOf(testCodecRecord{}), func() (*cell.Cell, error) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := Load[testCodecRecord](c.BeginParse()); err != nil {
+					b.Fatal(err)
+				}
+			}
+			return nil, nil
+		})
+	})
+}
@@ -0,0 +1,95 @@
+package tlb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// refSliceTag - checks that tag is '^' or '^ max:N' on slice field, and returns max number of elements (0 when unlimited)
+func refSliceTag(field reflect.StructField, settings []string) (int, bool) {
+	if settings[0] != "^" || field.Type.Kind() != reflect.Slice || field.Type.Elem().Kind() == reflect.Uint8 {
+		return 0, false
+	}
+
+	switch {
+	case len(settings) == 1:
+		return 0, true
+	case len(settings) == 2 && strings.HasPrefix(settings[1], "max:"):
+		limit, err := strconv.Atoi(settings[1][len("max:"):])
+		if err != nil || limit <= 0 {
+			// we panic, because its developer's issue, need to fix tag
+			panic(fmt.Sprintf("corrupted max in tag of '%s'", field.Name))
+		}
+		return limit, true
+	}
+	return 0, false
+}
+
+// loadRefSlice - loads element from each ref until refs are exhausted or limit is reached
+func loadRefSlice(ctx context.Context, field reflect.StructField, fieldVal reflect.Value, limit int, loader *cell.Slice) error {
+	elemTyp := field.Type.Elem()
+
+	arr := reflect.MakeSlice(field.Type, 0, loader.RefsNum())
+	for loader.RefsNum() > 0 && (limit == 0 || arr.Len() < limit) {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("decoding interrupted at element %d of %s: %w", arr.Len(), field.Name, err)
+		}
+
+		ref, err := loader.LoadRef()
+		if err != nil {
+			return fmt.Errorf("failed to load ref of element %d of %s, err: %w", arr.Len(), field.Name, err)
+		}
+
+		var nVal reflect.Value
+		if elemTyp == reflect.TypeOf(&cell.Cell{}) {
+			c, err := ref.ToCell()
+			if err != nil {
+				return fmt.Errorf("failed to convert ref of element %d of %s to cell, err: %w", arr.Len(), field.Name, err)
+			}
+			nVal = reflect.ValueOf(c)
+		} else if ref, err = checkExotic(ctx, ref); err != nil {
+			return fmt.Errorf("failed to load ref of element %d of %s, err: %w", arr.Len(), field.Name, err)
+		} else if nVal, err = structLoad(ctx, elemTyp, ref); err != nil {
+			err = withIndex(keepFieldError(err, fmt.Errorf("failed to load element %d of %s, err: %w", arr.Len(), field.Name, err)), arr.Len(), 0)
+			return inRef(err, loader.RefsOffset()-1, 0)
+		} else if err = checkConsumed(ctx, nVal, ref); err != nil {
+			err = withIndex(keepFieldError(err, fmt.Errorf("failed to load element %d of %s, err: %w", arr.Len(), field.Name, err)), arr.Len(), ref.BitsOffset())
+			return inRef(err, loader.RefsOffset()-1, ref.BitsOffset())
+		}
+		arr = reflect.Append(arr, nVal)
+	}
+
+	fieldVal.Set(arr)
+	return nil
+}
+
+// storeRefSlice - stores each element to its own ref, reverse of loadRefSlice
+func storeRefSlice(field reflect.StructField, fieldVal reflect.Value, limit int, builder *cell.Builder, options storeOptions, audit *auditor) error {
+	if limit > 0 && fieldVal.Len() > limit {
+		return fmt.Errorf("%s has %d elements, max is %d", field.Name, fieldVal.Len(), limit)
+	}
+
+	for j := 0; j < fieldVal.Len(); j++ {
+		elem := fieldVal.Index(j)
+
+		var c *cell.Cell
+		if elem.Type() == reflect.TypeOf(&cell.Cell{}) {
+			c = elem.Interface().(*cell.Cell)
+		} else {
+			var err error
+			if c, err = structStore(elem, elem.Type().Name(), options, audit.nested(fmt.Sprintf("%s[%d]", field.Name, j))); err != nil {
+				return withIndex(err, j, 0)
+			}
+		}
+
+		if err := builder.StoreRef(c); err != nil {
+			return fmt.Errorf("failed to store element %d of %s to ref, err: %w", j, field.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package tlb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testRefSliceItem struct {
+	Val uint32 `tlb:"## 32"`
+}
+
+type testRefSlice struct {
+	Head  uint8              `tlb:"## 8"`
+	Items []testRefSliceItem `tlb:"^ max:2"`
+	Rest  []*cell.Cell       `tlb:"^"`
+}
+
+func TestLoadFromCellRefSlice(t *testing.T) {
+	refs := []*cell.Cell{
+		cell.BeginCell().MustStoreUInt(1, 32).EndCell(),
+		cell.BeginCell().MustStoreUInt(2, 32).EndCell(),
+		cell.BeginCell().MustStoreUInt(3, 8).EndCell(),
+	}
+
+	b := cell.BeginCell().MustStoreUInt(9, 8)
+	for _, r := range refs {
+		b.MustStoreRef(r)
+	}
+	a := b.EndCell()
+
+	var x testRefSlice
+	if err := LoadFromCell(&x, a.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.Items) != 2 || x.Items[0].Val != 1 || x.Items[1].Val != 2 || len(x.Rest) != 1 || !bytes.Equal(x.Rest[0].Hash(), refs[2].Hash()) {
+		t.Fatal("not eq")
+	}
+
+	c, err := ToCell(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("cell hashes not same after From to")
+	}
+
+	x.Items = append(x.Items, testRefSliceItem{Val: 3})
+	if _, err = ToCell(x); err == nil {
+		t.Fatal("more than max elements should fail")
+	}
+}
@@ -0,0 +1,295 @@
+package tlb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// ErrNoMatchingType - returned when no registered or union candidate type has magic matching the data
+var ErrNoMatchingType = errors.New("no matching type for magic")
+
+type registeredMagic struct {
+	name  string
+	typ   reflect.Type
+	magic magicBits
+
+	// versioned types are used only when decoding at point within [from, to]
+	versioned bool
+	from, to  uint32
+}
+
+var registry = struct {
+	mx       sync.RWMutex
+	types    map[string]reflect.Type
+	versions []registeredMagic
+	// sorted by magic size, longest first
+	magics []registeredMagic
+}{
+	types: map[string]reflect.Type{},
+}
+
+// Register - registers struct type under name, so it can be referenced in union tags,
+// and types with Magic can be decoded using LoadAny.
+// Prototype can be a value or a pointer to struct, for example Register("InternalMessage", InternalMessage{})
+func Register(name string, prototype any) {
+	typ := reflect.TypeOf(prototype)
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ == nil || typ.Kind() != reflect.Struct {
+		panic("registered prototype should be a struct")
+	}
+
+	registry.mx.Lock()
+	defer registry.mx.Unlock()
+
+	registry.types[name] = typ
+	rebuildMagics()
+}
+
+// RegisterVersion - registers struct type with Magic which is valid only for range [from, to] (inclusive)
+// of block seqno or utime, to decode messages of contracts which changed format over time.
+// Such types are used only by LoadAnyAt, and are preferred over types registered using Register with the same magic.
+// Ranges of versions with the same magic should not overlap, use math.MaxUint32 as 'to' for open range.
+func RegisterVersion(name string, prototype any, from, to uint32) {
+	typ := reflect.TypeOf(prototype)
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ == nil || typ.Kind() != reflect.Struct {
+		panic("registered prototype should be a struct")
+	}
+
+	if from > to {
+		panic("incorrect version range")
+	}
+
+	magics, ok := magicOf(typ)
+	if !ok {
+		panic("versioned prototype should have magic")
+	}
+
+	registry.mx.Lock()
+	defer registry.mx.Unlock()
+
+	for _, magic := range magics {
+		registry.versions = append(registry.versions, registeredMagic{
+			name: name, typ: typ, magic: magic,
+			versioned: true, from: from, to: to,
+		})
+	}
+	rebuildMagics()
+}
+
+// rebuildMagics - must be called under registry lock
+func rebuildMagics() {
+	magics := make([]registeredMagic, 0, len(registry.types)+len(registry.versions))
+	for n, t := range registry.types {
+		if alts, ok := magicOf(t); ok {
+			for _, magic := range alts {
+				magics = append(magics, registeredMagic{name: n, typ: t, magic: magic})
+			}
+		}
+	}
+	magics = append(magics, registry.versions...)
+
+	sort.SliceStable(magics, func(i, j int) bool {
+		if magics[i].magic.sz != magics[j].magic.sz {
+			return magics[i].magic.sz > magics[j].magic.sz
+		}
+		if magics[i].versioned != magics[j].versioned {
+			return magics[i].versioned
+		}
+		return magics[i].name < magics[j].name
+	})
+	registry.magics = magics
+}
+
+// LoadAny - peeks magic from loader and decodes registered type with matching magic,
+// returns pointer to decoded struct. When few types are matching, one with the longest magic is used.
+// Returns ErrNoMatchingType if nothing matches, decodes of matched type are limited by limits set using SetLimits.
+func LoadAny(loader *cell.Slice) (any, error) {
+	return LoadAnyContext(context.Background(), loader)
+}
+
+// LoadAnyContext - the same as LoadAny, but options set using WithOptions in ctx are applied to decoding
+func LoadAnyContext(ctx context.Context, loader *cell.Slice) (any, error) {
+	v, _, err := loadAny(ctx, loader, 0, false)
+	return v, err
+}
+
+// LoadAnyNamed - the same as LoadAny, but also returns name under which decoded type was registered
+func LoadAnyNamed(loader *cell.Slice) (any, string, error) {
+	return LoadAnyNamedContext(context.Background(), loader)
+}
+
+// LoadAnyNamedContext - the same as LoadAnyNamed, but options set using WithOptions in ctx are applied to decoding
+func LoadAnyNamedContext(ctx context.Context, loader *cell.Slice) (any, string, error) {
+	return loadAny(ctx, loader, 0, false)
+}
+
+// LoadAnyAt - the same as LoadAny, but also considers types registered using RegisterVersion,
+// which range contains at (block seqno or utime, the same as used on registration)
+func LoadAnyAt(loader *cell.Slice, at uint32) (any, error) {
+	v, _, err := loadAny(context.Background(), loader, at, true)
+	return v, err
+}
+
+func loadAny(ctx context.Context, loader *cell.Slice, at uint32, hasAt bool) (any, string, error) {
+	registry.mx.RLock()
+	magics := registry.magics
+	registry.mx.RUnlock()
+
+	for _, m := range magics {
+		if m.versioned && (!hasAt || at < m.from || at > m.to) {
+			continue
+		}
+
+		ok, err := m.magic.match(loader)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err = acquireDecode(m.name); err != nil {
+			return nil, "", err
+		}
+
+		nVal, err := structLoad(ctx, reflect.PtrTo(m.typ), loader)
+		if err == nil {
+			err = checkConsumed(ctx, nVal, loader)
+		}
+		recordDecode(m.name, err)
+
+		if err != nil {
+			return nil, "", err
+		}
+		return nVal.Interface(), m.name, nil
+	}
+
+	return nil, "", ErrNoMatchingType
+}
+
+func registeredType(name string) reflect.Type {
+	registry.mx.RLock()
+	defer registry.mx.RUnlock()
+
+	typ, ok := registry.types[name]
+	if !ok {
+		// we panic, because its developer's issue, need to register type
+		panic(fmt.Sprintf("type '%s' is not registered", name))
+	}
+	return typ
+}
+
+// lookupRegistered - returns registered type by name, without panic when it is not registered
+func lookupRegistered(name string) (reflect.Type, bool) {
+	registry.mx.RLock()
+	defer registry.mx.RUnlock()
+
+	typ, ok := registry.types[name]
+	return typ, ok
+}
+
+// registeredName - returns name under which struct type is registered, first in sorted order if there are several
+func registeredName(typ reflect.Type) (string, bool) {
+	registry.mx.RLock()
+	defer registry.mx.RUnlock()
+
+	var names []string
+	for name, t := range registry.types {
+		if t == typ {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return "", false
+	}
+	sort.Strings(names)
+	return names[0], true
+}
+
+// magicOf - returns accepted magics of struct type, declared in tag of its Magic field
+func magicOf(typ reflect.Type) ([]magicBits, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Type == reflect.TypeOf(Magic{}) {
+			return parseMagics(fieldTag(field)), true
+		}
+	}
+	return nil, false
+}
+
+func unionLoad(ctx context.Context, iface reflect.Type, names []string, loader *cell.Slice) (reflect.Value, error) {
+	for _, name := range names {
+		typ := registeredType(name)
+
+		magics, ok := magicOf(typ)
+		if !ok {
+			panic(fmt.Sprintf("type '%s' used in union has no magic", name))
+		}
+
+		// peek magic without loading
+		var match bool
+		for _, magic := range magics {
+			var err error
+			if match, err = magic.match(loader); err != nil {
+				return reflect.Value{}, err
+			}
+
+			if match {
+				break
+			}
+		}
+
+		if !match {
+			continue
+		}
+
+		// we prefer pointers, because methods of interface are usually implemented on them
+		fieldTyp := reflect.PtrTo(typ)
+		if !fieldTyp.Implements(iface) {
+			if !typ.Implements(iface) {
+				panic(fmt.Sprintf("type '%s' not implements %s", name, iface.String()))
+			}
+			fieldTyp = typ
+		}
+
+		return structLoad(ctx, fieldTyp, loader)
+	}
+
+	return reflect.Value{}, ErrNoMatchingType
+}
+
+func unionStore(fieldVal reflect.Value, names []string, options storeOptions, audit *auditor) (*cell.Cell, error) {
+	if fieldVal.IsNil() {
+		return nil, errors.New("union value should not be nil")
+	}
+
+	val := fieldVal.Elem()
+	typ := val.Type()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	for _, name := range names {
+		if registeredType(name) == typ {
+			audit.record("", "union stored as "+name)
+			return structStore(val, name, options, audit)
+		}
+	}
+
+	return nil, fmt.Errorf("type %s is not in union", typ.String())
+}
@@ -0,0 +1,115 @@
+package tlb
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testRegistryShort struct {
+	_   Magic  `tlb:"#7e"`
+	Val uint16 `tlb:"## 16"`
+}
+
+type testRegistryLong struct {
+	_   Magic  `tlb:"#7e01"`
+	Val uint32 `tlb:"## 32"`
+}
+
+func TestLoadAny(t *testing.T) {
+	Register("TestRegistryShort", testRegistryShort{})
+	Register("TestRegistryLong", &testRegistryLong{})
+
+	v, err := LoadAny(cell.BeginCell().MustStoreUInt(0x7e01, 16).MustStoreUInt(99, 32).EndCell().BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	long, ok := v.(*testRegistryLong)
+	if !ok || long.Val != 99 {
+		t.Fatal("long magic type should be chosen")
+	}
+
+	v, err = LoadAny(cell.BeginCell().MustStoreUInt(0x7e, 8).MustStoreUInt(5, 16).EndCell().BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if short, ok := v.(*testRegistryShort); !ok || short.Val != 5 {
+		t.Fatal("short magic type should be chosen")
+	}
+
+	_, err = LoadAny(cell.BeginCell().MustStoreUInt(0xFFFFFFFF, 32).EndCell().BeginParse())
+	if !errors.Is(err, ErrNoMatchingType) {
+		t.Fatal("should be no matching type error, got", err)
+	}
+}
+
+type testRegistryPanic struct {
+	_     Magic           `tlb:"#7d"`
+	Inner testPanicLoader `tlb:"."`
+}
+
+func TestLoadAnyContext(t *testing.T) {
+	Register("TestRegistryPanic", testRegistryPanic{})
+
+	short := cell.BeginCell().MustStoreUInt(0x7d, 8).MustStoreUInt(7, 8).EndCell()
+	_, err := LoadAnyContext(WithOptions(context.Background(), Options{NoPanic: true}), short.BeginParse())
+	if !errors.Is(err, ErrLoaderPanic) {
+		t.Fatal("should fail with loader panic error", err)
+	}
+
+	long := cell.BeginCell().MustStoreUInt(0x7d, 8).MustStoreUInt(7, 32).MustStoreUInt(1, 1).EndCell()
+	if _, err = LoadAny(long.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, name, err := LoadAnyNamedContext(WithOptions(context.Background(), Options{Strict: true}), long.BeginParse())
+	if !errors.Is(err, ErrNotFullyConsumed) {
+		t.Fatal("should fail with not fully consumed error", name, err)
+	}
+}
+
+type testRegistryTransferV1 struct {
+	_      Magic  `tlb:"#5d02"`
+	Amount uint32 `tlb:"## 32"`
+}
+
+type testRegistryTransferV2 struct {
+	_      Magic  `tlb:"#5d02"`
+	Amount uint16 `tlb:"## 16"`
+	Mode   uint16 `tlb:"## 16"`
+}
+
+func TestLoadAnyAt(t *testing.T) {
+	RegisterVersion("TestRegistryTransfer", testRegistryTransferV1{}, 0, 999)
+	RegisterVersion("TestRegistryTransfer", testRegistryTransferV2{}, 1000, math.MaxUint32)
+
+	body := cell.BeginCell().MustStoreUInt(0x5d02, 16).MustStoreUInt(0x00050001, 32).EndCell()
+
+	v, err := LoadAnyAt(body.BeginParse(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v1, ok := v.(*testRegistryTransferV1); !ok || v1.Amount != 0x00050001 {
+		t.Fatal("v1 should be chosen", v)
+	}
+
+	v, err = LoadAnyAt(body.BeginParse(), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v2, ok := v.(*testRegistryTransferV2); !ok || v2.Amount != 5 || v2.Mode != 1 {
+		t.Fatal("v2 should be chosen", v)
+	}
+
+	_, err = LoadAny(body.BeginParse())
+	if !errors.Is(err, ErrNoMatchingType) {
+		t.Fatal("versioned types should not be used without point, got", err)
+	}
+}
@@ -0,0 +1,74 @@
+package tlb
+
+import (
+	"fmt"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// Resign - replaces bits of the region in cell with new signature, without full re-serialization.
+// Region can be taken from Marks of the struct loaded from this cell, for example:
+//
+//	type WalletBody struct {
+//		Signature []byte     `tlb:"bits 512 mark:sig"`
+//		Payload   *cell.Cell `tlb:"remaining"`
+//		Marks     Marks      `tlb:"-"`
+//	}
+//
+// Region should belong to the root of the cell, refs are kept as is.
+func Resign(c *cell.Cell, region Region, sig []byte) (*cell.Cell, error) {
+	if region.BitsOffset+region.Bits > c.BitsSize() {
+		return nil, fmt.Errorf("region is out of cell bounds")
+	}
+
+	if uint(len(sig))*8 < region.Bits {
+		return nil, fmt.Errorf("signature is too short for region, want %d bits", region.Bits)
+	}
+
+	loader := c.BeginParse()
+
+	before, err := loader.LoadSlice(region.BitsOffset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data before region: %w", err)
+	}
+
+	if _, err = loader.LoadSlice(region.Bits); err != nil {
+		return nil, fmt.Errorf("failed to skip region: %w", err)
+	}
+
+	afterSz, after, err := loader.RestBits()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data after region: %w", err)
+	}
+
+	b := cell.BeginCell()
+	if err = b.StoreSlice(before, region.BitsOffset); err != nil {
+		return nil, fmt.Errorf("failed to store data before region: %w", err)
+	}
+
+	if err = b.StoreSlice(sig, region.Bits); err != nil {
+		return nil, fmt.Errorf("failed to store signature: %w", err)
+	}
+
+	if err = b.StoreSlice(after, afterSz); err != nil {
+		return nil, fmt.Errorf("failed to store data after region: %w", err)
+	}
+
+	for loader.RefsNum() > 0 {
+		ref, err := loader.LoadRef()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load ref: %w", err)
+		}
+
+		refCell, err := ref.ToCell()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert ref to cell: %w", err)
+		}
+
+		if err = b.StoreRef(refCell); err != nil {
+			return nil, fmt.Errorf("failed to store ref: %w", err)
+		}
+	}
+
+	return b.EndCell(), nil
+}
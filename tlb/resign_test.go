@@ -0,0 +1,44 @@
+package tlb
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testSignedBody struct {
+	Signature []byte     `tlb:"bits 512 mark:sig"`
+	Payload   *cell.Cell `tlb:"remaining"`
+	Marks     Marks      `tlb:"-"`
+}
+
+func TestResign(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+
+	payload := cell.BeginCell().MustStoreUInt(698983191, 32).MustStoreUInt(1, 32).
+		MustStoreRef(cell.BeginCell().MustStoreUInt(7, 8).EndCell())
+	body := cell.BeginCell().MustStoreSlice(make([]byte, 64), 512).MustStoreBuilder(payload).EndCell()
+
+	var x testSignedBody
+	if err := LoadFromCell(&x, body.BeginParse()); err != nil {
+		t.Fatal(err)
+	}
+
+	sig := x.Payload.Sign(key)
+
+	signed, err := Resign(body, x.Marks["sig"], sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := cell.BeginCell().MustStoreSlice(sig, 512).MustStoreBuilder(payload).EndCell()
+	if !bytes.Equal(signed.Hash(), expected.Hash()) {
+		t.Fatal("resigned cell not eq")
+	}
+
+	if _, err = Resign(body, x.Marks["sig"], sig[:10]); err == nil {
+		t.Fatal("should fail on short signature")
+	}
+}
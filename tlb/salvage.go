@@ -0,0 +1,36 @@
+package tlb
+
+import (
+	"context"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// Salvage - result of LoadFromCellSalvage, describes how much of the struct was loaded
+type Salvage struct {
+	// Loaded - names of successfully loaded fields, in order of loading
+	Loaded []string
+	// FailedField - name of the first field which was failed to load, empty when all fields are loaded
+	FailedField string
+	// Err - reason of the failure
+	Err error
+	// Remainder - undecoded data starting from the failed field, nil when all fields are loaded
+	Remainder *cell.Slice
+}
+
+// Complete - returns true if all fields were loaded
+func (s *Salvage) Complete() bool {
+	return s.FailedField == ""
+}
+
+// LoadFromCellSalvage - loads as many leading fields of v as possible, instead of failing on the first error.
+// Fields starting from the failed one are not loaded, the failed field can be partially filled.
+// Error is returned only when v is not a valid struct pointer, load failures are reported in Salvage.
+// Useful for exploring bodies of unknown contracts, when only the beginning of the schema is known.
+func LoadFromCellSalvage(v any, loader *cell.Slice) (*Salvage, error) {
+	salvage := &Salvage{}
+	if err := loadFromCell(context.Background(), v, loader, salvage); err != nil {
+		return nil, err
+	}
+	return salvage, nil
+}
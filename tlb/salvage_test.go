@@ -0,0 +1,50 @@
+package tlb
+
+import (
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testSalvage struct {
+	_       Magic      `tlb:"#0f8a7ea5"`
+	QueryID uint64     `tlb:"## 64"`
+	Amount  Coins      `tlb:"."`
+	Kind    uint8      `tlb:"## 8 assert:1"`
+	Payload *cell.Cell `tlb:"^"`
+}
+
+func TestLoadFromCellSalvage(t *testing.T) {
+	c := cell.BeginCell().MustStoreUInt(0x0f8a7ea5, 32).MustStoreUInt(7, 64).
+		MustStoreBigCoins(MustFromTON("1.5").NanoTON()).MustStoreUInt(2, 8).MustStoreUInt(0xFF, 8).EndCell()
+
+	var x testSalvage
+	s, err := LoadFromCellSalvage(&x, c.BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Complete() || s.FailedField != "Kind" || s.Err == nil {
+		t.Fatal("incorrect failed field", s.FailedField)
+	}
+
+	if len(s.Loaded) != 3 || x.QueryID != 7 || x.Amount.NanoTON().Uint64() != 1500000000 {
+		t.Fatal("loaded prefix not eq", s.Loaded)
+	}
+
+	if s.Remainder.BitsLeft() != 16 || s.Remainder.MustLoadUInt(16) != 0x02FF {
+		t.Fatal("remainder not eq")
+	}
+
+	c = cell.BeginCell().MustStoreUInt(0x0f8a7ea5, 32).MustStoreUInt(7, 64).
+		MustStoreBigCoins(MustFromTON("1.5").NanoTON()).MustStoreUInt(1, 8).MustStoreRef(cell.BeginCell().EndCell()).EndCell()
+
+	s, err = LoadFromCellSalvage(&x, c.BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !s.Complete() || s.Remainder != nil || len(s.Loaded) != 5 {
+		t.Fatal("should be complete")
+	}
+}
@@ -0,0 +1,50 @@
+package tlb
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// dataTag - handlers of data tag keyword, loader, storer and Check are dispatched using the same table,
+// so new tag cannot be supported by one of them and missed by others
+type dataTag struct {
+	load  func(ctx context.Context, rv reflect.Value, i int, settings []string, loader *cell.Slice) error
+	store func(field reflect.StructField, fieldVal reflect.Value, settings []string, builder *cell.Builder, options storeOptions, audit *auditor) error
+	check func(c *checker, rv reflect.Value, field reflect.StructField, settings []string)
+}
+
+// dataTags - handlers of data tags by keyword, modifiers like maybe, either and try are handled before it,
+// it is filled in init, because handlers of nested tags refer to loadField and storeField which use it
+var dataTags map[string]dataTag
+
+func init() {
+	dataTags = map[string]dataTag{
+		"##":        {loadIntTag, storeIntTag, (*checker).checkIntTag},
+		"addr":      {loadAddrTag, storeAddrTag, (*checker).checkAddrTag},
+		"bool":      {loadBoolTag, storeBoolTag, (*checker).checkBoolTag},
+		"flags":     {loadFlagsTag, storeFlagsTag, (*checker).checkFlagsTag},
+		"timestamp": {loadTimestampTag, storeTimestampTag, (*checker).checkTimestampTag},
+		"str":       {loadStrTag, storeStrTag, (*checker).checkStrTag},
+		"skip":      {loadSkipTag, storeSkipTag, (*checker).checkSkipTag},
+		"pad":       {loadPadTag, storePadTag, (*checker).checkPadTag},
+		"align":     {loadPadTag, storePadTag, (*checker).checkPadTag},
+		"unary":     {loadUnaryTag, storeUnaryTag, (*checker).checkUnaryTag},
+		"bits":      {loadBitsTag, storeBitsTag, (*checker).checkBitsTag},
+		"hash":      {loadHashTag, storeHashTag, (*checker).checkHashTag},
+		"^":         {loadRefTag, storeRefTag, (*checker).checkRefTag},
+		".":         {loadInnerTag, storeInnerTag, (*checker).checkInnerTag},
+		"union":     {loadUnionTag, storeUnionTag, (*checker).checkUnionTag},
+		"remaining": {loadRemainingTag, storeRemainingTag, (*checker).checkRemainingTag},
+		"unknown":   {loadUnknownTag, storeUnknownTag, (*checker).checkUnknownTag},
+		"coins":     {loadCoinsTag, storeCoinsTag, (*checker).checkCoinsTag},
+		"cell":      {loadCellTag, storeCellTag, (*checker).checkCellTag},
+		"refs":      {loadRefsTag, storeRefsTag, (*checker).checkRefsTag},
+		"repeat":    {loadRepeatTag, storeRepeatTag, (*checker).checkRepeatTag},
+		"chunked":   {loadChunkedTag, storeChunkedTag, (*checker).checkChunkedTag},
+		"pfxdict":   {loadPfxDictTag, storePfxDictTag, (*checker).checkPfxDictTag},
+		"dictaug":   {loadDictAugTag, storeDictAugTag, (*checker).checkDictAugTag},
+		"dict":      {loadDictTag, storeDictTag, (*checker).checkDictTag},
+	}
+}
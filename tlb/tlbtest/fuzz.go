@@ -0,0 +1,72 @@
+package tlbtest
+
+import (
+	"embed"
+	"reflect"
+
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+//go:embed seeds/*.boc
+var seeds embed.FS
+
+// Seeds - returns seed corpus of real BOCs (account state and blocks), to be added to fuzz tests using f.Add
+func Seeds() [][]byte {
+	entries, err := seeds.ReadDir("seeds")
+	if err != nil {
+		panic(err)
+	}
+
+	res := make([][]byte, 0, len(entries))
+	for _, e := range entries {
+		data, err := seeds.ReadFile("seeds/" + e.Name())
+		if err != nil {
+			panic(err)
+		}
+		res = append(res, data)
+	}
+	return res
+}
+
+// FuzzLoad - fuzz entry point, parses data as BOC and decodes its root to each of types,
+// values of types are used only as prototypes, they can be structs or pointers to structs.
+// When types are empty, types registered using tlb.Register are tried with tlb.LoadAny.
+// Decoded values are serialized back using tlb.ToCellSafe, so load-only types are supported too.
+// Errors are expected for random data, only panics are reported by fuzzer,
+// so definitions and loaders of types should not panic on any input. Example:
+//
+//	func FuzzMyTypes(f *testing.F) {
+//		for _, s := range tlbtest.Seeds() {
+//			f.Add(s)
+//		}
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			tlbtest.FuzzLoad([]any{MyMsg{}, MyState{}}, data)
+//		})
+//	}
+func FuzzLoad(types []any, data []byte) {
+	root, err := cell.FromBOC(data)
+	if err != nil {
+		return
+	}
+
+	if len(types) == 0 {
+		if v, err := tlb.LoadAny(root.BeginParse()); err == nil {
+			_, _ = tlb.ToCellSafe(v)
+		}
+		return
+	}
+
+	for _, proto := range types {
+		typ := reflect.TypeOf(proto)
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		v := reflect.New(typ).Interface()
+		if err = decode(v, root.BeginParse()); err != nil {
+			continue
+		}
+		_, _ = tlb.ToCellSafe(v)
+	}
+}
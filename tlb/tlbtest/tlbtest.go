@@ -0,0 +1,126 @@
+// Package tlbtest contains helpers to test types serialized using tlb package,
+// like round trip check and golden cell assertions.
+package tlbtest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/api"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// RoundTrip - serializes v, parses it into a fresh value of the same type and compares them using tlb.Diff,
+// then checks that parsed value is serialized to the same cell, returns serialized cell.
+// v can be struct or pointer to struct, Marshaler and Unmarshaler of its type are used when implemented.
+// Blank fields (like Magic), Marks fields and fields with 'cell' tag are not compared, because they are filled only on load.
+func RoundTrip(t testing.TB, v any) *cell.Cell {
+	t.Helper()
+	return RoundTripCodec(t, manualCodec{}, v)
+}
+
+// RoundTripCodec - the same as RoundTrip, but v is serialized and parsed using codec,
+// to check generated or alternative codecs against the same expectations, for example api.TLB
+func RoundTripCodec(t testing.TB, codec api.Codec, v any) *cell.Cell {
+	t.Helper()
+
+	c, err := codec.Encode(v)
+	if err != nil {
+		t.Fatalf("failed to serialize %T: %v", v, err)
+	}
+
+	typ := reflect.TypeOf(v)
+	isPtr := typ.Kind() == reflect.Pointer
+	if isPtr {
+		typ = typ.Elem()
+	}
+
+	fresh := reflect.New(typ)
+	if err = codec.Decode(c.BeginParse(), fresh.Interface()); err != nil {
+		t.Fatalf("failed to parse serialized %T: %v", v, err)
+	}
+
+	got := fresh
+	if !isPtr {
+		got = fresh.Elem()
+	}
+
+	if d := tlb.Diff(v, got.Interface()); d != "" {
+		t.Fatalf("parsed value is not equal to original, difference at %s", d)
+	}
+
+	again, err := codec.Encode(got.Interface())
+	if err != nil {
+		t.Fatalf("failed to serialize parsed %T: %v", v, err)
+	}
+
+	if !bytes.Equal(again.Hash(), c.Hash()) {
+		t.Fatalf("parsed value is serialized differently:\n%s\nwant:\n%s", again.Dump(), c.Dump())
+	}
+	return c
+}
+
+// AssertCell - checks that v is serialized to the cell equal to want
+func AssertCell(t testing.TB, v any, want *cell.Cell) {
+	t.Helper()
+
+	c, err := encode(v)
+	if err != nil {
+		t.Fatalf("failed to serialize %T: %v", v, err)
+	}
+
+	if !bytes.Equal(c.Hash(), want.Hash()) {
+		t.Fatalf("%T is serialized to:\n%s\nwant:\n%s", v, c.Dump(), want.Dump())
+	}
+}
+
+// AssertBOC - checks that v is serialized to the cell equal to the root of hex encoded BOC
+func AssertBOC(t testing.TB, v any, wantHex string) {
+	t.Helper()
+
+	data, err := hex.DecodeString(wantHex)
+	if err != nil {
+		t.Fatalf("incorrect hex of golden boc: %v", err)
+	}
+
+	want, err := cell.FromBOC(data)
+	if err != nil {
+		t.Fatalf("incorrect golden boc: %v", err)
+	}
+	AssertCell(t, v, want)
+}
+
+// manualCodec - codec which uses Marshaler and Unmarshaler (or custom ToCell and LoadFromCell) of the type
+// when they are implemented, and struct tags otherwise
+type manualCodec struct{}
+
+func (manualCodec) Encode(v any) (*cell.Cell, error) {
+	return encode(v)
+}
+
+func (manualCodec) Decode(loader *cell.Slice, v any) error {
+	return decode(v, loader)
+}
+
+func encode(v any) (*cell.Cell, error) {
+	switch m := v.(type) {
+	case tlb.Marshaler:
+		return m.MarshalTLB()
+	case interface{ ToCell() (*cell.Cell, error) }:
+		return m.ToCell()
+	}
+	return tlb.ToCell(v)
+}
+
+func decode(v any, loader *cell.Slice) error {
+	switch m := v.(type) {
+	case tlb.Unmarshaler:
+		return m.UnmarshalTLB(loader)
+	case interface{ LoadFromCell(*cell.Slice) error }:
+		return m.LoadFromCell(loader)
+	}
+	return tlb.LoadFromCell(v, loader)
+}
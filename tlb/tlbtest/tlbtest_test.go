@@ -0,0 +1,127 @@
+package tlbtest
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/api"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testInner struct {
+	Val uint16 `tlb:"## 16"`
+}
+
+type testStruct struct {
+	_       tlb.Magic           `tlb:"#0badf00d"`
+	ID      uint32              `tlb:"## 32"`
+	Amount  tlb.Coins           `tlb:"coins"`
+	Big     *big.Int            `tlb:"## 128"`
+	Owner   *address.Address    `tlb:"addr"`
+	At      time.Time           `tlb:"timestamp 32"`
+	Payload *cell.Cell          `tlb:"maybe ^"`
+	Items   map[uint8]testInner `tlb:"dict 8 -> map ^"`
+	Inner   *testInner          `tlb:"^"`
+}
+
+// brokenStruct - Val is stored as 16 bits, but loaded as 8
+type brokenStruct struct {
+	Val uint16 `tlb:"## 8"`
+}
+
+func (b brokenStruct) ToCell() (*cell.Cell, error) {
+	return cell.BeginCell().MustStoreUInt(uint64(b.Val), 16).EndCell(), nil
+}
+
+type fakeTB struct {
+	testing.TB
+	msg string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.msg = fmt.Sprintf(format, args...)
+	panic(f)
+}
+
+func TestRoundTrip(t *testing.T) {
+	v := testStruct{
+		ID:      7,
+		Amount:  tlb.MustFromTON("1.5"),
+		Big:     new(big.Int).Lsh(big.NewInt(1), 100),
+		Owner:   address.MustParseAddr("EQC6KV4zs8TJtSZapOrRFmqSkxzpq-oSCoxekQRKElf4nC1I"),
+		At:      time.Unix(1700000000, 0),
+		Payload: cell.BeginCell().MustStoreUInt(1, 8).EndCell(),
+		Items:   map[uint8]testInner{1: {Val: 10}, 5: {Val: 50}},
+		Inner:   &testInner{Val: 3},
+	}
+
+	c := RoundTrip(t, v)
+	RoundTrip(t, &v)
+	AssertCell(t, v, c)
+	AssertBOC(t, testInner{Val: 0xABCD}, "b5ee9c72410101010004000004abcd1e8f5994")
+}
+
+// truncatingCodec - alternative codec which loses high byte of values
+type truncatingCodec struct{}
+
+func (truncatingCodec) Encode(v any) (*cell.Cell, error) {
+	return cell.BeginCell().MustStoreUInt(uint64(v.(testInner).Val&0xFF), 16).EndCell(), nil
+}
+
+func (truncatingCodec) Decode(loader *cell.Slice, v any) error {
+	v.(*testInner).Val = uint16(loader.MustLoadUInt(16))
+	return nil
+}
+
+func TestRoundTripCodec(t *testing.T) {
+	c := RoundTripCodec(t, api.TLB, testInner{Val: 0xABCD})
+	AssertCell(t, testInner{Val: 0xABCD}, c)
+
+	f := &fakeTB{TB: t}
+	func() {
+		defer func() { recover() }()
+		RoundTripCodec(f, truncatingCodec{}, testInner{Val: 0x1234})
+	}()
+
+	if f.msg == "" {
+		t.Fatal("should fail")
+	}
+}
+
+func TestRoundTripMismatch(t *testing.T) {
+	f := &fakeTB{TB: t}
+	func() {
+		defer func() { recover() }()
+		RoundTrip(f, brokenStruct{Val: 0x1234})
+	}()
+
+	if f.msg == "" {
+		t.Fatal("should fail")
+	}
+
+	f.msg = ""
+	func() {
+		defer func() { recover() }()
+		AssertCell(f, testInner{Val: 1}, cell.BeginCell().MustStoreUInt(2, 16).EndCell())
+	}()
+
+	if f.msg == "" {
+		t.Fatal("should fail")
+	}
+}
+
+func FuzzShippedTypes(f *testing.F) {
+	for _, s := range Seeds() {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		FuzzLoad([]any{tlb.AccountState{}, tlb.Block{}, tlb.Message{}, tlb.StateInit{}, testStruct{}}, data)
+	})
+}
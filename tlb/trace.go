@@ -0,0 +1,57 @@
+package tlb
+
+import (
+	"reflect"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// FieldTrace - loaded or stored field, passed to Options.Trace on load and to callback of ToCellTrace on store
+type FieldTrace struct {
+	// Type - struct type which contains the field
+	Type reflect.Type
+	// Field - name of the field
+	Field string
+	// Tag - tlb tag of the field, as it is declared
+	Tag string
+	// Depth - nesting level of the struct, 0 for the root one
+	Depth int
+	// Store - true when field was stored, false when loaded
+	Store bool
+	// BitsFrom, BitsTo - range of bits taken by the field, including maybe and either bits.
+	// On load offsets are in the cell being loaded, inner structs loaded using '.' share the cell with outer struct.
+	// On store offsets are in the cell of the struct, inner structs are built in their own cells.
+	BitsFrom, BitsTo uint
+	// RefsFrom, RefsTo - range of refs taken by the field, in the same cell as bits
+	RefsFrom, RefsTo int
+	// Value - value of the field, nil when field is not exported
+	Value any
+}
+
+// ToCellTrace - the same as ToCell, but calls trace after each field is stored, including fields of inner structs,
+// it is the store counterpart of Options.Trace. Values of dictionaries are not traced.
+func ToCellTrace(v any, trace func(FieldTrace)) (*cell.Cell, error) {
+	return toCell(v, storeOptions{}, &auditor{trace: trace})
+}
+
+func newFieldTrace(rv reflect.Value, i, depth int, store bool, bitsFrom, bitsTo uint, refsFrom, refsTo int) FieldTrace {
+	field := rv.Type().Field(i)
+
+	var val any
+	if fv := rv.Field(i); fv.CanInterface() {
+		val = fv.Interface()
+	}
+
+	return FieldTrace{
+		Type:     rv.Type(),
+		Field:    field.Name,
+		Tag:      fieldTag(field),
+		Depth:    depth,
+		Store:    store,
+		BitsFrom: bitsFrom,
+		BitsTo:   bitsTo,
+		RefsFrom: refsFrom,
+		RefsTo:   refsTo,
+		Value:    val,
+	}
+}
@@ -0,0 +1,65 @@
+package tlb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+func TestLoadFromCellTrace(t *testing.T) {
+	v := testDump{
+		Flag:    true,
+		Num:     42,
+		Inner:   testDumpInner{Val: 7},
+		Opt:     &testDumpInner{Val: 9},
+		Payload: cell.BeginCell().MustStoreUInt(7, 8).EndCell(),
+	}
+
+	var stored []string
+	c, err := ToCellTrace(v, func(ft FieldTrace) {
+		stored = append(stored, fmt.Sprintf("%d %s %d-%d %d-%d", ft.Depth, ft.Field, ft.BitsFrom, ft.BitsTo, ft.RefsFrom, ft.RefsTo))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded []string
+	var x testDump
+	err = LoadFromCellContext(WithOptions(context.Background(), Options{Trace: func(ft FieldTrace) {
+		if ft.Store {
+			t.Fatal("should be load trace")
+		}
+
+		if ft.Field == "Num" && (ft.Value != uint32(42) || ft.Tag != "## 32" || ft.Type != reflect.TypeOf(x)) {
+			t.Fatal("incorrect trace of Num", ft)
+		}
+		loaded = append(loaded, fmt.Sprintf("%d %s %d-%d %d-%d", ft.Depth, ft.Field, ft.BitsFrom, ft.BitsTo, ft.RefsFrom, ft.RefsTo))
+	}}), &x, c.BeginParse())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantStored := []string{
+		"0 _ 0-8 0-0", "0 Flag 8-9 0-0", "0 Num 9-41 0-0",
+		"1 Val 0-8 0-0", "0 Inner 41-41 0-1",
+		"1 Val 0-8 0-0", "0 Opt 41-50 1-1",
+		"0 Payload 50-50 1-2",
+	}
+	if !reflect.DeepEqual(stored, wantStored) {
+		t.Fatal("incorrect store trace", stored)
+	}
+
+	// on load inner struct stored inline shares the cell with outer one
+	wantLoaded := []string{
+		"0 _ 0-8 0-0", "0 Flag 8-9 0-0", "0 Num 9-41 0-0",
+		"1 Val 0-8 0-0", "0 Inner 41-41 0-1",
+		"1 Val 42-50 1-1", "0 Opt 41-50 1-1",
+		"0 Payload 50-50 1-2",
+	}
+	if !reflect.DeepEqual(loaded, wantLoaded) {
+		t.Fatal("incorrect load trace", loaded)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	tlbapi "github.com/xssnick/tonutils-go/api"
 	"github.com/xssnick/tonutils-go/liteclient"
 	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/tvm/cell"
 	"sync"
 	"time"
 )
@@ -68,6 +70,7 @@ type LSError struct {
 
 type APIClient struct {
 	client LiteClient
+	proofs tlbapi.ProofVerifier
 
 	curMasterUpdateTime time.Time
 	curMasterLock       sync.RWMutex
@@ -80,6 +83,31 @@ func NewAPIClient(client LiteClient) *APIClient {
 	}
 }
 
+// SetProofVerifier - sets verifier of merkle proofs returned by liteserver together with data,
+// when it is set, data is returned only after its proofs are verified. Should be called before client is used
+func (c *APIClient) SetProofVerifier(v tlbapi.ProofVerifier) {
+	c.proofs = v
+}
+
+// verifyProofs - verifies each root of proof BOC using proof verifier, when it is set, empty proof is skipped
+func (c *APIClient) verifyProofs(block *tlb.BlockInfo, proof []byte) error {
+	if c.proofs == nil || len(proof) == 0 {
+		return nil
+	}
+
+	roots, err := cell.FromBOCMultiRoot(proof)
+	if err != nil {
+		return fmt.Errorf("failed to parse proof boc: %w", err)
+	}
+
+	for _, root := range roots {
+		if err = c.proofs.VerifyProof(block, root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func loadBytes(data []byte) (loaded []byte, buffer []byte) {
 	offset := 1
 	ln := int(data[0])
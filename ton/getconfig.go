@@ -10,6 +10,9 @@ import (
 	"math/big"
 )
 
+// ErrConfigParamNotFound - returned by typed getters of BlockchainConfig when param is not present in config
+var ErrConfigParamNotFound = errors.New("config param is not present")
+
 type BlockchainConfig struct {
 	data map[int32]*cell.Cell
 }
@@ -130,3 +133,55 @@ func (b *BlockchainConfig) Get(id int32) *cell.Cell {
 func (b *BlockchainConfig) All() map[int32]*cell.Cell {
 	return b.data
 }
+
+// param - returns cell of config param id, or ErrConfigParamNotFound
+func (b *BlockchainConfig) param(id int32) (*cell.Cell, error) {
+	c := b.data[id]
+	if c == nil {
+		return nil, fmt.Errorf("%w: %d", ErrConfigParamNotFound, id)
+	}
+	return c, nil
+}
+
+// GetGlobalVersion - parses config param 8, returns ErrConfigParamNotFound if param is not present,
+// its capabilities can be passed to tlb.WithCapabilities
+func (b *BlockchainConfig) GetGlobalVersion() (*tlb.GlobalVersion, error) {
+	c, err := b.param(8)
+	if err != nil {
+		return nil, err
+	}
+
+	var ver tlb.GlobalVersion
+	if err = tlb.LoadFromCell(&ver, c.BeginParse()); err != nil {
+		return nil, fmt.Errorf("failed to parse config param 8: %w", err)
+	}
+	return &ver, nil
+}
+
+// GetSuspendedAddressList - parses config param 44, returns ErrConfigParamNotFound if param is not present
+func (b *BlockchainConfig) GetSuspendedAddressList() (*tlb.SuspendedAddressList, error) {
+	c, err := b.param(44)
+	if err != nil {
+		return nil, err
+	}
+
+	var list tlb.SuspendedAddressList
+	if err = tlb.LoadFromCell(&list, c.BeginParse()); err != nil {
+		return nil, fmt.Errorf("failed to parse config param 44: %w", err)
+	}
+	return &list, nil
+}
+
+// GetPrecompiledContracts - parses config param 45, returns ErrConfigParamNotFound if param is not present
+func (b *BlockchainConfig) GetPrecompiledContracts() (*tlb.PrecompiledContractsConfig, error) {
+	c, err := b.param(45)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg tlb.PrecompiledContractsConfig
+	if err = tlb.LoadFromCell(&cfg, c.BeginParse()); err != nil {
+		return nil, fmt.Errorf("failed to parse config param 45: %w", err)
+	}
+	return &cfg, nil
+}
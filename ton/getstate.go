@@ -42,11 +42,17 @@ func (c *APIClient) GetAccount(ctx context.Context, block *tlb.BlockInfo, addr *
 
 		var shardProof []byte
 		shardProof, resp.Data = loadBytes(resp.Data)
-		_ = shardProof
 
 		var proof []byte
 		proof, resp.Data = loadBytes(resp.Data)
-		_ = proof
+
+		if err = c.verifyProofs(b, shardProof); err != nil {
+			return nil, fmt.Errorf("failed to verify shard block proof: %w", err)
+		}
+
+		if err = c.verifyProofs(shard, proof); err != nil {
+			return nil, fmt.Errorf("failed to verify account state proof: %w", err)
+		}
 
 		var state []byte
 		state, resp.Data = loadBytes(resp.Data)
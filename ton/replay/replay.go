@@ -0,0 +1,450 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+const masterShard = -9223372036854775808
+
+const checkpointFile = "checkpoint.json"
+
+type TonAPI interface {
+	LookupBlock(ctx context.Context, workchain int32, shard int64, seqno uint32) (*tlb.BlockInfo, error)
+	GetBlockData(ctx context.Context, block *tlb.BlockInfo) (*tlb.Block, error)
+	GetBlockShardsInfo(ctx context.Context, master *tlb.BlockInfo) ([]*tlb.BlockInfo, error)
+	GetBlockTransactions(ctx context.Context, block *tlb.BlockInfo, count uint32, after ...*tlb.TransactionID) ([]*tlb.TransactionID, bool, error)
+	GetTransaction(ctx context.Context, block *tlb.BlockInfo, addr *address.Address, lt uint64) (*tlb.Transaction, error)
+}
+
+type Config struct {
+	// FromSeqno and ToSeqno - inclusive range of masterchain blocks to replay,
+	// shard blocks committed in these master blocks are processed too
+	FromSeqno uint32
+	ToSeqno   uint32
+	// Types - names of tlb registered types to keep, all decoded bodies are kept when empty
+	Types []string
+	// Dir - directory for output files and checkpoint
+	Dir string
+	// BlocksPerFile - number of masterchain blocks in one output file, 1000 when 0
+	BlocksPerFile uint32
+	// OnProgress - optional, called after each processed masterchain block
+	OnProgress func(Progress)
+}
+
+type Progress struct {
+	// Seqno - last processed masterchain block
+	Seqno uint32
+	// Processed and Total - number of processed and all masterchain blocks of the range
+	Processed uint32
+	Total     uint32
+	// Records - number of records written in this run
+	Records uint64
+}
+
+// Record - decoded message body, one line of output NDJSON file
+type Record struct {
+	MasterSeqno uint32 `json:"mc_seqno"`
+	Workchain   int32  `json:"workchain"`
+	Shard       string `json:"shard"`
+	BlockSeqno  uint32 `json:"block_seqno"`
+	Account     string `json:"account"`
+	LT          uint64 `json:"lt"`
+	TxHash      string `json:"tx_hash"`
+	// Direction - 'in' for incoming message of transaction, 'out' for outgoing
+	Direction string `json:"direction"`
+	Type      string `json:"type"`
+	Value     any    `json:"value"`
+}
+
+// MarshalJSON - serializes record, Value is serialized using tlb.MarshalJSON, so cells, addresses and coins of it are kept
+func (r Record) MarshalJSON() ([]byte, error) {
+	value, err := tlb.MarshalJSON(r.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize value: %w", err)
+	}
+
+	type record Record
+	return json.Marshal(struct {
+		record
+		Value json.RawMessage `json:"value"`
+	}{record(r), value})
+}
+
+type checkpoint struct {
+	FromSeqno uint32 `json:"from_seqno"`
+	ToSeqno   uint32 `json:"to_seqno"`
+	// Seqno - last fully written masterchain block
+	Seqno uint32 `json:"seqno"`
+	// File and Size - output file and its size after Seqno was written,
+	// used to drop partially written data on resume
+	File string `json:"file"`
+	Size int64  `json:"size"`
+}
+
+// Run - replays range of masterchain blocks, decodes bodies of transaction messages using types registered in tlb,
+// and writes them to NDJSON files in cfg.Dir, partitioned by masterchain seqno.
+// Progress is saved after each masterchain block, so when Run is called again with the same config
+// after a failure, it continues from the last written block.
+// Options set in ctx using tlb.WithOptions are applied when bodies are decoded.
+func Run(ctx context.Context, api TonAPI, cfg Config) error {
+	if cfg.FromSeqno > cfg.ToSeqno {
+		return fmt.Errorf("incorrect range")
+	}
+
+	if cfg.BlocksPerFile == 0 {
+		cfg.BlocksPerFile = 1000
+	}
+
+	types := map[string]bool{}
+	for _, t := range cfg.Types {
+		types[t] = true
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	cp, err := loadCheckpoint(cfg.Dir)
+	if err != nil {
+		return err
+	}
+
+	start := cfg.FromSeqno
+	if cp != nil {
+		if cp.FromSeqno != cfg.FromSeqno || cp.ToSeqno != cfg.ToSeqno {
+			return fmt.Errorf("checkpoint in %s is for range %d-%d", cfg.Dir, cp.FromSeqno, cp.ToSeqno)
+		}
+		start = cp.Seqno + 1
+	}
+
+	r := &replayer{
+		api:            api,
+		cfg:            cfg,
+		types:          types,
+		cp:             cp,
+		shardLastSeqno: map[string]uint32{},
+	}
+	defer r.close()
+
+	if start > 0 {
+		// init last seen shard blocks, to process only new ones
+		prev, err := api.LookupBlock(ctx, -1, masterShard, start-1)
+		if err != nil {
+			return fmt.Errorf("failed to lookup master block %d: %w", start-1, err)
+		}
+
+		shards, err := api.GetBlockShardsInfo(ctx, prev)
+		if err != nil {
+			return fmt.Errorf("failed to get shards of master block %d: %w", start-1, err)
+		}
+
+		for _, shard := range shards {
+			r.shardLastSeqno[shardID(shard)] = shard.SeqNo
+		}
+	}
+
+	var records uint64
+	for seqno := start; seqno <= cfg.ToSeqno; seqno++ {
+		n, err := r.processMaster(ctx, seqno)
+		if err != nil {
+			return fmt.Errorf("failed to process master block %d: %w", seqno, err)
+		}
+		records += n
+
+		if cfg.OnProgress != nil {
+			cfg.OnProgress(Progress{
+				Seqno:     seqno,
+				Processed: seqno - cfg.FromSeqno + 1,
+				Total:     cfg.ToSeqno - cfg.FromSeqno + 1,
+				Records:   records,
+			})
+		}
+	}
+
+	return nil
+}
+
+type replayer struct {
+	api   TonAPI
+	cfg   Config
+	types map[string]bool
+	cp    *checkpoint
+
+	shardLastSeqno map[string]uint32
+
+	file     *os.File
+	fileName string
+}
+
+func (r *replayer) processMaster(ctx context.Context, seqno uint32) (uint64, error) {
+	master, err := r.api.LookupBlock(ctx, -1, masterShard, seqno)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lookup block: %w", err)
+	}
+
+	shards, err := r.api.GetBlockShardsInfo(ctx, master)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get shards: %w", err)
+	}
+
+	blocks := []*tlb.BlockInfo{master}
+	for _, shard := range shards {
+		notSeen, err := r.notSeenShards(ctx, shard)
+		if err != nil {
+			return 0, err
+		}
+		blocks = append(blocks, notSeen...)
+	}
+
+	var buf bytes.Buffer
+	var records uint64
+	for _, block := range blocks {
+		list, err := r.blockRecords(ctx, seqno, block)
+		if err != nil {
+			return 0, fmt.Errorf("failed to process block %d:%x:%d: %w", block.Workchain, uint64(block.Shard), block.SeqNo, err)
+		}
+
+		for _, rec := range list {
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return 0, fmt.Errorf("failed to serialize record of tx %s: %w", rec.TxHash, err)
+			}
+			buf.Write(data)
+			buf.WriteByte('\n')
+			records++
+		}
+	}
+
+	if err = r.write(seqno, buf.Bytes()); err != nil {
+		return 0, err
+	}
+
+	// update seen shards only when master block is fully written
+	for _, shard := range shards {
+		r.shardLastSeqno[shardID(shard)] = shard.SeqNo
+	}
+
+	return records, nil
+}
+
+// notSeenShards - returns shard block and its parents which were not processed yet
+func (r *replayer) notSeenShards(ctx context.Context, shard *tlb.BlockInfo) ([]*tlb.BlockInfo, error) {
+	if no, ok := r.shardLastSeqno[shardID(shard)]; ok && no >= shard.SeqNo {
+		return nil, nil
+	}
+
+	if len(r.shardLastSeqno) == 0 {
+		// nothing is known about previous state (replay from genesis)
+		return []*tlb.BlockInfo{shard}, nil
+	}
+
+	b, err := r.api.GetBlockData(ctx, shard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block data: %w", err)
+	}
+
+	parents, err := b.BlockInfo.GetParentBlocks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent blocks of %d:%x:%d: %w", shard.Workchain, uint64(shard.Shard), shard.SeqNo, err)
+	}
+
+	var res []*tlb.BlockInfo
+	for _, parent := range parents {
+		ext, err := r.notSeenShards(ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ext...)
+	}
+
+	return append(res, shard), nil
+}
+
+func (r *replayer) blockRecords(ctx context.Context, masterSeqno uint32, block *tlb.BlockInfo) ([]Record, error) {
+	var records []Record
+	var after *tlb.TransactionID
+	for more := true; more; {
+		var ids []*tlb.TransactionID
+		var err error
+		ids, more, err = r.api.GetBlockTransactions(ctx, block, 100, after)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transactions: %w", err)
+		}
+
+		for _, id := range ids {
+			addr := address.NewAddressVar(0, block.Workchain, 256, id.AccountID)
+			if block.Workchain == -1 || block.Workchain == 0 {
+				addr = address.NewAddress(0, byte(block.Workchain), id.AccountID)
+			}
+
+			tx, err := r.api.GetTransaction(ctx, block, addr, id.LT)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get transaction %d of %x: %w", id.LT, id.AccountID, err)
+			}
+
+			base := Record{
+				MasterSeqno: masterSeqno,
+				Workchain:   block.Workchain,
+				Shard:       fmt.Sprintf("%016x", uint64(block.Shard)),
+				BlockSeqno:  block.SeqNo,
+				Account:     hex.EncodeToString(id.AccountID),
+				LT:          id.LT,
+				TxHash:      hex.EncodeToString(id.Hash),
+			}
+
+			if tx.IO.In != nil {
+				if rec, ok := r.decode(ctx, base, "in", tx.IO.In.Msg.Payload()); ok {
+					records = append(records, rec)
+				}
+			}
+
+			for _, out := range tx.IO.Out {
+				if rec, ok := r.decode(ctx, base, "out", out.Msg.Payload()); ok {
+					records = append(records, rec)
+				}
+			}
+		}
+
+		if len(ids) == 0 {
+			break
+		}
+		after = ids[len(ids)-1]
+	}
+	return records, nil
+}
+
+func (r *replayer) decode(ctx context.Context, rec Record, direction string, body *cell.Cell) (Record, bool) {
+	if body == nil {
+		return rec, false
+	}
+
+	v, name, err := tlb.LoadAnyNamedContext(ctx, body.BeginParse())
+	if err != nil {
+		return rec, false
+	}
+
+	if len(r.types) > 0 && !r.types[name] {
+		return rec, false
+	}
+
+	rec.Direction = direction
+	rec.Type = name
+	rec.Value = v
+	return rec, true
+}
+
+// write - appends data of master block to its partition file and saves checkpoint
+func (r *replayer) write(seqno uint32, data []byte) error {
+	part := (seqno - r.cfg.FromSeqno) / r.cfg.BlocksPerFile
+	from := r.cfg.FromSeqno + part*r.cfg.BlocksPerFile
+	to := from + r.cfg.BlocksPerFile - 1
+	if to > r.cfg.ToSeqno {
+		to = r.cfg.ToSeqno
+	}
+	name := fmt.Sprintf("%d-%d.ndjson", from, to)
+
+	if r.fileName != name {
+		r.close()
+
+		f, err := os.OpenFile(filepath.Join(r.cfg.Dir, name), os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open output file: %w", err)
+		}
+
+		// drop data written after last checkpoint
+		var size int64
+		if r.cp != nil && r.cp.File == name {
+			size = r.cp.Size
+		}
+
+		if err = f.Truncate(size); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("failed to truncate output file: %w", err)
+		}
+
+		if _, err = f.Seek(size, 0); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("failed to seek output file: %w", err)
+		}
+
+		r.file, r.fileName = f, name
+	}
+
+	if _, err := r.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	if err := r.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync output file: %w", err)
+	}
+
+	size, err := r.file.Seek(0, 1)
+	if err != nil {
+		return fmt.Errorf("failed to get output file size: %w", err)
+	}
+
+	r.cp = &checkpoint{
+		FromSeqno: r.cfg.FromSeqno,
+		ToSeqno:   r.cfg.ToSeqno,
+		Seqno:     seqno,
+		File:      name,
+		Size:      size,
+	}
+	return saveCheckpoint(r.cfg.Dir, r.cp)
+}
+
+func (r *replayer) close() {
+	if r.file != nil {
+		_ = r.file.Close()
+		r.file, r.fileName = nil, ""
+	}
+}
+
+func loadCheckpoint(dir string) (*checkpoint, error) {
+	data, err := os.ReadFile(filepath.Join(dir, checkpointFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp checkpoint
+	if err = json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+func saveCheckpoint(dir string, cp *checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to serialize checkpoint: %w", err)
+	}
+
+	// write to temp file and rename, to not corrupt checkpoint on failure
+	tmp := filepath.Join(dir, checkpointFile+".tmp")
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err = os.Rename(tmp, filepath.Join(dir, checkpointFile)); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func shardID(shard *tlb.BlockInfo) string {
+	return fmt.Sprintf("%d|%d", shard.Workchain, shard.Shard)
+}
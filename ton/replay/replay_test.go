@@ -0,0 +1,159 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/tlb"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+type testReplayBody struct {
+	_       tlb.Magic  `tlb:"#5e9a1a7e"`
+	Value   uint32     `tlb:"## 32"`
+	Payload *cell.Cell `tlb:"^"`
+}
+
+func testReplayPayload(seqno uint32) *cell.Cell {
+	return cell.BeginCell().MustStoreUInt(uint64(seqno), 16).EndCell()
+}
+
+// mockAPI - every master block has one shard block with seqno+100, which has one transaction
+type mockAPI struct {
+	failAt uint32
+}
+
+var errMock = errors.New("mock failure")
+
+func (m *mockAPI) LookupBlock(_ context.Context, workchain int32, shard int64, seqno uint32) (*tlb.BlockInfo, error) {
+	if workchain == -1 && seqno == m.failAt {
+		m.failAt = 0
+		return nil, errMock
+	}
+	return &tlb.BlockInfo{Workchain: workchain, Shard: shard, SeqNo: seqno}, nil
+}
+
+func (m *mockAPI) GetBlockData(_ context.Context, block *tlb.BlockInfo) (*tlb.Block, error) {
+	b := &tlb.Block{}
+	b.BlockInfo.SeqNo = block.SeqNo
+	b.BlockInfo.Shard = tlb.ShardIdent{WorkchainID: block.Workchain}
+	b.BlockInfo.PrevRef.Prev1.SeqNo = block.SeqNo - 1
+	return b, nil
+}
+
+func (m *mockAPI) GetBlockShardsInfo(_ context.Context, master *tlb.BlockInfo) ([]*tlb.BlockInfo, error) {
+	return []*tlb.BlockInfo{{Workchain: 0, Shard: masterShard, SeqNo: master.SeqNo + 100}}, nil
+}
+
+func (m *mockAPI) GetBlockTransactions(_ context.Context, block *tlb.BlockInfo, _ uint32, after ...*tlb.TransactionID) ([]*tlb.TransactionID, bool, error) {
+	if block.Workchain == -1 || (len(after) > 0 && after[0] != nil) {
+		return nil, false, nil
+	}
+
+	acc := make([]byte, 32)
+	binary.BigEndian.PutUint32(acc, block.SeqNo)
+	return []*tlb.TransactionID{{LT: uint64(block.SeqNo), Hash: make([]byte, 32), AccountID: acc}}, false, nil
+}
+
+func (m *mockAPI) GetTransaction(_ context.Context, block *tlb.BlockInfo, _ *address.Address, lt uint64) (*tlb.Transaction, error) {
+	body := cell.BeginCell().MustStoreUInt(0x5e9a1a7e, 32).MustStoreUInt(uint64(block.SeqNo), 32).
+		MustStoreRef(testReplayPayload(block.SeqNo)).EndCell()
+
+	tx := &tlb.Transaction{LT: lt}
+	tx.IO.In = &tlb.Message{
+		MsgType: tlb.MsgTypeInternal,
+		Msg:     &tlb.InternalMessage{Body: body},
+	}
+	tx.IO.Out = []*tlb.Message{{
+		MsgType: tlb.MsgTypeInternal,
+		Msg:     &tlb.InternalMessage{Body: cell.BeginCell().MustStoreUInt(0, 32).EndCell()},
+	}}
+	return tx, nil
+}
+
+func readRecords(t *testing.T, path string) []Record {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var res []Record
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var rec Record
+		if err = json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, rec)
+	}
+	return res
+}
+
+func TestRun(t *testing.T) {
+	tlb.Register("TestReplayBody", testReplayBody{})
+
+	dir := t.TempDir()
+	api := &mockAPI{failAt: 15}
+
+	cfg := Config{
+		FromSeqno:     10,
+		ToSeqno:       21,
+		Types:         []string{"TestReplayBody"},
+		Dir:           dir,
+		BlocksPerFile: 5,
+	}
+
+	var last Progress
+	cfg.OnProgress = func(p Progress) {
+		last = p
+	}
+
+	if err := Run(context.Background(), api, cfg); !errors.Is(err, errMock) {
+		t.Fatal("should fail with mock error, got", err)
+	}
+
+	if last.Seqno != 14 || last.Processed != 5 || last.Total != 12 {
+		t.Fatal("incorrect progress", last)
+	}
+
+	if err := Run(context.Background(), api, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if last.Seqno != 21 || last.Processed != 12 || last.Records != 7 {
+		t.Fatal("incorrect progress after resume", last)
+	}
+
+	seqno := uint32(10)
+	for _, name := range []string{"10-14.ndjson", "15-19.ndjson", "20-21.ndjson"} {
+		for _, rec := range readRecords(t, filepath.Join(dir, name)) {
+			if rec.MasterSeqno != seqno || rec.BlockSeqno != seqno+100 || rec.Direction != "in" || rec.Type != "TestReplayBody" {
+				t.Fatal("incorrect record", name, rec)
+			}
+
+			value, _ := rec.Value.(map[string]any)
+			if value["Payload"] != base64.StdEncoding.EncodeToString(testReplayPayload(seqno+100).ToBOC()) {
+				t.Fatal("incorrect value of record", name, rec.Value)
+			}
+			seqno++
+		}
+	}
+
+	if seqno != 22 {
+		t.Fatal("not all blocks written, last", seqno-1)
+	}
+
+	cfg.ToSeqno = 30
+	if err := Run(context.Background(), api, cfg); err == nil {
+		t.Fatal("should fail on range mismatch")
+	}
+}
@@ -0,0 +1,184 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+
+	"github.com/xssnick/tonutils-go/address"
+	"github.com/xssnick/tonutils-go/internal/ecdh"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+const EncryptedCommentOpcode = 0x2167da4b
+
+var ErrInvalidEncryptedComment = errors.New("invalid encrypted comment")
+
+// EncryptedCommentPayload - body of the message with encrypted comment,
+// PubXor is xor of sender and receiver public keys,
+// MsgKey is used to derive encryption key, Data is encrypted comment with random prefix
+type EncryptedCommentPayload struct {
+	PubXor []byte
+	MsgKey []byte
+	Data   []byte
+}
+
+func (p *EncryptedCommentPayload) LoadFromCell(loader *cell.Slice) error {
+	op, err := loader.LoadUInt(32)
+	if err != nil {
+		return fmt.Errorf("failed to load op: %w", err)
+	}
+
+	if op != EncryptedCommentOpcode {
+		return fmt.Errorf("%w: incorrect op %x", ErrInvalidEncryptedComment, op)
+	}
+
+	data, err := loader.LoadBinarySnake()
+	if err != nil {
+		return fmt.Errorf("failed to load data: %w", err)
+	}
+
+	if len(data) < 32+16+16 || (len(data)-32-16)%16 != 0 {
+		return fmt.Errorf("%w: incorrect data len", ErrInvalidEncryptedComment)
+	}
+
+	p.PubXor = data[:32]
+	p.MsgKey = data[32:48]
+	p.Data = data[48:]
+	return nil
+}
+
+func (p *EncryptedCommentPayload) ToCell() (*cell.Cell, error) {
+	data := make([]byte, 0, len(p.PubXor)+len(p.MsgKey)+len(p.Data))
+	data = append(data, p.PubXor...)
+	data = append(data, p.MsgKey...)
+	data = append(data, p.Data...)
+
+	root := cell.BeginCell().MustStoreUInt(EncryptedCommentOpcode, 32)
+	if err := root.StoreBinarySnake(data); err != nil {
+		return nil, fmt.Errorf("failed to store data: %w", err)
+	}
+	return root.EndCell(), nil
+}
+
+// EncryptComment - builds message body with comment encrypted for receiver, according to TEP-44,
+// senderAddr is address of the wallet which sends message, it is used as salt
+func EncryptComment(comment string, senderAddr *address.Address, ourKey ed25519.PrivateKey, theirKey ed25519.PublicKey) (*cell.Cell, error) {
+	sharedKey, err := ecdh.SharedKey(ourKey, theirKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calc shared key: %w", err)
+	}
+
+	pfxLen := 16
+	if len(comment)%16 != 0 {
+		pfxLen += 16 - len(comment)%16
+	}
+
+	data := make([]byte, pfxLen, pfxLen+len(comment))
+	if _, err = rand.Read(data[1:]); err != nil {
+		return nil, fmt.Errorf("failed to generate prefix: %w", err)
+	}
+	data[0] = byte(pfxLen)
+	data = append(data, comment...)
+
+	msgKey := commentMsgKey(senderAddr, data)
+
+	c, err := commentCipher(sharedKey, msgKey)
+	if err != nil {
+		return nil, err
+	}
+	c.CryptBlocks(data, data)
+
+	pubXor := make([]byte, ed25519.PublicKeySize)
+	ourPub := ourKey.Public().(ed25519.PublicKey)
+	for i := range pubXor {
+		pubXor[i] = ourPub[i] ^ theirKey[i]
+	}
+
+	return (&EncryptedCommentPayload{
+		PubXor: pubXor,
+		MsgKey: msgKey,
+		Data:   data,
+	}).ToCell()
+}
+
+// DecryptComment - decrypts comment from message body, our key can be key of sender or receiver,
+// senderAddr is address of the wallet which sent message
+func DecryptComment(body *cell.Cell, senderAddr *address.Address, ourKey ed25519.PrivateKey) (string, error) {
+	var p EncryptedCommentPayload
+	if err := p.LoadFromCell(body.BeginParse()); err != nil {
+		return "", err
+	}
+
+	theirKey := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	ourPub := ourKey.Public().(ed25519.PublicKey)
+	for i := range theirKey {
+		theirKey[i] = ourPub[i] ^ p.PubXor[i]
+	}
+
+	sharedKey, err := ecdh.SharedKey(ourKey, theirKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to calc shared key: %w", err)
+	}
+
+	c, err := commentDecipher(sharedKey, p.MsgKey)
+	if err != nil {
+		return "", err
+	}
+
+	data := make([]byte, len(p.Data))
+	c.CryptBlocks(data, p.Data)
+
+	if !bytes.Equal(commentMsgKey(senderAddr, data), p.MsgKey) {
+		return "", fmt.Errorf("%w: msg key mismatch", ErrInvalidEncryptedComment)
+	}
+
+	if data[0] < 16 || int(data[0]) > len(data) {
+		return "", fmt.Errorf("%w: incorrect prefix len", ErrInvalidEncryptedComment)
+	}
+
+	return string(data[data[0]:]), nil
+}
+
+func commentMsgKey(senderAddr *address.Address, data []byte) []byte {
+	// salt is always bounceable mainnet form of address
+	salt := address.NewAddress(0, byte(senderAddr.Workchain()), senderAddr.Data())
+
+	h := hmac.New(sha512.New, []byte(salt.String()))
+	h.Write(data)
+	return h.Sum(nil)[:16]
+}
+
+func commentKey(sharedKey, msgKey []byte) (cipher.Block, []byte, error) {
+	h := hmac.New(sha512.New, sharedKey)
+	h.Write(msgKey)
+	x := h.Sum(nil)
+
+	block, err := aes.NewCipher(x[:32])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to init cipher: %w", err)
+	}
+	return block, x[32:48], nil
+}
+
+func commentCipher(sharedKey, msgKey []byte) (cipher.BlockMode, error) {
+	block, iv, err := commentKey(sharedKey, msgKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewCBCEncrypter(block, iv), nil
+}
+
+func commentDecipher(sharedKey, msgKey []byte) (cipher.BlockMode, error) {
+	block, iv, err := commentKey(sharedKey, msgKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewCBCDecrypter(block, iv), nil
+}
@@ -0,0 +1,40 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+)
+
+func TestEncryptComment(t *testing.T) {
+	_, senderKey, _ := ed25519.GenerateKey(nil)
+	_, receiverKey, _ := ed25519.GenerateKey(nil)
+
+	sender, err := FromPrivateKey(nil, senderKey, V3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, comment := range []string{"", "hello", "exactly 16 bytes", strings.Repeat("long comment ", 50)} {
+		body, err := EncryptComment(comment, sender.Address(), senderKey, receiverKey.Public().(ed25519.PublicKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, key := range []ed25519.PrivateKey{receiverKey, senderKey} {
+			got, err := DecryptComment(body, sender.Address(), key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != comment {
+				t.Fatal("comment not eq", got)
+			}
+		}
+
+		_, otherKey, _ := ed25519.GenerateKey(nil)
+		if _, err = DecryptComment(body, sender.Address(), otherKey); err == nil {
+			t.Fatal("should fail with other key")
+		}
+	}
+}
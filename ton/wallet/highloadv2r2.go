@@ -45,7 +45,10 @@ func (s *SpecHighloadV2R2) BuildMessage(_ context.Context, queryID uint32, messa
 		MustStoreUInt(boundedID, 64).
 		MustStoreDict(dict)
 
-	sign := payload.EndCell().Sign(s.wallet.key)
+	sign, err := s.wallet.signer.Sign(payload.EndCell().Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
 	msg := cell.BeginCell().MustStoreSlice(sign, 512).MustStoreBuilder(payload).EndCell()
 
 	return msg, nil
@@ -0,0 +1,172 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const _KeystoreVersion = 1
+
+// _KeystoreMaxIterations - limit of PBKDF2 iterations accepted from keystore file,
+// to not hang on crafted files, it is 100 times more than we use
+const _KeystoreMaxIterations = 100 * _Iterations
+
+var ErrInvalidKeystorePassword = errors.New("invalid keystore password")
+
+// Keystore - encrypted storage of the private key, key is encrypted
+// with AES-256-GCM using key derived from password with PBKDF2-SHA512
+type Keystore struct {
+	Version    int    `json:"version"`
+	PublicKey  []byte `json:"public_key"`
+	Salt       []byte `json:"salt"`
+	Iterations int    `json:"iterations"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptKey - encrypts private key with password
+func EncryptKey(key ed25519.PrivateKey, password string) (*Keystore, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid private key size")
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	ks := &Keystore{
+		Version:    _KeystoreVersion,
+		PublicKey:  key.Public().(ed25519.PublicKey),
+		Salt:       salt,
+		Iterations: _Iterations,
+	}
+
+	gcm, err := ks.cipher(password)
+	if err != nil {
+		return nil, err
+	}
+
+	ks.Nonce = make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(ks.Nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	ks.Ciphertext = gcm.Seal(nil, ks.Nonce, key.Seed(), ks.PublicKey)
+	return ks, nil
+}
+
+// Decrypt - decrypts private key using password
+func (k *Keystore) Decrypt(password string) (ed25519.PrivateKey, error) {
+	if k.Version != _KeystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version %d", k.Version)
+	}
+
+	gcm, err := k.cipher(password)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(k.Nonce) != gcm.NonceSize() {
+		return nil, errors.New("invalid keystore nonce size")
+	}
+
+	seed, err := gcm.Open(nil, k.Nonce, k.Ciphertext, k.PublicKey)
+	if err != nil {
+		return nil, ErrInvalidKeystorePassword
+	}
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, errors.New("invalid keystore key size")
+	}
+
+	key := ed25519.NewKeyFromSeed(seed)
+	if !bytes.Equal(key.Public().(ed25519.PublicKey), k.PublicKey) {
+		return nil, errors.New("keystore public key mismatch")
+	}
+
+	return key, nil
+}
+
+// Signer - decrypts private key and returns signer based on it
+func (k *Keystore) Signer(password string) (Signer, error) {
+	key, err := k.Decrypt(password)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(key), nil
+}
+
+func (k *Keystore) cipher(password string) (cipher.AEAD, error) {
+	if k.Iterations <= 0 || k.Iterations > _KeystoreMaxIterations {
+		return nil, fmt.Errorf("invalid keystore iterations number %d", k.Iterations)
+	}
+
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(password), k.Salt, k.Iterations, 32, sha512.New))
+	if err != nil {
+		return nil, fmt.Errorf("failed to init cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init gcm: %w", err)
+	}
+	return gcm, nil
+}
+
+// SaveKeystore - encrypts private key with password and writes it to file
+func SaveKeystore(path string, key ed25519.PrivateKey, password string) error {
+	ks, err := EncryptKey(key, password)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(ks)
+	if err != nil {
+		return fmt.Errorf("failed to serialize keystore: %w", err)
+	}
+
+	if err = os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	return nil
+}
+
+// LoadKeystore - reads encrypted keystore from file
+func LoadKeystore(path string) (*Keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	var ks Keystore
+	if err = json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore: %w", err)
+	}
+	return &ks, nil
+}
+
+// FromKeystore - loads keystore file, decrypts it and initializes wallet with its key
+func FromKeystore(api TonAPI, path, password string, version Version) (*Wallet, error) {
+	ks, err := LoadKeystore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ks.Decrypt(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromPrivateKey(api, key, version)
+}
@@ -0,0 +1,60 @@
+package wallet
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeystore(t *testing.T) {
+	seed := NewSeed()
+	key, err := SeedToPrivateKey(seed, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "wallet.json")
+	if err = SaveKeystore(path, key, "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := FromKeystore(nil, path, "secret", V3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sw, err := FromSeed(nil, seed, V3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Address().String() != sw.Address().String() {
+		t.Fatal("address not eq")
+	}
+
+	ks, err := LoadKeystore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = ks.Signer("wrong"); !errors.Is(err, ErrInvalidKeystorePassword) {
+		t.Fatal("should fail with wrong password", err)
+	}
+}
+
+func TestKeystoreIterationsLimit(t *testing.T) {
+	key, err := SeedToPrivateKey(NewSeed(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ks, err := EncryptKey(key, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ks.Iterations = _KeystoreMaxIterations + 1
+	if _, err = ks.Decrypt("secret"); err == nil || errors.Is(err, ErrInvalidKeystorePassword) {
+		t.Fatal("oversized iterations number should be rejected before key derivation", err)
+	}
+}
@@ -70,6 +70,17 @@ func FromSeed(api TonAPI, seed []string, version Version) (*Wallet, error) {
 }
 
 func FromSeedWithPassword(api TonAPI, seed []string, password string, version Version) (*Wallet, error) {
+	key, err := SeedToPrivateKey(seed, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromPrivateKey(api, key, version)
+}
+
+// SeedToPrivateKey - validates mnemonic and derives ed25519 private key from it,
+// the same way as it is done by TON wallets
+func SeedToPrivateKey(seed []string, password string) (ed25519.PrivateKey, error) {
 	// validate seed
 	if len(seed) < 12 {
 		return nil, fmt.Errorf("seed should have at least 12 words")
@@ -98,7 +109,7 @@ func FromSeedWithPassword(api TonAPI, seed []string, password string, version Ve
 
 	k := pbkdf2.Key(hash, []byte(_Salt), _Iterations, 32, sha512.New)
 
-	return FromPrivateKey(api, ed25519.NewKeyFromSeed(k), version)
+	return ed25519.NewKeyFromSeed(k), nil
 }
 
 var words = map[string]bool{
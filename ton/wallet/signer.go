@@ -0,0 +1,33 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"errors"
+)
+
+// Signer - signs hashes of wallet messages, can be implemented
+// to use hardware wallets, HSM or remote key storages
+type Signer interface {
+	Sign(hash []byte) ([]byte, error)
+	PublicKey() ed25519.PublicKey
+}
+
+type keySigner struct {
+	key ed25519.PrivateKey
+}
+
+// NewKeySigner - creates signer from in-memory ed25519 private key
+func NewKeySigner(key ed25519.PrivateKey) Signer {
+	return &keySigner{key: key}
+}
+
+func (s *keySigner) Sign(hash []byte) ([]byte, error) {
+	if len(s.key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid private key size")
+	}
+	return ed25519.Sign(s.key, hash), nil
+}
+
+func (s *keySigner) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
@@ -53,7 +53,10 @@ func (s *SpecV3) BuildMessage(ctx context.Context, isInitialized bool, block *tl
 		payload.MustStoreUInt(uint64(message.Mode), 8).MustStoreRef(intMsg)
 	}
 
-	sign := payload.EndCell().Sign(s.wallet.key)
+	sign, err := s.wallet.signer.Sign(payload.EndCell().Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
 	msg := cell.BeginCell().MustStoreSlice(sign, 512).MustStoreBuilder(payload).EndCell()
 
 	return msg, nil
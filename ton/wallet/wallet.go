@@ -106,10 +106,11 @@ type Message struct {
 }
 
 type Wallet struct {
-	api  TonAPI
-	key  ed25519.PrivateKey
-	addr *address.Address
-	ver  Version
+	api    TonAPI
+	key    ed25519.PrivateKey
+	signer Signer
+	addr   *address.Address
+	ver    Version
 
 	// Can be used to operate multiple wallets with the same key and version.
 	// use GetSubwallet if you need it.
@@ -120,14 +121,26 @@ type Wallet struct {
 }
 
 func FromPrivateKey(api TonAPI, key ed25519.PrivateKey, version Version) (*Wallet, error) {
-	addr, err := AddressFromPubKey(key.Public().(ed25519.PublicKey), version, DefaultSubwallet)
+	w, err := FromSigner(api, NewKeySigner(key), version)
+	if err != nil {
+		return nil, err
+	}
+	w.key = key
+
+	return w, nil
+}
+
+// FromSigner - initializes wallet which signs messages using signer,
+// private key is not known to the wallet in this case, so PrivateKey will return nil
+func FromSigner(api TonAPI, signer Signer, version Version) (*Wallet, error) {
+	addr, err := AddressFromPubKey(signer.PublicKey(), version, DefaultSubwallet)
 	if err != nil {
 		return nil, err
 	}
 
 	w := &Wallet{
 		api:       api,
-		key:       key,
+		signer:    signer,
 		addr:      addr,
 		ver:       version,
 		subwallet: DefaultSubwallet,
@@ -167,8 +180,12 @@ func (w *Wallet) PrivateKey() ed25519.PrivateKey {
 	return w.key
 }
 
+func (w *Wallet) Signer() Signer {
+	return w.signer
+}
+
 func (w *Wallet) GetSubwallet(subwallet uint32) (*Wallet, error) {
-	addr, err := AddressFromPubKey(w.key.Public().(ed25519.PublicKey), w.ver, subwallet)
+	addr, err := AddressFromPubKey(w.signer.PublicKey(), w.ver, subwallet)
 	if err != nil {
 		return nil, err
 	}
@@ -176,6 +193,7 @@ func (w *Wallet) GetSubwallet(subwallet uint32) (*Wallet, error) {
 	sub := &Wallet{
 		api:       w.api,
 		key:       w.key,
+		signer:    w.signer,
 		addr:      addr,
 		ver:       w.ver,
 		subwallet: subwallet,
@@ -223,7 +241,7 @@ func (w *Wallet) BuildMessageForMany(ctx context.Context, messages []*Message) (
 	if !acc.IsActive || acc.State.Status != tlb.AccountStatusActive {
 		initialized = false
 
-		stateInit, err = GetStateInit(w.key.Public().(ed25519.PublicKey), w.ver, w.subwallet)
+		stateInit, err = GetStateInit(w.signer.PublicKey(), w.ver, w.subwallet)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get state init: %w", err)
 		}
@@ -435,3 +435,53 @@ func checkHighloadV2R2(t *testing.T, p *cell.Slice, w *Wallet, intMsg *tlb.Inter
 		t.Fatal("sign incorrect")
 	}
 }
+
+type testSigner struct {
+	Signer
+	calls int
+}
+
+func (s *testSigner) Sign(hash []byte) ([]byte, error) {
+	s.calls++
+	return s.Signer.Sign(hash)
+}
+
+func TestWallet_FromSigner(t *testing.T) {
+	_, pkey, _ := ed25519.GenerateKey(nil)
+	signer := &testSigner{Signer: NewKeySigner(pkey)}
+
+	w, err := FromSigner(MockAPI{}, signer, V3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.PrivateKey() != nil {
+		t.Fatal("private key should be unknown")
+	}
+
+	pw, err := FromPrivateKey(MockAPI{}, pkey, V3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Address().String() != pw.Address().String() {
+		t.Fatal("address not eq")
+	}
+
+	msg, err := w.spec.(*SpecV3).BuildMessage(context.Background(), false, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signer.calls != 1 {
+		t.Fatal("signer was not called")
+	}
+
+	p := msg.BeginParse()
+	sign := p.MustLoadSlice(512)
+	payload, _ := p.ToCell()
+
+	if !ed25519.Verify(pkey.Public().(ed25519.PublicKey), payload.Hash(), sign) {
+		t.Fatal("sign incorrect")
+	}
+}
@@ -2,6 +2,7 @@ package cell
 
 import (
 	"encoding/binary"
+	"fmt"
 	"github.com/xssnick/tonutils-go/address"
 	"math/big"
 )
@@ -233,7 +234,7 @@ func (b *Builder) StoreAddr(addr *address.Address) error {
 
 		return nil
 	case address.StdAddress:
-		if b.bitsSz+2+1+8+256 >= 1024 {
+		if b.bitsSz+2+1+anycastLen(addr)+8+256 >= 1024 {
 			return ErrNotFit1023
 		}
 
@@ -243,8 +244,7 @@ func (b *Builder) StoreAddr(addr *address.Address) error {
 			return err
 		}
 
-		// anycast
-		err = b.StoreUInt(0b0, 1)
+		err = b.storeAnycast(addr.Anycast())
 		if err != nil {
 			return err
 		}
@@ -261,7 +261,7 @@ func (b *Builder) StoreAddr(addr *address.Address) error {
 
 		return nil
 	case address.VarAddress:
-		if b.bitsSz+2+1+9+32+addr.BitsLen() >= 1024 {
+		if b.bitsSz+2+1+anycastLen(addr)+9+32+addr.BitsLen() >= 1024 {
 			return ErrNotFit1023
 		}
 
@@ -271,8 +271,7 @@ func (b *Builder) StoreAddr(addr *address.Address) error {
 			return err
 		}
 
-		// anycast
-		err = b.StoreUInt(0b0, 1)
+		err = b.storeAnycast(addr.Anycast())
 		if err != nil {
 			return err
 		}
@@ -298,6 +297,36 @@ func (b *Builder) StoreAddr(addr *address.Address) error {
 	return ErrAddressTypeNotSupported
 }
 
+// storeAnycast - stores Maybe Anycast of std or var address
+func (b *Builder) storeAnycast(anycast *address.Anycast) error {
+	if anycast == nil {
+		return b.StoreBoolBit(false)
+	}
+
+	if anycast.Depth < 1 || anycast.Depth > 30 {
+		return fmt.Errorf("incorrect anycast depth %d", anycast.Depth)
+	}
+
+	err := b.StoreBoolBit(true)
+	if err != nil {
+		return err
+	}
+
+	err = b.StoreUInt(uint64(anycast.Depth), anycastDepthLen)
+	if err != nil {
+		return err
+	}
+
+	return b.StoreSlice(anycast.Prefix, anycast.Depth)
+}
+
+func anycastLen(addr *address.Address) uint {
+	if addr.Anycast() == nil {
+		return 0
+	}
+	return anycastDepthLen + addr.Anycast().Depth
+}
+
 func (b *Builder) MustStoreStringSnake(str string) *Builder {
 	err := b.StoreStringSnake(str)
 	if err != nil {
@@ -47,6 +47,11 @@ func (c *Cell) ToBuilder() *Builder {
 	}
 }
 
+// IsSpecial - checks is cell exotic, like pruned branch or merkle proof, its type is in the first byte of data
+func (c *Cell) IsSpecial() bool {
+	return c.special
+}
+
 func (c *Cell) BitsSize() uint {
 	return c.bitsSz
 }
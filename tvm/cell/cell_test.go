@@ -89,6 +89,22 @@ func TestBOCWithCRC(t *testing.T) {
 	}
 }
 
+func TestFromBOCTruncated(t *testing.T) {
+	c := BeginCell().MustStoreUInt(7, 32).MustStoreRef(BeginCell().MustStoreUInt(1, 8).EndCell()).EndCell()
+	for _, boc := range [][]byte{c.ToBOCWithFlags(false), c.ToBOCWithFlags(true)} {
+		for i := 0; i < len(boc); i++ {
+			if _, err := FromBOC(boc[:i]); err == nil {
+				t.Fatal("should fail on truncated boc of len", i)
+			}
+		}
+	}
+
+	// header of 10 bytes with too big sizes of cells num and data
+	if _, err := FromBOC([]byte("\xb5\xee\x9cr100000")); err == nil {
+		t.Fatal("should fail on corrupted header")
+	}
+}
+
 func TestCell_Hash1(t *testing.T) {
 	emptyHash, _ := new(big.Int).SetString("68134197439415885698044414435951397869210496020759160419881882418413283430343", 10)
 
@@ -144,3 +160,31 @@ func TestVarAddr(t *testing.T) {
 		}
 	}
 }
+
+func TestAnycastAddr(t *testing.T) {
+	std := address.NewAddress(0, 0, make([]byte, 32))
+	std.SetAnycast(&address.Anycast{Depth: 5, Prefix: []byte{0b10101000}})
+
+	vr := address.NewAddressVar(0, -7, 40, []byte{1, 2, 3, 4, 5})
+	vr.SetAnycast(&address.Anycast{Depth: 12, Prefix: []byte{0xAB, 0xC0}})
+
+	for _, addr := range []*address.Address{std, vr} {
+		c := BeginCell().MustStoreAddr(addr).EndCell()
+
+		a := c.BeginParse().MustLoadAddr()
+		if a.Anycast() == nil || a.Anycast().Depth != addr.Anycast().Depth ||
+			!bytes.Equal(a.Anycast().Prefix, addr.Anycast().Prefix) {
+			t.Fatal("anycast not eq")
+		}
+
+		if !bytes.Equal(BeginCell().MustStoreAddr(a).EndCell().Hash(), c.Hash()) {
+			t.Fatal("diff hash")
+		}
+	}
+
+	bad := address.NewAddress(0, 0, make([]byte, 32))
+	bad.SetAnycast(&address.Anycast{Depth: 31, Prefix: make([]byte, 4)})
+	if err := BeginCell().StoreAddr(bad); err == nil {
+		t.Fatal("should fail on incorrect depth")
+	}
+}
@@ -43,13 +43,33 @@ func FromBOCMultiRoot(data []byte) ([]*Cell, error) {
 	flags, cellNumSizeBytes := parseBOCFlags(r.MustReadByte()) // has_idx:(## 1) has_crc32c:(## 1)  has_cache_bits:(## 1) flags:(## 2) { flags = 0 } size:(## 3) { size <= 4 }
 	dataSizeBytes := int(r.MustReadByte())                     // off_bytes:(## 8) { off_bytes <= 8 }
 
-	cellsNum := dynInt(r.MustReadBytes(cellNumSizeBytes)) // cells:(##(size * 8))
-	rootsNum := dynInt(r.MustReadBytes(cellNumSizeBytes)) // roots:(##(size * 8)) { roots >= 1 }
+	if cellNumSizeBytes < 1 || cellNumSizeBytes > 4 {
+		return nil, fmt.Errorf("invalid boc cell num size %d", cellNumSizeBytes)
+	}
+	if dataSizeBytes < 1 || dataSizeBytes > 8 {
+		return nil, fmt.Errorf("invalid boc data size %d", dataSizeBytes)
+	}
+
+	header, err := r.ReadBytes(3*cellNumSizeBytes + dataSizeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read boc header, err: %w", err)
+	}
+
+	cellsNum := dynInt(header[:cellNumSizeBytes])                     // cells:(##(size * 8))
+	rootsNum := dynInt(header[cellNumSizeBytes : 2*cellNumSizeBytes]) // roots:(##(size * 8)) { roots >= 1 }
 
 	// complete BOCs - ??? (absent:(##(size * 8)) { roots + absent <= cells })
-	_ = r.MustReadBytes(cellNumSizeBytes)
 
-	dataLen := dynInt(r.MustReadBytes(dataSizeBytes)) // tot_cells_size:(##(off_bytes * 8))
+	dataLen := dynInt(header[3*cellNumSizeBytes:]) // tot_cells_size:(##(off_bytes * 8))
+
+	if rootsNum < 1 || rootsNum > cellsNum {
+		return nil, fmt.Errorf("invalid boc roots num %d of %d cells", rootsNum, cellsNum)
+	}
+
+	// each cell takes at least 2 bytes, so we can reject impossible sizes before allocating
+	if dataLen < 0 || dataLen > r.LeftLen() || cellsNum > dataLen/2 {
+		return nil, fmt.Errorf("boc is truncated, %d cells of %d bytes not fit in %d bytes", cellsNum, dataLen, r.LeftLen())
+	}
 
 	// with checksum
 	if flags.HasCrc32c {
@@ -59,7 +79,10 @@ func FromBOCMultiRoot(data []byte) ([]*Cell, error) {
 		}
 	}
 
-	rootList := r.MustReadBytes(rootsNum * cellNumSizeBytes) // root_list:(roots * ##(size * 8))
+	rootList, err := r.ReadBytes(rootsNum * cellNumSizeBytes) // root_list:(roots * ##(size * 8))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read root list, err: %w", err)
+	}
 	rootIndex := dynInt(rootList[0:cellNumSizeBytes])
 	_ = rootIndex
 
@@ -119,6 +142,10 @@ func parseCells(rootsNum, cellsNum, refSzBytes int, data []byte, index []int) ([
 			if i > 0 {
 				offset = index[i-1]
 			}
+
+			if offset < 0 || len(data)-offset < 2 {
+				return nil, errors.New("failed to parse cell header, corrupted index")
+			}
 		}
 
 		// len(self.refs) + self.is_special() * 8 + self.level() * 32
@@ -148,6 +175,10 @@ func parseCells(rootsNum, cellsNum, refSzBytes int, data []byte, index []int) ([
 
 			offset += hashesNum*hashSize + hashesNum*depthSize
 			// TODO: check depth and hashes
+
+			if len(data)-offset < sz {
+				return nil, errors.New("failed to parse cell hashes, corrupted data")
+			}
 		}
 
 		payload := data[offset : offset+sz]
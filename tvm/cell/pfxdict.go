@@ -0,0 +1,288 @@
+package cell
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// PrefixDictionary - prefix code dictionary (PfxHashmapE), keys can have any length up to keySz,
+// but no key can be a prefix of another key
+type PrefixDictionary struct {
+	// key is bits of dict key in form of '0' and '1' chars, because keys have different length
+	storage map[string]*HashmapKV
+	keySz   uint
+}
+
+var ErrPrefixConflict = errors.New("key is a prefix of another key or another key is prefix of it")
+
+func NewPrefixDict(keySz uint) *PrefixDictionary {
+	return &PrefixDictionary{
+		storage: map[string]*HashmapKV{},
+		keySz:   keySz,
+	}
+}
+
+func (c *Slice) MustLoadPrefixDict(keySz uint) *PrefixDictionary {
+	ld, err := c.LoadPrefixDict(keySz)
+	if err != nil {
+		panic(err)
+	}
+	return ld
+}
+
+func (c *Slice) LoadPrefixDict(keySz uint) (*PrefixDictionary, error) {
+	cl, err := c.LoadMaybeRef()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ref for prefix dict, err: %w", err)
+	}
+
+	if cl == nil {
+		return NewPrefixDict(keySz), nil
+	}
+
+	return cl.ToPrefixDict(keySz)
+}
+
+func (c *Slice) ToPrefixDict(keySz uint) (*PrefixDictionary, error) {
+	d := NewPrefixDict(keySz)
+
+	err := d.mapInner(keySz, c, BeginCell())
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *PrefixDictionary) mapInner(leftKeySz uint, loader *Slice, keyPrefix *Builder) error {
+	sz, keyPrefix, err := loadLabel(leftKeySz, loader, keyPrefix)
+	if err != nil {
+		return err
+	}
+
+	if sz > leftKeySz {
+		return fmt.Errorf("label is longer than key")
+	}
+
+	isFork, err := loader.LoadBoolBit()
+	if err != nil {
+		return err
+	}
+
+	if !isFork {
+		// phmn_leaf$0
+		keyCell := keyPrefix.EndCell()
+		d.storage[bitsString(keyCell)] = &HashmapKV{
+			Key:   keyCell,
+			Value: loader.MustToCell(),
+		}
+		return nil
+	}
+
+	// phmn_fork$1
+	if leftKeySz-sz == 0 {
+		return fmt.Errorf("fork is deeper than key size")
+	}
+
+	left, err := loader.LoadRef()
+	if err != nil {
+		return err
+	}
+	err = d.mapInner(leftKeySz-(1+sz), left, keyPrefix.Copy().MustStoreUInt(0, 1))
+	if err != nil {
+		return err
+	}
+
+	right, err := loader.LoadRef()
+	if err != nil {
+		return err
+	}
+	return d.mapInner(leftKeySz-(1+sz), right, keyPrefix.Copy().MustStoreUInt(1, 1))
+}
+
+func (d *PrefixDictionary) Set(key, value *Cell) error {
+	if key.BitsSize() > d.keySz {
+		return fmt.Errorf("invalid key size")
+	}
+
+	bits := bitsString(key)
+	for k := range d.storage {
+		if k != bits && (strings.HasPrefix(k, bits) || strings.HasPrefix(bits, k)) {
+			return ErrPrefixConflict
+		}
+	}
+
+	d.storage[bits] = &HashmapKV{
+		Key:   key,
+		Value: value,
+	}
+	return nil
+}
+
+func (d *PrefixDictionary) Get(key *Cell) *Cell {
+	v, ok := d.storage[bitsString(key)]
+	if !ok {
+		return nil
+	}
+	return v.Value
+}
+
+func (d *PrefixDictionary) All() []*HashmapKV {
+	all := make([]*HashmapKV, 0, len(d.storage))
+	for _, v := range d.storage {
+		all = append(all, v)
+	}
+
+	return all
+}
+
+func (d *PrefixDictionary) MustToCell() *Cell {
+	c, err := d.ToCell()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (d *PrefixDictionary) ToCell() (*Cell, error) {
+	if len(d.storage) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(d.storage))
+	for k := range d.storage {
+		keys = append(keys, k)
+	}
+
+	var dive func(keys []string, offset, leftKeySz uint) (*Cell, error)
+	dive = func(keys []string, offset, leftKeySz uint) (*Cell, error) {
+		b := BeginCell()
+
+		if len(keys) == 1 {
+			if err := storeBitsLabel(b, keys[0][offset:], leftKeySz); err != nil {
+				return nil, fmt.Errorf("failed to store label, err: %w", err)
+			}
+
+			// phmn_leaf$0
+			if err := b.StoreBoolBit(false); err != nil {
+				return nil, fmt.Errorf("failed to store leaf tag, err: %w", err)
+			}
+
+			if err := b.StoreBuilder(d.storage[keys[0]].Value.ToBuilder()); err != nil {
+				return nil, fmt.Errorf("failed to store value, err: %w", err)
+			}
+			return b.EndCell(), nil
+		}
+
+		// find common part of keys, it is stored in label
+		common := keys[0][offset:]
+		for _, k := range keys[1:] {
+			i := 0
+			for i < len(common) && offset+uint(i) < uint(len(k)) && common[i] == k[offset+uint(i)] {
+				i++
+			}
+			common = common[:i]
+		}
+
+		split := offset + uint(len(common))
+		var zeroes, ones []string
+		for _, k := range keys {
+			if uint(len(k)) == split {
+				return nil, ErrPrefixConflict
+			}
+
+			if k[split] == '1' {
+				ones = append(ones, k)
+			} else {
+				zeroes = append(zeroes, k)
+			}
+		}
+
+		if err := storeBitsLabel(b, common, leftKeySz); err != nil {
+			return nil, fmt.Errorf("failed to store label, err: %w", err)
+		}
+
+		branch0, err := dive(zeroes, split+1, leftKeySz-uint(len(common))-1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build branch 0, err: %w", err)
+		}
+
+		branch1, err := dive(ones, split+1, leftKeySz-uint(len(common))-1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build branch 1, err: %w", err)
+		}
+
+		// phmn_fork$1
+		return b.MustStoreBoolBit(true).MustStoreRef(branch0).MustStoreRef(branch1).EndCell(), nil
+	}
+
+	dict, err := dive(keys, 0, d.keySz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prefix dict cell, err: %w", err)
+	}
+
+	return dict, nil
+}
+
+func (b *Builder) MustStorePrefixDict(dict *PrefixDictionary) *Builder {
+	err := b.StorePrefixDict(dict)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (b *Builder) StorePrefixDict(dict *PrefixDictionary) error {
+	if dict == nil {
+		return b.StoreMaybeRef(nil)
+	}
+
+	c, err := dict.ToCell()
+	if err != nil {
+		return err
+	}
+	return b.StoreMaybeRef(c)
+}
+
+// storeBitsLabel - stores label with bits in form of '0' and '1' chars, for key with n bits left
+func storeBitsLabel(b *Builder, bits string, n uint) error {
+	// short unary 0
+	if len(bits) == 0 {
+		return b.StoreUInt(0, 2)
+	}
+
+	// hml_long$10
+	err := b.StoreUInt(0b10, 2)
+	if err != nil {
+		return err
+	}
+
+	err = b.StoreUInt(uint64(len(bits)), uint(math.Ceil(math.Log2(float64(n+1)))))
+	if err != nil {
+		return err
+	}
+
+	for _, bit := range bits {
+		if err = b.StoreBoolBit(bit == '1'); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bitsString - returns bits of cell in form of '0' and '1' chars
+func bitsString(c *Cell) string {
+	ld := c.BeginParse()
+
+	var sb strings.Builder
+	for ld.BitsLeft() > 0 {
+		if ld.MustLoadUInt(1) == 1 {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+package cell
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPrefixDict(t *testing.T) {
+	d := NewPrefixDict(16)
+
+	keys := []*Cell{
+		BeginCell().MustStoreUInt(0b0, 1).EndCell(),
+		BeginCell().MustStoreUInt(0b10, 2).EndCell(),
+		BeginCell().MustStoreUInt(0b110101, 6).EndCell(),
+		BeginCell().MustStoreUInt(0b1110011100111111, 16).EndCell(),
+	}
+
+	for i, k := range keys {
+		if err := d.Set(k, BeginCell().MustStoreUInt(uint64(i), 8).EndCell()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := d.Set(BeginCell().MustStoreUInt(0b1101, 4).EndCell(), BeginCell().EndCell()); !errors.Is(err, ErrPrefixConflict) {
+		t.Fatal("should fail on prefix of existing key", err)
+	}
+
+	c := BeginCell().MustStorePrefixDict(d).EndCell()
+
+	ld, err := c.BeginParse().LoadPrefixDict(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ld.All()) != len(keys) {
+		t.Fatal("keys num not eq")
+	}
+
+	for i, k := range keys {
+		v := ld.Get(k)
+		if v == nil || v.BeginParse().MustLoadUInt(8) != uint64(i) {
+			t.Fatal("value not eq", i)
+		}
+	}
+
+	if !bytes.Equal(BeginCell().MustStorePrefixDict(ld).EndCell().Hash(), c.Hash()) {
+		t.Fatal("hash not eq after reserialization")
+	}
+
+	empty, err := BeginCell().MustStorePrefixDict(NewPrefixDict(16)).EndCell().BeginParse().LoadPrefixDict(16)
+	if err != nil || len(empty.All()) != 0 {
+		t.Fatal("empty dict not eq")
+	}
+}
@@ -0,0 +1,50 @@
+package cell
+
+import "sync"
+
+var slicePool = sync.Pool{
+	New: func() any {
+		return &Slice{}
+	},
+}
+
+// AcquireSlice - returns slice from the pool, prepared to parse cell c, the same as c.BeginParse(),
+// but memory of previously released slices is reused. Useful for hot decode loops,
+// slice should be returned using ReleaseSlice when it is not needed anymore.
+func AcquireSlice(c *Cell) *Slice {
+	s := slicePool.Get().(*Slice)
+	s.Reset(c)
+	return s
+}
+
+// ReleaseSlice - returns slice to the pool, slice and refs loaded from it should not be used after that
+func ReleaseSlice(s *Slice) {
+	slicePool.Put(s)
+}
+
+// Reset - reinitializes slice to parse cell c from the beginning, reusing memory of the slice
+// and of its ref slices, so refs loaded from it before should not be used after reset.
+func (c *Slice) Reset(cl *Cell) {
+	c.special = cl.special
+	c.level = cl.level
+	c.bitsSz = cl.bitsSz
+	c.loadedSz = 0
+	c.loadedRefs = 0
+	c.data = append(c.data[:0], cl.data...)
+
+	refs := c.allRefs[:cap(c.allRefs)]
+	if len(refs) < len(cl.refs) {
+		refs = append(refs, make([]*Slice, len(cl.refs)-len(refs))...)
+	}
+	refs = refs[:len(cl.refs)]
+
+	for i, ref := range cl.refs {
+		if refs[i] == nil {
+			refs[i] = &Slice{}
+		}
+		refs[i].Reset(ref)
+	}
+
+	c.allRefs = refs
+	c.refs = refs
+}
@@ -0,0 +1,61 @@
+package cell
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSlice_Reset(t *testing.T) {
+	a := BeginCell().MustStoreUInt(0xAA, 8).MustStoreRef(BeginCell().MustStoreUInt(1, 16).EndCell()).EndCell()
+	b := BeginCell().MustStoreUInt(0xBBBB, 16).
+		MustStoreRef(BeginCell().MustStoreUInt(2, 16).EndCell()).
+		MustStoreRef(BeginCell().MustStoreUInt(3, 32).EndCell()).EndCell()
+
+	s := AcquireSlice(a)
+	if s.MustLoadUInt(8) != 0xAA || s.MustLoadRef().MustLoadUInt(16) != 1 {
+		t.Fatal("incorrect values of a")
+	}
+
+	s.Reset(b)
+	if s.MustLoadUInt(16) != 0xBBBB || s.MustLoadRef().MustLoadUInt(16) != 2 || s.MustLoadRef().MustLoadUInt(32) != 3 {
+		t.Fatal("incorrect values of b")
+	}
+
+	s.Reset(a)
+	c, err := s.ToCell()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(c.Hash(), a.Hash()) {
+		t.Fatal("hash not eq after reset to smaller cell")
+	}
+	ReleaseSlice(s)
+}
+
+func benchCell() *Cell {
+	return BeginCell().MustStoreUInt(0x0f8a7ea5, 32).MustStoreUInt(777, 64).
+		MustStoreRef(BeginCell().MustStoreUInt(1, 256).EndCell()).
+		MustStoreRef(BeginCell().MustStoreUInt(2, 256).EndCell()).EndCell()
+}
+
+func BenchmarkCell_BeginParse(b *testing.B) {
+	c := benchCell()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := c.BeginParse()
+		s.MustLoadUInt(32)
+		s.MustLoadRef()
+	}
+}
+
+func BenchmarkAcquireSlice(b *testing.B) {
+	c := benchCell()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := AcquireSlice(c)
+		s.MustLoadUInt(32)
+		s.MustLoadRef()
+		ReleaseSlice(s)
+	}
+}
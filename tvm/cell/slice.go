@@ -1,24 +1,26 @@
 package cell
 
 import (
-	"errors"
 	"fmt"
-	"math"
 	"math/big"
 
 	"github.com/xssnick/tonutils-go/address"
 )
 
 type Slice struct {
-	special  bool
-	level    byte
-	bitsSz   uint
-	loadedSz uint
-	data     []byte
+	special    bool
+	level      byte
+	bitsSz     uint
+	loadedSz   uint
+	loadedRefs int
+	data       []byte
 
 	// store it as slice of pointers to make indexing logic cleaner on parse,
 	// from outside it should always come as object to not have problems
 	refs []*Slice
+
+	// all refs slices allocated by Reset, kept to reuse them on next reset
+	allRefs []*Slice
 }
 
 func (c *Slice) MustLoadRef() *Slice {
@@ -35,6 +37,7 @@ func (c *Slice) LoadRef() (*Slice, error) {
 	}
 	ref := c.refs[0]
 	c.refs = c.refs[1:]
+	c.loadedRefs++
 
 	return ref, nil
 }
@@ -62,6 +65,7 @@ func (c *Slice) LoadMaybeRef() (*Slice, error) {
 	}
 	ref := c.refs[0]
 	c.refs = c.refs[1:]
+	c.loadedRefs++
 
 	return ref, nil
 }
@@ -70,6 +74,16 @@ func (c *Slice) RefsNum() int {
 	return len(c.refs)
 }
 
+// BitsOffset - returns number of bits already loaded from the beginning of the cell
+func (c *Slice) BitsOffset() uint {
+	return c.loadedSz
+}
+
+// RefsOffset - returns number of refs already loaded from the beginning of the cell
+func (c *Slice) RefsOffset() int {
+	return c.loadedRefs
+}
+
 func (c *Slice) MustLoadCoins() uint64 {
 	r, err := c.LoadCoins()
 	if err != nil {
@@ -351,21 +365,12 @@ func (c *Slice) LoadAddr() (*address.Address, error) {
 			return nil, fmt.Errorf("failed to load anycast bit: %w", err)
 		}
 
+		var anycast *address.Anycast
 		if isAnycast {
-			depthLen := uint(math.Ceil(math.Log2(30)))
-
-			depth, err := c.LoadUInt(depthLen)
+			anycast, err = c.loadAnycast()
 			if err != nil {
-				return nil, fmt.Errorf("failed to load depth: %w", err)
+				return nil, err
 			}
-
-			pfx, err := c.LoadSlice(uint(depth))
-			if err != nil {
-				return nil, fmt.Errorf("failed to load prefix: %w", err)
-			}
-			_ = pfx
-
-			// TODO: save anycast
 		}
 
 		workchain, err := c.LoadUInt(8)
@@ -378,28 +383,21 @@ func (c *Slice) LoadAddr() (*address.Address, error) {
 			return nil, fmt.Errorf("failed to load addr data: %w", err)
 		}
 
-		return address.NewAddress(0, byte(workchain), data), nil
+		addr := address.NewAddress(0, byte(workchain), data)
+		addr.SetAnycast(anycast)
+		return addr, nil
 	case 3:
 		isAnycast, err := c.LoadBoolBit()
 		if err != nil {
 			return nil, fmt.Errorf("failed to load anycast bit: %w", err)
 		}
 
+		var anycast *address.Anycast
 		if isAnycast {
-			depthLen := uint(math.Ceil(math.Log2(30)))
-
-			depth, err := c.LoadUInt(depthLen)
+			anycast, err = c.loadAnycast()
 			if err != nil {
-				return nil, fmt.Errorf("failed to load depth: %w", err)
+				return nil, err
 			}
-
-			pfx, err := c.LoadSlice(uint(depth))
-			if err != nil {
-				return nil, fmt.Errorf("failed to load prefix: %w", err)
-			}
-			_ = pfx
-
-			// TODO: save anycast
 		}
 
 		ln, err := c.LoadUInt(9)
@@ -417,13 +415,39 @@ func (c *Slice) LoadAddr() (*address.Address, error) {
 			return nil, fmt.Errorf("failed to load addr data: %w", err)
 		}
 
-		return address.NewAddressVar(0, int32(workchain), uint(ln), data), nil
+		addr := address.NewAddressVar(0, int32(workchain), uint(ln), data)
+		addr.SetAnycast(anycast)
+		return addr, nil
 	default:
-		// TODO: support of all types of addresses, currently only std supported, skipping 3 bits
-		return nil, errors.New("not supported type of address, currently only std supported")
+		// all 4 values of 2 bits type are handled above
+		return nil, fmt.Errorf("unknown type of address: %d", typ)
 	}
 }
 
+// anycastDepthLen - size of depth:(#<= 30) in anycast_info
+const anycastDepthLen = 5
+
+func (c *Slice) loadAnycast() (*address.Anycast, error) {
+	depth, err := c.LoadUInt(anycastDepthLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load depth: %w", err)
+	}
+
+	if depth < 1 || depth > 30 {
+		return nil, fmt.Errorf("incorrect anycast depth %d", depth)
+	}
+
+	pfx, err := c.LoadSlice(uint(depth))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prefix: %w", err)
+	}
+
+	return &address.Anycast{
+		Depth:  uint(depth),
+		Prefix: pfx,
+	}, nil
+}
+
 func (c *Slice) MustLoadStringSnake() string {
 	a, err := c.LoadStringSnake()
 	if err != nil {
@@ -501,13 +525,21 @@ func (c *Slice) Copy() *Slice {
 	}
 
 	return &Slice{
-		bitsSz:   c.bitsSz,
-		loadedSz: c.loadedSz,
-		data:     data,
-		refs:     refs,
+		special:    c.special,
+		level:      c.level,
+		bitsSz:     c.bitsSz,
+		loadedSz:   c.loadedSz,
+		loadedRefs: c.loadedRefs,
+		data:       data,
+		refs:       refs,
 	}
 }
 
+// IsSpecial - checks is slice of exotic cell, like pruned branch or merkle proof, its type is in the first byte of data
+func (c *Slice) IsSpecial() bool {
+	return c.special
+}
+
 func (c *Slice) ToCell() (*Cell, error) {
 	cp := c.Copy()
 